@@ -59,50 +59,61 @@ func Import(stores *models.Stores, args []string) error {
 	decoder := json.NewDecoder(file)
 	var source importJSONSource
 
-	createdRecordIds := struct {
-		authors []int
-	}{make([]int, 0, len(source.Imports.Authors))}
-
 	if e := decoder.Decode(&source); e != nil {
 		return fmt.Errorf("unable to decode json (e %v)", e)
 	}
 
-	for _, a := range source.Imports.Authors {
-		fmt.Printf("importing %s...", a)
-		id, e := stores.Authors.CreateAuthors(*a)
+	if e := importGenres(stores.Genres, source.Imports); e != nil {
+		return e
+	}
 
-		if e != nil {
-			fmt.Println()
-			return fmt.Errorf("failed import on %s (e %v)", a, e)
-		}
+	// Authors and books share a database; import them (and process the deletions) within a single transaction so a
+	// failure part of the way through does not leave a partially imported library behind.
+	library := &models.Stores{Authors: stores.Authors, Books: stores.Books, BookAuthors: stores.BookAuthors}
 
-		createdRecordIds.authors = append(createdRecordIds.authors, int(id))
-		fmt.Printf(" %d\n", id)
+	e = library.Transaction(func(tx *models.Stores) error {
+		return importLibrary(tx.Authors, tx.Books, tx.BookAuthors, source)
+	})
+
+	if e != nil {
+		return e
 	}
 
-	fmt.Printf("updating %d authors w/ imported flag... ")
-	authorbp := &models.AuthorBlueprint{ID: createdRecordIds.authors}
+	counts := struct {
+		authors int
+		genres  int
+	}{}
 
-	if _, e := stores.Authors.UpdateAuthorAuthorFlags(models.AuthorImported, authorbp); e != nil {
-		fmt.Printf("failed\n")
-		return fmt.Errorf("unable to update authors (e %v)", e)
+	counts.authors, e = stores.Authors.CountAuthors(nil)
+
+	if e != nil {
+		return fmt.Errorf("unable to get import summary (e %v)", e)
 	}
 
-	fmt.Printf("success\n")
+	counts.genres, e = stores.Genres.CountGenres(nil)
 
+	if e != nil {
+		return fmt.Errorf("unable to get import summary (e %v)", e)
+	}
+
+	fmt.Println(fmt.Sprintf("import summary: %d authors, %d genres", counts.authors, counts.genres))
+	return nil
+}
+
+func importGenres(genres models.GenreStore, imports importModelList) error {
 	children := make(chan genreImportChild)
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 
 	go func() {
-		pending := make([]genreImportChild, 0, len(source.Imports.Genres))
+		pending := make([]genreImportChild, 0, len(imports.Genres))
 
 		for g := range children {
 			pending = append(pending, g)
 		}
 
 		for _, g := range pending {
-			matches, e := stores.Genres.SelectGenreIDs(&models.GenreBlueprint{Name: []string{g.parent}})
+			matches, e := genres.SelectGenreIDs(&models.GenreBlueprint{Name: []string{g.parent}})
 
 			if e != nil || len(matches) != 1 {
 				fmt.Printf("unable to create genre %s, cant find parent %s (e %v)", g.child.Name, g.parent, e)
@@ -115,7 +126,7 @@ func Import(stores *models.Stores, args []string) error {
 			}
 
 			fmt.Printf("importing %s... ", g.child.Name)
-			id, e := stores.Genres.CreateGenres(g.child)
+			id, e := genres.CreateGenres(g.child)
 
 			if e != nil {
 				fmt.Printf("unable to create genre %s (e %v)\n", g.child.Name, e)
@@ -128,11 +139,14 @@ func Import(stores *models.Stores, args []string) error {
 		wg.Done()
 	}()
 
-	for _, g := range source.Imports.Genres {
+	defer wg.Wait()
+	defer close(children)
+
+	for _, g := range imports.Genres {
 		parent := ""
 		fmt.Printf("importing %s...", g)
 
-		for _, t := range source.Imports.GenreTaxonomy {
+		for _, t := range imports.GenreTaxonomy {
 			if t.Child != g.Name {
 				continue
 			}
@@ -147,7 +161,7 @@ func Import(stores *models.Stores, args []string) error {
 			continue
 		}
 
-		id, e := stores.Genres.CreateGenres(*g)
+		id, e := genres.CreateGenres(*g)
 
 		if e != nil {
 			return fmt.Errorf("failed import on genre %s (e %v)", g, e)
@@ -156,71 +170,104 @@ func Import(stores *models.Stores, args []string) error {
 		fmt.Printf(" %d\n", id)
 	}
 
-	close(children)
-	wg.Wait()
+	return nil
+}
+
+func importLibrary(
+	authors models.AuthorStore,
+	books models.BookStore,
+	links models.BookAuthorStore,
+	source importJSONSource,
+) error {
+	createdRecordIds := struct {
+		authors []int
+	}{make([]int, 0, len(source.Imports.Authors))}
+
+	// Authors are upserted by their unique name, re-importing a library overwrites the authors it already holds.
+	for _, a := range source.Imports.Authors {
+		fmt.Printf("importing %s...", a)
+		ids, e := authors.UpsertAuthors(nil, *a)
+
+		if e != nil || len(ids) != 1 {
+			fmt.Println()
+			return fmt.Errorf("failed import on %s (e %v)", a, e)
+		}
+
+		createdRecordIds.authors = append(createdRecordIds.authors, int(ids[0]))
+		fmt.Printf(" %d\n", ids[0])
+	}
+
+	fmt.Printf("updating %d authors w/ imported flag... ", len(createdRecordIds.authors))
+	authorbp := &models.AuthorBlueprint{ID: createdRecordIds.authors}
+
+	if _, e := authors.UpdateAuthorAuthorFlags(models.AuthorImported, authorbp); e != nil {
+		fmt.Printf("failed\n")
+		return fmt.Errorf("unable to update authors (e %v)", e)
+	}
+
+	fmt.Printf("success\n")
 
 	for _, b := range source.Imports.Books {
-		var authorName string
+		authorNames := make([]string, 0, 1)
 
 		for _, ba := range source.Imports.BookAuthors {
 			if ba.Book == b.Title {
-				authorName = ba.Author
+				authorNames = append(authorNames, ba.Author)
 			}
 		}
 
-		if authorName == "" {
+		if len(authorNames) == 0 {
 			fmt.Printf("skipping book: \"%s\", no author found\n", b.Title)
 			continue
 		}
 
-		aid, e := stores.Authors.SelectAuthorIDs(&models.AuthorBlueprint{
-			Name: []string{authorName},
+		aids, e := authors.SelectAuthorIDs(&models.AuthorBlueprint{
+			Name: authorNames,
+		})
+
+		if e != nil || len(aids) != len(authorNames) {
+			return fmt.Errorf("failed import on book author lookup - found %d (e %v)", len(aids), e)
+		}
+
+		// The ids of the authors are not returned in the order of their names; the first author listed for the book is
+		// looked up on its own to become the book's primary author.
+		primary, e := authors.SelectAuthorIDs(&models.AuthorBlueprint{
+			Name: authorNames[:1],
 		})
 
-		if e != nil || len(aid) != 1 {
-			return fmt.Errorf("failed import on book author lookup - found %d (e %v)", len(aid), e)
+		if e != nil || len(primary) != 1 {
+			return fmt.Errorf("failed import on book primary author lookup - found %d (e %v)", len(primary), e)
 		}
 
 		fmt.Printf("creating book %s... ", b)
 
-		b.AuthorID = aid[0]
+		b.AuthorID = primary[0]
 
-		id, e := stores.Books.CreateBooks(*b)
+		id, e := books.CreateBooks(*b)
 
 		if e != nil {
 			fmt.Println()
 			return fmt.Errorf("failed import on book create (e %v)", e)
 		}
 
+		for _, aid := range aids {
+			if e := links.LinkBookAuthor(int(id), aid); e != nil {
+				fmt.Println()
+				return fmt.Errorf("failed import on book author link (e %v)", e)
+			}
+		}
+
 		fmt.Printf("%d\n", id)
 	}
 
 	for _, b := range source.Deletions.Books {
 		blueprint := &models.BookBlueprint{Title: []string{b.Title}}
-		_, e := stores.Books.DeleteBooks(blueprint)
+		_, e := books.DeleteBooks(blueprint)
 
 		if e != nil {
 			return fmt.Errorf("unable to delete requested books (e %s)", e.Error())
 		}
 	}
 
-	counts := struct {
-		authors int
-		genres  int
-	}{}
-
-	counts.authors, e = stores.Authors.CountAuthors(nil)
-
-	if e != nil {
-		return fmt.Errorf("unable to get import summary (e %v)", e)
-	}
-
-	counts.genres, e = stores.Genres.CountGenres(nil)
-
-	if e != nil {
-		return fmt.Errorf("unable to get import summary (e %v)", e)
-	}
-
-	fmt.Println(fmt.Sprintf("import summary: %d authors, %d genres", counts.authors, counts.genres))
 	return nil
 }
@@ -2,7 +2,7 @@ drop table if exists genres;
 
 create table genres (
   id SERIAL,
-  name TEXT,
+  name TEXT NOT NULL,
   parent_id INTEGER,
   genre_references jsonb
 );
@@ -12,5 +12,5 @@ drop table if exists multi_auto;
 create table multi_auto (
   id SERIAL,
   status TEXT DEFAULT 'pending' NOT NULL,
-  name TEXT
+  name TEXT NOT NULL
 );
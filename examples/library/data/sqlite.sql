@@ -2,7 +2,7 @@ drop table if exists authors;
 
 create table authors (
   system_id INTEGER PRIMARY KEY,
-  name TEXT,
+  name TEXT NOT NULL UNIQUE,
   university_id INTEGER,
   rating REAL NOT NULL DEFAULT '100.00',
   flags INTEGER NOT NULL DEFAULT 0,
@@ -13,8 +13,29 @@ drop table if exists books;
 
 create table books (
   system_id INTEGER PRIMARY KEY,
-  title TEXT,
+  title TEXT NOT NULL,
   author INTEGER NOT NULL,
   series INTEGER,
   year_published INTEGER NOT NULL
 );
+
+drop table if exists book_authors;
+
+create table book_authors (
+  book_id INTEGER NOT NULL,
+  author_id INTEGER NOT NULL,
+  UNIQUE (book_id, author_id)
+);
+
+drop table if exists series;
+
+create table series (
+  system_id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  subtitle TEXT,
+  version INTEGER NOT NULL DEFAULT 0,
+  completed_at DATETIME,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at DATETIME
+);
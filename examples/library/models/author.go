@@ -18,12 +18,12 @@ const (
 
 // Author represents an author of a book.
 type Author struct {
-	table        bool          `marlow:"tableName=authors"`
+	table        bool          `marlow:"tableName=authors&primaryKey=system_id"`
 	ID           int           `marlow:"column=system_id&autoIncrement=true"`
-	Name         string        `marlow:"column=name"`
+	Name         string        `marlow:"column=name&unique"`
 	UniversityID sql.NullInt64 `marlow:"column=university_id"`
-	ReaderRating float64       `marlow:"column=rating"`
-	AuthorFlags  uint8         `marlow:"column=flags&bitmask"`
+	ReaderRating float64       `marlow:"column=rating&default=100"`
+	AuthorFlags  uint8         `marlow:"column=flags&bitmask&default=0"`
 	Birthday     time.Time     `marlow:"column=birthday"`
 }
 
@@ -52,6 +52,16 @@ func Test_Author(t *testing.T) {
 			g.Assert(r).Equal("")
 		})
 
+		g.It("supports NOT IN lookups on sql.NullInt64 fields, excluding NULL values", func() {
+			r := fmt.Sprintf("%s", &AuthorBlueprint{
+				UniversityIDNotIn: []sql.NullInt64{{Int64: 10, Valid: true}, {Valid: false}},
+			})
+			g.Assert(r).Equal("WHERE authors.university_id NOT IN (?)")
+
+			r = fmt.Sprintf("%s", &AuthorBlueprint{UniversityIDNotIn: []sql.NullInt64{{Valid: false}}})
+			g.Assert(r).Equal("WHERE authors.university_id IS NOT NULL")
+		})
+
 		g.It("supports int values on sql.NullInt64 fields", func() {
 			r := fmt.Sprintf("%s", &AuthorBlueprint{
 				UniversityID: []sql.NullInt64{
@@ -61,11 +71,11 @@ func Test_Author(t *testing.T) {
 			g.Assert(r).Equal("WHERE authors.university_id IN (?)")
 		})
 
-		g.It("supports NOT NULL selection if present but empty on sql.NullInt64 fields", func() {
+		g.It("supports IS NOT NULL selection if present but empty on sql.NullInt64 fields", func() {
 			r := fmt.Sprintf("%s", &AuthorBlueprint{
 				UniversityID: []sql.NullInt64{},
 			})
-			g.Assert(r).Equal("WHERE authors.university_id NOT NULL")
+			g.Assert(r).Equal("WHERE authors.university_id IS NOT NULL")
 		})
 
 		g.It("supports null values on sql.NullInt64 fields", func() {
@@ -77,6 +87,21 @@ func Test_Author(t *testing.T) {
 			g.Assert(r).Equal("WHERE authors.university_id IS NULL")
 		})
 
+		g.It("supports IS NULL and IS NOT NULL filters on sql.NullInt64 fields", func() {
+			isNull, notNull := true, false
+			r := fmt.Sprintf("%s", &AuthorBlueprint{UniversityIDIsNull: &isNull})
+			g.Assert(r).Equal("WHERE authors.university_id IS NULL")
+
+			r = fmt.Sprintf("%s", &AuthorBlueprint{UniversityIDIsNull: &notNull})
+			g.Assert(r).Equal("WHERE authors.university_id IS NOT NULL")
+		})
+
+		g.It("composes null filters with the other clauses of inclusive blueprints", func() {
+			isNull := true
+			r := fmt.Sprintf("%s", &AuthorBlueprint{ID: []int{1}, UniversityIDIsNull: &isNull, Inclusive: true})
+			g.Assert(r).Equal("WHERE authors.system_id IN (?) OR authors.university_id IS NULL")
+		})
+
 		g.It("supports range on ID column querying", func() {
 			r := fmt.Sprintf("%s", &AuthorBlueprint{
 				IDRange: []int{1, 2},
@@ -169,26 +194,87 @@ func Test_Author(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for authors w/o (default limit)", func() {
-			authors, e := store.FindAuthors(nil)
+			authors, _, e := store.FindAuthors(nil)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(authors)).Equal(marlow.DefaultBlueprintLimit)
 		})
 
 		g.It("allows the consumer to search for authors w/ blueprint (explicit limit)", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{Limit: 20})
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{Limit: 20})
 			g.Assert(e).Equal(nil)
 			g.Assert(len(authors)).Equal(20)
 		})
 
 		g.It("allows the consumer to search for authors w/ blueprint (explicit offset)", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{Offset: 1, Limit: 1})
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{Offset: 1, Limit: 1})
 			g.Assert(e).Equal(nil)
 			g.Assert(len(authors)).Equal(1)
 			g.Assert(authors[0].ID).Equal(2)
 		})
 
+		g.Describe("paging with the blueprint Cursor", func() {
+			g.It("visits every author once when following the cursors of a tied order", func() {
+				total, e := store.CountAuthors(nil)
+				g.Assert(e).Equal(nil)
+
+				seen := make(map[int]bool, total)
+				blueprint := &AuthorBlueprint{OrderBy: "rating DESC", Limit: 7}
+
+				for {
+					authors, next, e := store.FindAuthors(blueprint)
+					g.Assert(e).Equal(nil)
+
+					for _, author := range authors {
+						g.Assert(seen[author.ID]).Equal(false)
+						seen[author.ID] = true
+					}
+
+					if next == "" {
+						break
+					}
+
+					blueprint.Cursor = next
+				}
+
+				g.Assert(len(seen)).Equal(total)
+			})
+
+			g.It("returns an empty cursor when the page is not full", func() {
+				_, next, e := store.FindAuthors(&AuthorBlueprint{ID: []int{1, 2}, Limit: 5})
+				g.Assert(e).Equal(nil)
+				g.Assert(next).Equal("")
+			})
+
+			g.It("combines the cursor with the other blueprint clauses", func() {
+				first, next, e := store.FindAuthors(&AuthorBlueprint{IDRange: []int{0, 10}, Limit: 4})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(first)).Equal(4)
+
+				second, _, e := store.FindAuthors(&AuthorBlueprint{IDRange: []int{0, 10}, Limit: 4, Cursor: next})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(second)).Equal(4)
+				g.Assert(second[0].ID).Equal(first[3].ID + 1)
+			})
+
+			g.It("returns an error for a malformed cursor", func() {
+				_, _, e := store.FindAuthors(&AuthorBlueprint{Cursor: "not a cursor"})
+				g.Assert(e == nil).Equal(false)
+			})
+
+			g.It("returns an error for a cursor created with a different order", func() {
+				_, next, e := store.FindAuthors(&AuthorBlueprint{OrderBy: "name", Limit: 2})
+				g.Assert(e).Equal(nil)
+
+				_, _, e = store.FindAuthors(&AuthorBlueprint{OrderBy: "rating", Cursor: next})
+				g.Assert(e == nil).Equal(false)
+
+				_, e = store.CountAuthors(&AuthorBlueprint{OrderBy: "rating", Cursor: next})
+				g.Assert(e == nil).Equal(false)
+			})
+		})
+
 		g.It("allows the consumer to search for authors by explicit Name", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{
 				Name: []string{"author-11", "author-21", "not-exists"},
 			})
 			g.Assert(e).Equal(nil)
@@ -196,7 +282,7 @@ func Test_Author(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search by 'NameLike'", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{
 				NameLike: []string{"%-100%"},
 			})
 			g.Assert(e).Equal(nil)
@@ -204,7 +290,7 @@ func Test_Author(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for authors by explicit ID", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{
 				ID: []int{1, 2},
 			})
 			g.Assert(e).Equal(nil)
@@ -212,7 +298,7 @@ func Test_Author(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for authors by ID range", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{
 				IDRange: []int{1, 4},
 			})
 			g.Assert(e).Equal(nil)
@@ -220,7 +306,7 @@ func Test_Author(t *testing.T) {
 		})
 
 		g.It("correctly serializes null/not null values into a sql.NullInt64 field", func() {
-			authors, e := store.FindAuthors(&AuthorBlueprint{
+			authors, _, e := store.FindAuthors(&AuthorBlueprint{
 				ID: []int{1337, 1338},
 			})
 			g.Assert(e).Equal(nil)
@@ -239,6 +325,20 @@ func Test_Author(t *testing.T) {
 			g.Assert(count).Equal(generatedAuthorCount + 1)
 		})
 
+		g.It("allows consumers to average the reader rating of authors", func() {
+			average, e := store.AvgAuthorReaderRating(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(average.Valid).Equal(true)
+			g.Assert(average.Float64).Equal(100.0)
+		})
+
+		g.It("ignores NULL values when aggregating nullable fields", func() {
+			maximum, e := store.MaxAuthorUniversityID(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(maximum.Valid).Equal(true)
+			g.Assert(maximum.Int64).Equal(int64(10))
+		})
+
 		g.It("allows consumer to search by authors with null UniversityID", func() {
 			count, e := store.CountAuthors(&AuthorBlueprint{
 				UniversityID: []sql.NullInt64{
@@ -334,6 +434,17 @@ func Test_Author(t *testing.T) {
 			g.Assert(count).Equal(1)
 		})
 
+		g.It("allows consumer to search by authors with or without a UniversityID", func() {
+			isNull, notNull := true, false
+			count, e := store.CountAuthors(&AuthorBlueprint{UniversityIDIsNull: &notNull})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(1)
+
+			count, e = store.CountAuthors(&AuthorBlueprint{UniversityIDIsNull: &isNull})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(generatedAuthorCount)
+		})
+
 		g.It("allows consumer to search by authors with explicit UniversityID", func() {
 			count, e := store.CountAuthors(&AuthorBlueprint{
 				UniversityID: []sql.NullInt64{
@@ -344,6 +455,20 @@ func Test_Author(t *testing.T) {
 			g.Assert(count).Equal(1)
 		})
 
+		g.It("allows consumer to exclude authors by UniversityID, never matching authors without one", func() {
+			count, e := store.CountAuthors(&AuthorBlueprint{
+				UniversityIDNotIn: []sql.NullInt64{{Int64: 10, Valid: true}},
+			})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(0)
+
+			count, e = store.CountAuthors(&AuthorBlueprint{
+				UniversityIDNotIn: []sql.NullInt64{{Int64: 11, Valid: true}, {Valid: false}},
+			})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(1)
+		})
+
 		g.It("allows the consumer to update the author id", func() {
 			c, e := store.UpdateAuthorID(1991, &AuthorBlueprint{ID: []int{8}})
 			g.Assert(e).Equal(nil)
@@ -381,7 +506,7 @@ func Test_Author(t *testing.T) {
 			g.Assert(e).Equal(nil)
 			g.Assert(updatedCount).Equal(int64(1))
 
-			a, e := store.FindAuthors(&AuthorBlueprint{ID: []int{1}})
+			a, _, e := store.FindAuthors(&AuthorBlueprint{ID: []int{1}})
 			g.Assert(e).Equal(nil)
 			g.Assert(a[0].Name).Equal("danny")
 		})
@@ -408,7 +533,7 @@ func Test_Author(t *testing.T) {
 				}...)
 				g.Assert(e).Equal(nil)
 
-				found, e := store.FindAuthors(&AuthorBlueprint{ID: []int{int(s)}})
+				found, _, e := store.FindAuthors(&AuthorBlueprint{ID: []int{int(s)}})
 				g.Assert(e).Equal(nil)
 				g.Assert(len(found)).Equal(1)
 				g.Assert(found[0].Name).Equal("Amelia")
@@ -452,7 +577,7 @@ func Test_Author(t *testing.T) {
 				blueprint := &AuthorBlueprint{ID: []int{20}}
 				_, e := store.UpdateAuthorAuthorFlags(5, blueprint)
 				g.Assert(e).Equal(nil)
-				authors, e := store.FindAuthors(blueprint)
+				authors, _, e := store.FindAuthors(blueprint)
 				g.Assert(e).Equal(nil)
 				g.Assert(authors[0].AuthorFlags).Equal(uint8(5))
 			})
@@ -509,7 +634,7 @@ func Test_Author(t *testing.T) {
 			g.It("allows users to search by birthday", func() {
 				birthday, e := time.Parse(time.RFC3339, "1821-11-11T15:04:05Z")
 				g.Assert(e).Equal(nil)
-				b, e := store.FindAuthors(&AuthorBlueprint{Birthday: []time.Time{birthday}})
+				b, _, e := store.FindAuthors(&AuthorBlueprint{Birthday: []time.Time{birthday}})
 				g.Assert(e).Equal(nil)
 				g.Assert(len(b)).Equal(1)
 			})
@@ -540,7 +665,7 @@ func Test_Author(t *testing.T) {
 				blueprint := &AuthorBlueprint{ID: []int{20}}
 				_, e := store.UpdateAuthorReaderRating(50.00, blueprint)
 				g.Assert(e).Equal(nil)
-				authors, e := store.FindAuthors(blueprint)
+				authors, _, e := store.FindAuthors(blueprint)
 				g.Assert(e).Equal(nil)
 				g.Assert(authors[0].ReaderRating).Equal(50.00)
 			})
@@ -595,6 +720,60 @@ func Test_Author(t *testing.T) {
 			})
 		})
 
+		g.Describe("UpsertAuthors", func() {
+			names := []string{"upserted author", "upserted rival"}
+
+			g.AfterEach(func() {
+				_, e := store.DeleteAuthors(&AuthorBlueprint{Name: names})
+				g.Assert(e).Equal(nil)
+			})
+
+			g.It("inserts the authors without a conflicting row, returning their ids", func() {
+				ids, e := store.UpsertAuthors(nil, Author{Name: names[0], ReaderRating: 10})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(ids)).Equal(1)
+				author, e := store.GetAuthor(int(ids[0]))
+				g.Assert(e).Equal(nil)
+				g.Assert(author.Name).Equal(names[0])
+				g.Assert(author.ReaderRating).Equal(10.00)
+			})
+
+			g.It("overwrites the authors conflicting on their name, returning the id of the existing row", func() {
+				created, e := store.CreateAuthors(Author{Name: names[0], ReaderRating: 10})
+				g.Assert(e).Equal(nil)
+				ids, e := store.UpsertAuthors(nil, Author{Name: names[0], ReaderRating: 20}, Author{Name: names[1]})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(ids)).Equal(2)
+				g.Assert(ids[0]).Equal(created)
+				c, e := store.CountAuthors(&AuthorBlueprint{Name: names})
+				g.Assert(e).Equal(nil)
+				g.Assert(c).Equal(2)
+				author, e := store.GetAuthor(int(created))
+				g.Assert(e).Equal(nil)
+				g.Assert(author.ReaderRating).Equal(20.00)
+			})
+
+			g.It("only overwrites the columns requested", func() {
+				university := sql.NullInt64{Int64: 10, Valid: true}
+				created, e := store.CreateAuthors(Author{Name: names[0], ReaderRating: 10, UniversityID: university})
+				g.Assert(e).Equal(nil)
+				_, e = store.UpsertAuthors([]string{"rating"}, Author{Name: names[0], ReaderRating: 30})
+				g.Assert(e).Equal(nil)
+				author, e := store.GetAuthor(int(created))
+				g.Assert(e).Equal(nil)
+				g.Assert(author.ReaderRating).Equal(30.00)
+				g.Assert(author.UniversityID).Equal(university)
+			})
+
+			g.It("returns an error when asked to overwrite an unknown column", func() {
+				_, e := store.UpsertAuthors([]string{"missing"}, Author{Name: names[0]})
+				g.Assert(e == nil).Equal(false)
+				c, e := store.CountAuthors(&AuthorBlueprint{Name: names})
+				g.Assert(e).Equal(nil)
+				g.Assert(c).Equal(0)
+			})
+		})
+
 		g.Describe("DeleteAuthors", func() {
 
 			g.It("returns an error and a negative number with an empty blueprint", func() {
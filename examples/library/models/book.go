@@ -9,12 +9,12 @@ import "database/sql"
 
 // Book represents a book in the example application
 type Book struct {
-	table         string        `marlow:"defaultLimit=10"`
+	table         string        `marlow:"defaultLimit=10&defaultOrder=system_id&primaryKey=system_id"`
 	ID            int           `marlow:"column=system_id&autoIncrement=true"`
-	Title         string        `marlow:"column=title"`
-	AuthorID      int           `marlow:"column=author"`
+	Title         string        `marlow:"column=title&validate=required,max=255"`
+	AuthorID      int           `marlow:"column=author&references=Author&index=true"`
 	SeriesID      sql.NullInt64 `marlow:"column=series"`
-	YearPublished int           `marlow:"column=year_published" json:"year_published"`
+	YearPublished int           `marlow:"column=year_published&validate=min=0" json:"year_published"`
 }
 
 // String returns the book with good info.
@@ -0,0 +1,10 @@
+package models
+
+//go:generate marlowc -input book_author.go
+
+// BookAuthor records link books to each of the authors that wrote them.
+type BookAuthor struct {
+	table    bool `marlow:"tableName=book_authors&joins=Book,Author&uniqueKey=book_id,author_id"`
+	BookID   int  `marlow:"column=book_id&references=Book"`
+	AuthorID int  `marlow:"column=author_id&references=Author"`
+}
@@ -0,0 +1,132 @@
+package models
+
+import "os"
+import "io"
+import "fmt"
+import "bytes"
+import "testing"
+import _ "github.com/mattn/go-sqlite3"
+import "database/sql"
+import "github.com/franela/goblin"
+
+func Test_BookAuthor(t *testing.T) {
+	var db *sql.DB
+	var store BookAuthorStore
+	var queryLog io.Writer
+
+	g := goblin.Goblin(t)
+
+	dbFile := "./book-author-testing.db"
+
+	g.Describe("BookAuthor join blueprint filters", func() {
+		g.It("matches the books linked to the authors matched by the nested blueprint", func() {
+			str := fmt.Sprintf("%s", &BookBlueprint{
+				Title:         []string{"a"},
+				LinkedAuthors: &AuthorBlueprint{Name: []string{"b"}},
+			})
+			expected := "books.system_id IN (SELECT book_authors.book_id FROM book_authors " +
+				"WHERE book_authors.author_id IN (SELECT authors.system_id FROM authors WHERE authors.name IN (?)))"
+			g.Assert(str).Equal(fmt.Sprintf("WHERE books.title IN (?) AND %s", expected))
+		})
+
+		g.It("matches every linked book when the nested blueprint is empty", func() {
+			str := fmt.Sprintf("%s", &BookBlueprint{LinkedAuthors: &AuthorBlueprint{}})
+			expected := "WHERE books.system_id IN (SELECT book_authors.book_id FROM book_authors " +
+				"WHERE book_authors.author_id IN (SELECT authors.system_id FROM authors))"
+			g.Assert(str).Equal(expected)
+		})
+
+		g.It("orders the values of the nested blueprint with its placeholders", func() {
+			values := (&BookBlueprint{
+				Title:         []string{"a"},
+				LinkedAuthors: &AuthorBlueprint{Name: []string{"b"}},
+			}).Values()
+			g.Assert(values).Equal([]interface{}{"a", "b"})
+		})
+	})
+
+	g.Describe("BookAuthor model & generated store", func() {
+		g.Before(func() {
+			var e error
+			db, e = loadDB(dbFile)
+			g.Assert(e).Equal(nil)
+
+			statements := []string{
+				"insert into authors (system_id,name,birthday) values(1,'first author',date());",
+				"insert into authors (system_id,name,birthday) values(2,'second author',date());",
+				"insert into books (system_id,title,author,year_published) values(1,'first book',1,2001);",
+				"insert into books (system_id,title,author,year_published) values(2,'second book',1,2002);",
+			}
+
+			for _, statement := range statements {
+				_, e := db.Exec(statement)
+				g.Assert(e).Equal(nil)
+			}
+		})
+
+		g.BeforeEach(func() {
+			queryLog = new(bytes.Buffer)
+			store = NewBookAuthorStore(db, queryLog)
+		})
+
+		g.After(func() {
+			e := db.Close()
+			g.Assert(e).Equal(nil)
+			os.Remove(dbFile)
+		})
+
+		g.It("links books to their authors", func() {
+			g.Assert(store.LinkBookAuthor(1, 1)).Equal(nil)
+			g.Assert(store.LinkBookAuthor(1, 2)).Equal(nil)
+			g.Assert(store.LinkBookAuthor(2, 2)).Equal(nil)
+
+			count, e := store.CountBookAuthors(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(3)
+		})
+
+		g.It("lists the authors linked to a book", func() {
+			authors, e := store.ListLinkedAuthors(1)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(authors)).Equal(2)
+			g.Assert(authors[0].Name).Equal("first author")
+			g.Assert(authors[1].Name).Equal("second author")
+		})
+
+		g.It("lists the books linked to an author", func() {
+			books, e := store.ListLinkedBooks(2)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(books)).Equal(2)
+
+			books, e = store.ListLinkedBooks(1)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(books)).Equal(1)
+			g.Assert(books[0].Title).Equal("first book")
+		})
+
+		g.It("finds books written by authors matching a blueprint", func() {
+			books, _, e := NewBookStore(db, queryLog).FindBooks(&BookBlueprint{
+				LinkedAuthors: &AuthorBlueprint{Name: []string{"first author"}},
+				Title:         []string{"first book", "second book"},
+			})
+			g.Assert(e).Equal(nil)
+			g.Assert(len(books)).Equal(1)
+			g.Assert(books[0].ID).Equal(1)
+		})
+
+		g.It("unlinks books from their authors", func() {
+			count, e := store.UnlinkBookAuthor(1, 2)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			authors, e := store.ListLinkedAuthors(1)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(authors)).Equal(1)
+			g.Assert(authors[0].ID).Equal(1)
+		})
+
+		g.It("returns an error when linking a pair of records twice", func() {
+			g.Assert(store.LinkBookAuthor(2, 2) == nil).Equal(false)
+		})
+	})
+}
@@ -4,7 +4,9 @@ import "os"
 import "io"
 import "fmt"
 import "bytes"
+import "context"
 import "strings"
+import "reflect"
 import "testing"
 import _ "github.com/mattn/go-sqlite3"
 import "database/sql"
@@ -59,6 +61,38 @@ func Test_Book(t *testing.T) {
 			expected := "WHERE (books.system_id > ? AND books.system_id < ?) OR books.title LIKE ? OR books.title LIKE ?"
 			g.Assert(str).Equal(expected)
 		})
+
+		g.It("returns negated clauses for the exclusion fields", func() {
+			str := fmt.Sprintf("%s", &BookBlueprint{AuthorIDNotIn: []int{1, 2}})
+			g.Assert(str).Equal("WHERE books.author NOT IN (?,?)")
+
+			str = fmt.Sprintf("%s", &BookBlueprint{YearPublishedNotRange: []int{2000, 2010}})
+			g.Assert(str).Equal("WHERE (books.year_published <= ? OR books.year_published >= ?)")
+		})
+
+		g.It("nests the conditions of grouped blueprints within the clause", func() {
+			blueprint := &BookBlueprint{
+				YearPublishedRange: []int{2000, 2010},
+				Or: []*BookBlueprint{
+					{TitleLike: []string{"a%"}},
+					{TitleLike: []string{"b%"}, AuthorID: []int{1}},
+				},
+			}
+			expected := "WHERE (books.year_published > ? AND books.year_published < ?) AND ((books.title LIKE ?) OR " +
+				"(books.author IN (?) AND books.title LIKE ?))"
+			g.Assert(fmt.Sprintf("%s", blueprint)).Equal(expected)
+			g.Assert(blueprint.Values()).Equal([]interface{}{2000, 2010, "a%", 1, "b%"})
+		})
+
+		g.It("leaves empty groups and blueprints out of the clause", func() {
+			str := fmt.Sprintf("%s", &BookBlueprint{And: []*BookBlueprint{nil, {}, {ID: []int{1}}}, Or: []*BookBlueprint{}})
+			g.Assert(str).Equal("WHERE (books.system_id IN (?))")
+		})
+
+		g.It("excludes every NOT LIKE pattern, even for inclusive blueprints", func() {
+			str := fmt.Sprintf("%s", &BookBlueprint{TitleNotLike: []string{"a", "b"}, Inclusive: true})
+			g.Assert(str).Equal("WHERE NOT books.title LIKE ? AND NOT books.title LIKE ?")
+		})
 	})
 
 	g.Describe("Book model & generated store", func() {
@@ -91,26 +125,26 @@ func Test_Book(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for books w/o a blueprint (default limit)", func() {
-			books, e := store.FindBooks(nil)
+			books, _, e := store.FindBooks(nil)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(10)
 		})
 
 		g.It("allows the consumer to search for books w/ blueprint (explicit offset)", func() {
-			books, e := store.FindBooks(&BookBlueprint{Offset: 1})
+			books, _, e := store.FindBooks(&BookBlueprint{Offset: 1})
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(10)
 			g.Assert(books[0].ID).Equal(2)
 		})
 
 		g.It("allows the consumer to search for books w/ blueprint (explicit offset)", func() {
-			books, e := store.FindBooks(&BookBlueprint{Limit: 20})
+			books, _, e := store.FindBooks(&BookBlueprint{Limit: 20})
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(20)
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the series id (not null)", func() {
-			books, e := store.FindBooks(&BookBlueprint{SeriesID: []sql.NullInt64{}})
+			books, _, e := store.FindBooks(&BookBlueprint{SeriesID: []sql.NullInt64{}})
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(0)
 		})
@@ -128,7 +162,7 @@ func Test_Book(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the series id (null)", func() {
-			books, e := store.FindBooks(&BookBlueprint{SeriesID: []sql.NullInt64{
+			books, _, e := store.FindBooks(&BookBlueprint{SeriesID: []sql.NullInt64{
 				{Valid: false},
 			}})
 			g.Assert(e).Equal(nil)
@@ -136,7 +170,7 @@ func Test_Book(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the series id (valid)", func() {
-			books, e := store.FindBooks(&BookBlueprint{SeriesID: []sql.NullInt64{
+			books, _, e := store.FindBooks(&BookBlueprint{SeriesID: []sql.NullInt64{
 				{Valid: true, Int64: 10},
 			}})
 			g.Assert(e).Equal(nil)
@@ -144,55 +178,81 @@ func Test_Book(t *testing.T) {
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the year published", func() {
-			books, e := store.FindBooks(&BookBlueprint{YearPublished: []int{2001, 2002}})
+			books, _, e := store.FindBooks(&BookBlueprint{YearPublished: []int{2001, 2002}})
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(2)
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the author id", func() {
 			q := &BookBlueprint{AuthorID: []int{11, 21, 100}}
-			books, e := store.FindBooks(q)
+			books, _, e := store.FindBooks(q)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(2)
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the id", func() {
 			q := &BookBlueprint{ID: []int{1, 2, 10e3}}
-			books, e := store.FindBooks(q)
+			books, _, e := store.FindBooks(q)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(2)
 		})
 
 		g.It("allows the consumer to search for books w/ an exact match on the title", func() {
 			q := &BookBlueprint{Title: []string{"book-1"}}
-			books, e := store.FindBooks(q)
+			books, _, e := store.FindBooks(q)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(1)
 		})
 
 		g.It("allows the consumer to search for books w/ an author id range", func() {
 			q := &BookBlueprint{AuthorIDRange: []int{0, 20}}
-			books, e := store.FindBooks(q)
+			books, _, e := store.FindBooks(q)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(1)
 		})
 
 		g.It("allows the consumer to search for books w/ a year published range", func() {
 			q := &BookBlueprint{YearPublishedRange: []int{2000, 2002}}
-			books, e := store.FindBooks(q)
+			books, _, e := store.FindBooks(q)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(1)
 		})
 
 		g.It("allows the consumer to search for books w/ an id range", func() {
 			q := &BookBlueprint{IDRange: []int{0, 3}}
-			books, e := store.FindBooks(q)
+			books, _, e := store.FindBooks(q)
 			g.Assert(e).Equal(nil)
 			g.Assert(len(books)).Equal(2)
 		})
 
+		g.It("allows the consumer to exclude books by author, title and year published", func() {
+			count, e := store.CountBooks(&BookBlueprint{AuthorIDNotIn: []int{11, 21}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(testBookCount - 2)
+
+			count, e = store.CountBooks(&BookBlueprint{TitleNotLike: []string{"book-1%"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(testBookCount - 62)
+
+			ids, e := store.SelectBookIDs(&BookBlueprint{YearPublishedNotRange: []int{2001, 2003}, Limit: 3})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{1, 3, 4})
+		})
+
+		g.It("allows the consumer to search for books matching nested groups of blueprints", func() {
+			ids, e := store.SelectBookIDs(&BookBlueprint{
+				IDRange: []int{0, 20},
+				Or: []*BookBlueprint{
+					{Title: []string{"book-2"}},
+					{TitleLike: []string{"book-1_"}, YearPublished: []int{2005, 20011}},
+				},
+			})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{2, 11})
+		})
+
 		g.It("allows the consumer to search for books w/ multiple fields", func() {
-			books, e := store.FindBooks(&BookBlueprint{
+			books, _, e := store.FindBooks(&BookBlueprint{
 				ID:                 []int{1},
 				YearPublishedRange: []int{2000, 2003},
 			})
@@ -201,6 +261,161 @@ func Test_Book(t *testing.T) {
 			g.Assert(len(books)).Equal(1)
 		})
 
+		g.Describe("ordering with the blueprint OrderBy and OrderDirection", func() {
+
+			g.It("orders results by the requested column and direction", func() {
+				books, _, e := store.FindBooks(&BookBlueprint{OrderBy: "year_published", OrderDirection: "desc"})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(books)).Equal(10)
+				g.Assert(books[0].YearPublished).Equal(200150)
+			})
+
+			g.It("supports multiple sort keys with individual directions", func() {
+				books, _, e := store.FindBooks(&BookBlueprint{OrderBy: "author DESC, system_id"})
+				g.Assert(e).Equal(nil)
+				g.Assert(books[0].ID).Equal(testBookCount)
+			})
+
+			g.It("applies the ordering to paginated lookups", func() {
+				books, _, e := store.FindBooks(&BookBlueprint{OrderBy: "system_id", OrderDirection: "DESC", Offset: 10})
+				g.Assert(e).Equal(nil)
+				g.Assert(books[0].ID).Equal(testBookCount - 10)
+			})
+
+			g.It("applies the ordering to field selection", func() {
+				ids, e := store.SelectBookIDs(&BookBlueprint{OrderBy: "system_id", OrderDirection: "DESC", Limit: 2})
+				g.Assert(e).Equal(nil)
+				g.Assert(ids).Equal([]int{testBookCount, testBookCount - 1})
+			})
+
+			g.It("returns an error when ordering by an unknown column", func() {
+				_, _, e := store.FindBooks(&BookBlueprint{OrderBy: "system_id; DROP TABLE books"})
+				g.Assert(e == nil).Equal(false)
+			})
+
+			g.It("returns an error when ordering with an invalid direction", func() {
+				_, e := store.SelectBookTitles(&BookBlueprint{OrderBy: "title", OrderDirection: "sideways"})
+				g.Assert(e == nil).Equal(false)
+			})
+
+		})
+
+		g.Describe("paging with the blueprint Cursor", func() {
+			g.It("returns the cursor of the next page in the requested order", func() {
+				blueprint := &BookBlueprint{OrderBy: "year_published", OrderDirection: "DESC", Limit: 5}
+
+				first, next, e := store.FindBooks(blueprint)
+				g.Assert(e).Equal(nil)
+				g.Assert(next == "").Equal(false)
+
+				blueprint.Cursor = next
+				second, _, e := store.FindBooks(blueprint)
+				g.Assert(e).Equal(nil)
+				g.Assert(len(second)).Equal(5)
+				g.Assert(second[0].YearPublished < first[4].YearPublished).Equal(true)
+			})
+
+			g.It("matches the results of offset pagination", func() {
+				offset, _, e := store.FindBooks(&BookBlueprint{OrderBy: "author DESC", Limit: 3, Offset: 3})
+				g.Assert(e).Equal(nil)
+
+				_, next, e := store.FindBooks(&BookBlueprint{OrderBy: "author DESC", Limit: 3})
+				g.Assert(e).Equal(nil)
+
+				keyset, _, e := store.FindBooks(&BookBlueprint{OrderBy: "author DESC", Limit: 3, Cursor: next})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(keyset)).Equal(3)
+
+				for i, book := range keyset {
+					g.Assert(book.ID).Equal(offset[i].ID)
+				}
+			})
+
+			g.It("pages through the NULL values of nullable order columns", func() {
+				total, e := store.CountBooks(nil)
+				g.Assert(e).Equal(nil)
+
+				for _, direction := range []string{"ASC", "DESC"} {
+					seen := make(map[int]bool)
+					blueprint := &BookBlueprint{OrderBy: "series", OrderDirection: direction, Limit: 7}
+
+					for {
+						books, next, e := store.FindBooks(blueprint)
+						g.Assert(e).Equal(nil)
+
+						for _, book := range books {
+							seen[book.ID] = true
+						}
+
+						if next == "" {
+							break
+						}
+
+						blueprint.Cursor = next
+					}
+
+					g.Assert(len(seen)).Equal(total)
+				}
+			})
+
+			g.It("ignores the offset of blueprints continuing from a cursor", func() {
+				_, next, e := store.FindBooks(&BookBlueprint{Limit: 3})
+				g.Assert(e).Equal(nil)
+
+				keyset, _, e := store.FindBooks(&BookBlueprint{Limit: 3, Cursor: next})
+				g.Assert(e).Equal(nil)
+
+				offset, _, e := store.FindBooks(&BookBlueprint{Limit: 3, Cursor: next, Offset: 3})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(offset)).Equal(len(keyset))
+
+				for i, book := range offset {
+					g.Assert(book.ID).Equal(keyset[i].ID)
+				}
+			})
+
+			g.It("rejects invalid cursors in updates and deletions", func() {
+				blueprint := &BookBlueprint{ID: []int{1}, Cursor: "invalid"}
+
+				_, e := store.UpdateBookTitle("cursor-update", blueprint)
+				g.Assert(e == nil).Equal(false)
+
+				_, e = store.DeleteBooks(blueprint)
+				g.Assert(e == nil).Equal(false)
+
+				c, e := store.CountBooks(&BookBlueprint{ID: []int{1}})
+				g.Assert(e).Equal(nil)
+				g.Assert(c).Equal(1)
+			})
+		})
+
+		g.Describe("context-aware store methods", func() {
+
+			g.It("performs lookups with the provided context", func() {
+				books, _, e := store.FindBooksContext(context.Background(), &BookBlueprint{ID: []int{1, 2}})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(books)).Equal(2)
+			})
+
+			g.It("returns the context error when the context has been cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				_, e := store.CountBooksContext(ctx, nil)
+				g.Assert(e).Equal(context.Canceled)
+			})
+
+			g.It("does not execute updates once the context has been cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				_, e := store.UpdateBookTitleContext(ctx, "cancelled", &BookBlueprint{ID: []int{1}})
+				g.Assert(e).Equal(context.Canceled)
+				titles, e := store.SelectBookTitles(&BookBlueprint{ID: []int{1}})
+				g.Assert(e).Equal(nil)
+				g.Assert(titles).Equal([]string{"book-1"})
+			})
+
+		})
+
 		g.Describe("store.CountBooks", func() {
 
 			g.It("allows the consumer to count books with nil blueprint", func() {
@@ -219,6 +434,140 @@ func Test_Book(t *testing.T) {
 
 		})
 
+		g.Describe("store.CountBooksBy<Field>", func() {
+			g.It("counts the books matched by the blueprint for each value of the field", func() {
+				counts, e := store.CountBooksByAuthorID(&BookBlueprint{ID: []int{1, 2, 3}})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(counts)).Equal(3)
+				g.Assert(counts[0]).Equal(BookAuthorIDCount{AuthorID: 11, Total: 1})
+				g.Assert(counts[2]).Equal(BookAuthorIDCount{AuthorID: 31, Total: 1})
+			})
+
+			g.It("groups NULL values together", func() {
+				total, e := store.CountBooks(nil)
+				g.Assert(e).Equal(nil)
+
+				counts, e := store.CountBooksBySeriesID(nil)
+				g.Assert(e).Equal(nil)
+				g.Assert(len(counts)).Equal(1)
+				g.Assert(counts[0].SeriesID.Valid).Equal(false)
+				g.Assert(counts[0].Total).Equal(total)
+			})
+
+			g.It("applies the blueprint limit and offset to the groups", func() {
+				counts, e := store.CountBooksByAuthorID(&BookBlueprint{Limit: 2, Offset: 1})
+				g.Assert(e).Equal(nil)
+				g.Assert(len(counts)).Equal(2)
+				g.Assert(counts[0].AuthorID).Equal(21)
+				g.Assert(counts[1].AuthorID).Equal(31)
+			})
+
+			g.It("does not count the books by their primary key", func() {
+				_, counted := reflect.TypeOf(store).MethodByName("CountBooksByID")
+				g.Assert(counted).Equal(false)
+			})
+		})
+
+		g.Describe("numeric field aggregates", func() {
+			g.It("returns the earliest year published", func() {
+				year, e := store.MinBookYearPublished(nil)
+				g.Assert(e).Equal(nil)
+				g.Assert(year.Valid).Equal(true)
+				g.Assert(year.Int64).Equal(int64(2001))
+			})
+
+			g.It("sums the years published of the books matched by the blueprint", func() {
+				sum, e := store.SumBookYearPublished(&BookBlueprint{ID: []int{1, 2}})
+				g.Assert(e).Equal(nil)
+				g.Assert(sum.Int64).Equal(int64(4003))
+			})
+
+			g.It("averages the years published of the books matched by the blueprint", func() {
+				average, e := store.AvgBookYearPublished(&BookBlueprint{ID: []int{1, 2, 3}})
+				g.Assert(e).Equal(nil)
+				g.Assert(average.Valid).Equal(true)
+				g.Assert(average.Float64).Equal(float64(2002))
+			})
+
+			g.It("does not aggregate the primary key or the ids of referenced authors", func() {
+				_, summed := store.(interface {
+					SumBookID(*BookBlueprint) (sql.NullInt64, error)
+				})
+				g.Assert(summed).Equal(false)
+
+				_, averaged := store.(interface {
+					AvgBookAuthorID(*BookBlueprint) (sql.NullFloat64, error)
+				})
+				g.Assert(averaged).Equal(false)
+			})
+
+			g.It("returns an invalid result when only NULL values are matched", func() {
+				series, e := store.MaxBookSeriesID(&BookBlueprint{ID: []int{1, 2}})
+				g.Assert(e).Equal(nil)
+				g.Assert(series.Valid).Equal(false)
+			})
+
+			g.It("returns an invalid result when no books are matched", func() {
+				sum, e := store.SumBookYearPublished(&BookBlueprint{ID: []int{98765}})
+				g.Assert(e).Equal(nil)
+				g.Assert(sum.Valid).Equal(false)
+			})
+		})
+
+		g.Describe("relationship preloads", func() {
+			var authors AuthorStore
+
+			g.Before(func() {
+				for _, id := range []int{11, 21} {
+					statement := "insert into authors (system_id,name,birthday) values(%d,'author-%d',date());"
+					_, e := db.Exec(fmt.Sprintf(statement, id, id))
+					g.Assert(e).Equal(nil)
+				}
+			})
+
+			g.BeforeEach(func() {
+				authors = NewAuthorStore(db, queryLog)
+			})
+
+			g.It("loads the authors referenced by the books in a single lookup", func() {
+				books, _, e := store.FindBooks(&BookBlueprint{ID: []int{1, 2, 3}})
+				g.Assert(e).Equal(nil)
+
+				preloaded, e := store.PreloadAuthors(append(books, nil))
+				g.Assert(e).Equal(nil)
+				g.Assert(len(preloaded)).Equal(2)
+				g.Assert(preloaded[11].Name).Equal("author-11")
+				g.Assert(preloaded[21].Name).Equal("author-21")
+				g.Assert(strings.Count(fmt.Sprintf("%s", queryLog), "FROM authors")).Equal(1)
+			})
+
+			g.It("skips the lookup when no books are provided", func() {
+				preloaded, e := store.PreloadAuthors(nil)
+				g.Assert(e).Equal(nil)
+				g.Assert(len(preloaded)).Equal(0)
+				g.Assert(strings.Contains(fmt.Sprintf("%s", queryLog), "FROM authors")).Equal(false)
+			})
+
+			g.It("loads the books referencing each author, grouped by author", func() {
+				found, _, e := authors.FindAuthors(&AuthorBlueprint{ID: []int{11, 21}})
+				g.Assert(e).Equal(nil)
+
+				preloaded, e := authors.PreloadBooksByAuthor(found)
+				g.Assert(e).Equal(nil)
+				g.Assert(len(preloaded)).Equal(2)
+
+				for _, id := range []int{11, 21} {
+					count, e := store.CountBooks(&BookBlueprint{AuthorID: []int{id}})
+					g.Assert(e).Equal(nil)
+					g.Assert(len(preloaded[id])).Equal(count)
+
+					for _, book := range preloaded[id] {
+						g.Assert(book.AuthorID).Equal(id)
+					}
+				}
+			})
+		})
+
 		g.It("allows the consumer to select explicit book ids", func() {
 			results, e := store.SelectBookIDs(&BookBlueprint{
 				ID: []int{1, 2},
@@ -229,7 +578,7 @@ func Test_Book(t *testing.T) {
 		})
 
 		g.It("returns source struct instances that support source method calls", func() {
-			results, e := store.FindBooks(&BookBlueprint{
+			results, _, e := store.FindBooks(&BookBlueprint{
 				ID: []int{1},
 			})
 			g.Assert(e).Equal(nil)
@@ -263,6 +612,18 @@ func Test_Book(t *testing.T) {
 			g.Assert(results).Equal(int64(1))
 		})
 
+		g.It("rejects book title updates that fail the title's validation rules", func() {
+			_, e := store.UpdateBookTitle(strings.Repeat("a", 256), &BookBlueprint{ID: []int{1}})
+			g.Assert(e == nil).Equal(false)
+			g.Assert(e.Error()).Equal("invalid book: Title failed max=255")
+		})
+
+		g.It("rejects saving books that fail their validation rules", func() {
+			_, e := store.SaveBook(&Book{ID: 1, Title: "Marlow the puppy", YearPublished: -10})
+			_, ok := e.(*BookValidationError)
+			g.Assert(ok).Equal(true)
+		})
+
 		g.It("allows the consumer to update the author id", func() {
 			results, e := store.UpdateBookAuthorID(2001, &BookBlueprint{
 				ID: []int{1},
@@ -316,6 +677,152 @@ func Test_Book(t *testing.T) {
 			g.Assert(results).Equal(int64(1))
 		})
 
+		g.Describe("GetBook", func() {
+			g.It("returns the book with the requested primary key", func() {
+				book, e := store.GetBook(3)
+				g.Assert(e).Equal(nil)
+				g.Assert(book.ID).Equal(3)
+				g.Assert(book.Title).Equal("book-3")
+			})
+
+			g.It("returns a typed not found error when no book has the primary key", func() {
+				book, e := store.GetBook(98765)
+				g.Assert(book == nil).Equal(true)
+				notFound, ok := e.(*BookNotFoundError)
+				g.Assert(ok).Equal(true)
+				g.Assert(notFound.ID).Equal(98765)
+			})
+		})
+
+		g.Describe("SaveBook", func() {
+			g.It("updates every column of the book by primary key", func() {
+				book, e := store.GetBook(4)
+				g.Assert(e).Equal(nil)
+
+				book.Title = "book-4 (revised)"
+				book.YearPublished = 1999
+				book.SeriesID = sql.NullInt64{Int64: 7, Valid: true}
+
+				count, e := store.SaveBook(book)
+				g.Assert(e).Equal(nil)
+				g.Assert(count).Equal(int64(1))
+
+				saved, e := store.GetBook(4)
+				g.Assert(e).Equal(nil)
+				g.Assert(saved.Title).Equal("book-4 (revised)")
+				g.Assert(saved.YearPublished).Equal(1999)
+				g.Assert(saved.SeriesID.Int64).Equal(int64(7))
+			})
+
+			g.It("returns 0 when no book has the primary key", func() {
+				count, e := store.SaveBook(&Book{ID: 98765, Title: "missing"})
+				g.Assert(e).Equal(nil)
+				g.Assert(count).Equal(int64(0))
+			})
+
+			g.It("returns an error when given a nil book", func() {
+				_, e := store.SaveBook(nil)
+				g.Assert(e == nil).Equal(false)
+			})
+		})
+
+		g.Describe("IterateBooks", func() {
+			g.It("scans every matching book without applying the default limit", func() {
+				total, e := store.CountBooks(nil)
+				g.Assert(e).Equal(nil)
+
+				cursor, e := store.IterateBooks(nil)
+				g.Assert(e).Equal(nil)
+				defer cursor.Close()
+
+				count := 0
+
+				for cursor.Next() {
+					g.Assert(cursor.Record() == nil).Equal(false)
+					count++
+				}
+
+				g.Assert(cursor.Err()).Equal(nil)
+				g.Assert(count).Equal(total)
+				g.Assert(count > 10).Equal(true)
+			})
+
+			g.It("respects an explicit offset without a limit", func() {
+				total, e := store.CountBooks(nil)
+				g.Assert(e).Equal(nil)
+
+				cursor, e := store.IterateBooks(&BookBlueprint{Offset: total - 2})
+				g.Assert(e).Equal(nil)
+				defer cursor.Close()
+
+				count := 0
+
+				for cursor.Next() {
+					count++
+				}
+
+				g.Assert(cursor.Err()).Equal(nil)
+				g.Assert(count).Equal(2)
+			})
+		})
+
+		g.Describe("EachBooks", func() {
+			g.It("calls the function with every matching book in primary key order", func() {
+				total, e := store.CountBooks(nil)
+				g.Assert(e).Equal(nil)
+
+				ids := make([]int, 0, total)
+
+				e = store.EachBooks(nil, 7, func(book *Book) error {
+					ids = append(ids, book.ID)
+					return nil
+				})
+
+				g.Assert(e).Equal(nil)
+				g.Assert(len(ids)).Equal(total)
+
+				for i := 1; i < len(ids); i++ {
+					g.Assert(ids[i] > ids[i-1]).Equal(true)
+				}
+			})
+
+			g.It("combines the batches with the blueprint clauses", func() {
+				blueprint := &BookBlueprint{ID: []int{20, 22, 24, 26, 28}}
+				count := 0
+
+				e := store.EachBooks(blueprint, 2, func(*Book) error {
+					count++
+					return nil
+				})
+
+				g.Assert(e).Equal(nil)
+				g.Assert(count).Equal(5)
+				g.Assert(blueprint.Limit).Equal(0)
+			})
+
+			g.It("stops and returns the error returned by the function", func() {
+				count := 0
+
+				e := store.EachBooks(nil, 3, func(*Book) error {
+					count++
+
+					if count == 5 {
+						return fmt.Errorf("stop")
+					}
+
+					return nil
+				})
+
+				g.Assert(e == nil).Equal(false)
+				g.Assert(count).Equal(5)
+			})
+
+			g.It("returns an error with an invalid batch size", func() {
+				e := store.EachBooks(nil, 0, func(*Book) error { return nil })
+				g.Assert(e == nil).Equal(false)
+			})
+		})
+
 		g.It("allows the consumer to select explicit year published", func() {
 			results, e := store.SelectBookYearPublisheds(&BookBlueprint{
 				ID: []int{1, 2},
@@ -369,15 +876,241 @@ func Test_Book(t *testing.T) {
 				}...)
 				g.Assert(e).Equal(nil)
 
-				p, e := store.FindBooks(&BookBlueprint{ID: []int{int(s)}})
+				p, _, e := store.FindBooks(&BookBlueprint{ID: []int{int(s)}})
 				g.Assert(e).Equal(nil)
 				g.Assert(p[0].Title).Equal("Harry Potter")
 
-				found, e := store.FindBooks(&BookBlueprint{Title: []string{"Harry Potter"}})
+				found, _, e := store.FindBooks(&BookBlueprint{Title: []string{"Harry Potter"}})
 				g.Assert(e).Equal(nil)
 				g.Assert(len(found)).Equal(1)
 				g.Assert(found[0].ID > 0).Equal(true)
 			})
+
+			g.It("rejects books that fail their validation rules without creating any", func() {
+				_, e := store.CreateBooks([]Book{
+					{Title: "Valid Book", YearPublished: 2001},
+					{Title: "", YearPublished: -1},
+				}...)
+
+				invalid, ok := e.(*BookValidationError)
+				g.Assert(ok).Equal(true)
+				g.Assert(invalid.Fields).Equal([]BookFieldError{
+					{Field: "Title", Rule: "required"},
+					{Field: "YearPublished", Rule: "min=0"},
+				})
+
+				count, e := store.CountBooks(&BookBlueprint{Title: []string{"Valid Book"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(count).Equal(0)
+			})
+		})
+
+		g.Describe("Transaction", func() {
+			g.It("commits the changes made through the transaction-bound store", func() {
+				e := store.Transaction(func(books BookStore, _ BookStoreExecutor) error {
+					_, e := books.CreateBooks(Book{Title: "transaction-commit"})
+					return e
+				})
+				g.Assert(e).Equal(nil)
+
+				c, e := store.CountBooks(&BookBlueprint{Title: []string{"transaction-commit"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(c).Equal(1)
+			})
+
+			g.It("rolls back the changes and returns the error from the callback", func() {
+				failure := fmt.Errorf("failed")
+
+				e := store.Transaction(func(books BookStore, _ BookStoreExecutor) error {
+					if _, e := books.CreateBooks(Book{Title: "transaction-rollback"}); e != nil {
+						return e
+					}
+
+					return failure
+				})
+				g.Assert(e).Equal(failure)
+
+				c, e := store.CountBooks(&BookBlueprint{Title: []string{"transaction-rollback"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(c).Equal(0)
+			})
+
+			g.It("shares the transaction with other stores bound to the executor", func() {
+				authors := NewAuthorStore(db, queryLog)
+
+				e := store.Transaction(func(books BookStore, tx BookStoreExecutor) error {
+					if _, e := authors.WithExecutor(tx).CreateAuthors(Author{Name: "transaction-author"}); e != nil {
+						return e
+					}
+
+					if _, e := books.CreateBooks(Book{Title: "transaction-shared"}); e != nil {
+						return e
+					}
+
+					return fmt.Errorf("failed")
+				})
+				g.Assert(e == nil).Equal(false)
+
+				c, e := authors.CountAuthors(&AuthorBlueprint{Name: []string{"transaction-author"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(c).Equal(0)
+			})
+
+			g.It("uses savepoints to roll back nested transactions independently", func() {
+				e := store.Transaction(func(books BookStore, _ BookStoreExecutor) error {
+					if _, e := books.CreateBooks(Book{Title: "transaction-outer"}); e != nil {
+						return e
+					}
+
+					inner := books.Transaction(func(nested BookStore, _ BookStoreExecutor) error {
+						if _, e := nested.CreateBooks(Book{Title: "transaction-inner"}); e != nil {
+							return e
+						}
+
+						return fmt.Errorf("failed")
+					})
+
+					g.Assert(inner == nil).Equal(false)
+					return nil
+				})
+				g.Assert(e).Equal(nil)
+
+				outer, e := store.CountBooks(&BookBlueprint{Title: []string{"transaction-outer"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(outer).Equal(1)
+
+				inner, e := store.CountBooks(&BookBlueprint{Title: []string{"transaction-inner"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(inner).Equal(0)
+			})
+
+			g.It("gives each level of nested transactions a savepoint of its own", func() {
+				e := store.Transaction(func(books BookStore, _ BookStoreExecutor) error {
+					return books.Transaction(func(middle BookStore, _ BookStoreExecutor) error {
+						if _, e := middle.CreateBooks(Book{Title: "transaction-middle"}); e != nil {
+							return e
+						}
+
+						inner := middle.Transaction(func(nested BookStore, _ BookStoreExecutor) error {
+							if _, e := nested.CreateBooks(Book{Title: "transaction-innermost"}); e != nil {
+								return e
+							}
+
+							return fmt.Errorf("failed")
+						})
+
+						g.Assert(inner.Error()).Equal("failed")
+						return nil
+					})
+				})
+				g.Assert(e).Equal(nil)
+
+				middle, e := store.CountBooks(&BookBlueprint{Title: []string{"transaction-middle"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(middle).Equal(1)
+
+				inner, e := store.CountBooks(&BookBlueprint{Title: []string{"transaction-innermost"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(inner).Equal(0)
+			})
+		})
+
+		g.Describe("Stores", func() {
+			var stores *Stores
+
+			g.BeforeEach(func() {
+				stores = &Stores{Books: store, Authors: NewAuthorStore(db, queryLog)}
+			})
+
+			g.It("binds every store present to a single transaction", func() {
+				e := stores.Transaction(func(tx *Stores) error {
+					if _, e := tx.Authors.CreateAuthors(Author{Name: "stores-author"}); e != nil {
+						return e
+					}
+
+					if _, e := tx.Books.CreateBooks(Book{Title: "stores-book"}); e != nil {
+						return e
+					}
+
+					return fmt.Errorf("failed")
+				})
+				g.Assert(e.Error()).Equal("failed")
+
+				authors, e := stores.Authors.CountAuthors(&AuthorBlueprint{Name: []string{"stores-author"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(authors).Equal(0)
+
+				books, e := store.CountBooks(&BookBlueprint{Title: []string{"stores-book"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(books).Equal(0)
+			})
+
+			g.It("shares the savepoints of the transaction between its stores", func() {
+				e := stores.Transaction(func(tx *Stores) error {
+					return tx.Books.Transaction(func(books BookStore, _ BookStoreExecutor) error {
+						if _, e := books.CreateBooks(Book{Title: "stores-savepoint"}); e != nil {
+							return e
+						}
+
+						inner := tx.Authors.Transaction(func(authors AuthorStore, _ AuthorStoreExecutor) error {
+							if _, e := authors.CreateAuthors(Author{Name: "stores-savepoint"}); e != nil {
+								return e
+							}
+
+							return fmt.Errorf("failed")
+						})
+
+						g.Assert(inner.Error()).Equal("failed")
+						return nil
+					})
+				})
+				g.Assert(e).Equal(nil)
+
+				books, e := store.CountBooks(&BookBlueprint{Title: []string{"stores-savepoint"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(books).Equal(1)
+
+				authors, e := stores.Authors.CountAuthors(&AuthorBlueprint{Name: []string{"stores-savepoint"}})
+				g.Assert(e).Equal(nil)
+				g.Assert(authors).Equal(0)
+			})
+
+			g.It("refuses transactions without any store", func() {
+				e := (&Stores{}).Transaction(func(*Stores) error {
+					return nil
+				})
+				g.Assert(e.Error()).Equal("transactions require at least one store")
+			})
+		})
+
+		g.Describe("VerifyBookSchema", func() {
+			g.It("succeeds against the library schema", func() {
+				g.Assert(VerifyBookSchema(db)).Equal(nil)
+			})
+
+			g.It("reports the columns of tables that drifted from the fields of the book", func() {
+				drifted, e := sql.Open("sqlite3", "./book-drift-testing.db")
+				g.Assert(e).Equal(nil)
+				defer os.Remove("./book-drift-testing.db")
+				defer drifted.Close()
+
+				e = VerifyBookSchema(drifted)
+				g.Assert(e.Error()).Equal("invalid books table: table books does not exist")
+
+				statement := "create table books (system_id INTEGER PRIMARY KEY, title TEXT, author TEXT NOT NULL, year_published INTEGER);"
+				_, e = drifted.Exec(statement)
+				g.Assert(e).Equal(nil)
+
+				e = VerifyBookSchema(drifted)
+				schemaError, ok := e.(*BookSchemaError)
+				g.Assert(ok).Equal(true)
+				g.Assert(schemaError.Problems).Equal([]string{
+					"column author has type text, expected an integer type for Book.AuthorID",
+					"missing column series",
+					"column title is nullable but Book.Title is not",
+					"column year_published is nullable but Book.YearPublished is not",
+				})
+			})
 		})
 
 		g.Describe("findAuthors", func() {
@@ -399,4 +1132,190 @@ func Test_Book(t *testing.T) {
 			})
 		})
 	})
+
+	g.Describe("Book in-memory store", func() {
+		var fake BookStore
+
+		g.BeforeEach(func() {
+			fake = NewFakeBookStore(
+				Book{Title: "the first book", AuthorID: 1, YearPublished: 1990},
+				Book{Title: "The Second Book", AuthorID: 2, YearPublished: 2000},
+				Book{ID: 10, Title: "a third book", AuthorID: 1, YearPublished: 2010},
+			)
+		})
+
+		g.It("assigns the next id to records seeded or created without one", func() {
+			id, e := fake.CreateBooks(Book{Title: "a fourth book"}, Book{ID: 3, Title: "a fifth book"})
+			g.Assert(e).Equal(nil)
+			g.Assert(id).Equal(int64(12))
+
+			book, e := fake.GetBook(2)
+			g.Assert(e).Equal(nil)
+			g.Assert(book.Title).Equal("The Second Book")
+		})
+
+		g.It("returns the not found error when getting books that do not exist", func() {
+			_, e := fake.GetBook(4)
+			_, ok := e.(*BookNotFoundError)
+			g.Assert(ok).Equal(true)
+		})
+
+		g.It("validates the books being created", func() {
+			_, e := fake.CreateBooks(Book{})
+			_, ok := e.(*BookValidationError)
+			g.Assert(ok).Equal(true)
+		})
+
+		g.It("finds books matching every clause of the blueprint", func() {
+			books, _, e := fake.FindBooks(&BookBlueprint{AuthorID: []int{1}, YearPublishedRange: []int{1995, 2020}})
+			g.Assert(e).Equal(nil)
+			g.Assert(len(books)).Equal(1)
+			g.Assert(books[0].ID).Equal(10)
+		})
+
+		g.It("excludes books matched by the negated clauses of the blueprint", func() {
+			ids, e := fake.SelectBookIDs(&BookBlueprint{AuthorIDNotIn: []int{2}, TitleNotLike: []string{"a %"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{1})
+
+			ids, e = fake.SelectBookIDs(&BookBlueprint{YearPublishedNotRange: []int{1990, 2010}})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{1, 10})
+		})
+
+		g.It("finds books matching nested groups of blueprints", func() {
+			ids, e := fake.SelectBookIDs(&BookBlueprint{
+				AuthorID: []int{1},
+				Or:       []*BookBlueprint{{YearPublished: []int{1990}}, {TitleLike: []string{"a %"}}, nil, {}},
+			})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{1, 10})
+
+			count, e := fake.CountBooks(&BookBlueprint{And: []*BookBlueprint{{AuthorID: []int{1}}, {Title: []string{"x"}}}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(0)
+		})
+
+		g.It("finds books with or without a series by their null filter", func() {
+			_, e := fake.UpdateBookSeriesID(&sql.NullInt64{Int64: 1, Valid: true}, &BookBlueprint{ID: []int{10}})
+			g.Assert(e).Equal(nil)
+
+			isNull, notNull := true, false
+			ids, e := fake.SelectBookIDs(&BookBlueprint{SeriesIDIsNull: &notNull})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{10})
+
+			count, e := fake.CountBooks(&BookBlueprint{SeriesIDIsNull: &isNull})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(2)
+		})
+
+		g.It("excludes books by series, never matching books without one", func() {
+			_, e := fake.UpdateBookSeriesID(&sql.NullInt64{Int64: 1, Valid: true}, &BookBlueprint{ID: []int{10}})
+			g.Assert(e).Equal(nil)
+
+			count, e := fake.CountBooks(&BookBlueprint{SeriesIDNotIn: []sql.NullInt64{{Int64: 1, Valid: true}}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(0)
+
+			ids, e := fake.SelectBookIDs(&BookBlueprint{SeriesIDNotIn: []sql.NullInt64{{Int64: 2, Valid: true}}})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{10})
+
+			ids, e = fake.SelectBookIDs(&BookBlueprint{SeriesIDNotIn: []sql.NullInt64{{Valid: false}}})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{10})
+		})
+
+		g.It("finds books matching any clause of inclusive blueprints", func() {
+			count, e := fake.CountBooks(&BookBlueprint{ID: []int{2}, TitleLike: []string{"a %"}, Inclusive: true})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(2)
+		})
+
+		g.It("matches like patterns without regard to case", func() {
+			titles, e := fake.SelectBookTitles(&BookBlueprint{TitleLike: []string{"the%book"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(titles).Equal([]string{"the first book", "The Second Book"})
+		})
+
+		g.It("orders and pages the books found using the limit and offset of the blueprint", func() {
+			blueprint := &BookBlueprint{OrderBy: "year_published", OrderDirection: "DESC", Limit: 1, Offset: 1}
+			books, _, e := fake.FindBooks(blueprint)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(books)).Equal(1)
+			g.Assert(books[0].YearPublished).Equal(2000)
+		})
+
+		g.It("pages through the books found using the cursor of the blueprint", func() {
+			first, next, e := fake.FindBooks(&BookBlueprint{Limit: 2})
+			g.Assert(e).Equal(nil)
+			g.Assert(len(first)).Equal(2)
+
+			rest, next, e := fake.FindBooks(&BookBlueprint{Limit: 2, Cursor: next})
+			g.Assert(e).Equal(nil)
+			g.Assert(next).Equal("")
+			g.Assert(len(rest)).Equal(1)
+			g.Assert(rest[0].ID).Equal(10)
+		})
+
+		g.It("orders NULL values of nullable columns before every other value", func() {
+			_, e := fake.UpdateBookSeriesID(&sql.NullInt64{Int64: 1, Valid: true}, &BookBlueprint{ID: []int{10}})
+			g.Assert(e).Equal(nil)
+
+			for direction, expected := range map[string][]int{"ASC": {1, 2, 10}, "DESC": {10, 1, 2}} {
+				blueprint, ids := &BookBlueprint{OrderBy: "series", OrderDirection: direction, Limit: 1}, []int{}
+
+				for {
+					books, next, e := fake.FindBooks(blueprint)
+					g.Assert(e).Equal(nil)
+
+					for _, book := range books {
+						ids = append(ids, book.ID)
+					}
+
+					if next == "" {
+						break
+					}
+
+					blueprint.Cursor = next
+				}
+
+				g.Assert(ids).Equal(expected)
+			}
+		})
+
+		g.It("updates and deletes the books matched by the blueprint", func() {
+			count, e := fake.UpdateBookYearPublished(2001, &BookBlueprint{AuthorID: []int{1}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(2))
+
+			sum, e := fake.SumBookYearPublished(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(sum.Int64).Equal(int64(6002))
+
+			count, e = fake.DeleteBooks(&BookBlueprint{YearPublished: []int{2001}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(2))
+
+			remaining, e := fake.CountBooks(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(remaining).Equal(1)
+		})
+
+		g.It("restores the books held before transactions that fail", func() {
+			e := fake.Transaction(func(tx BookStore, _ BookStoreExecutor) error {
+				if _, e := tx.CreateBooks(Book{Title: "transaction-inner"}); e != nil {
+					return e
+				}
+
+				return fmt.Errorf("rollback")
+			})
+			g.Assert(e == nil).Equal(false)
+
+			count, e := fake.CountBooks(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(3)
+		})
+	})
 }
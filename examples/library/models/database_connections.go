@@ -71,9 +71,11 @@ func (db *DatabaseConnections) Initialize() error {
 // Stores builds the various model stores generated by marlow.
 func (db *DatabaseConnections) Stores(logger io.Writer) *Stores {
 	return &Stores{
-		Books:   NewBookStore(db.sqlite, logger),
-		Authors: NewAuthorStore(db.sqlite, logger),
-		Genres:  NewGenreStore(db.postgres, logger),
+		Books:       NewBookStore(db.sqlite, logger),
+		Authors:     NewAuthorStore(db.sqlite, logger),
+		BookAuthors: NewBookAuthorStore(db.sqlite, logger),
+		Genres:      NewGenreStore(db.postgres, logger),
+		Series:      NewSeriesStore(db.sqlite, logger),
 	}
 }
 
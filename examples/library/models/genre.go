@@ -8,7 +8,7 @@ type Genre struct {
 	table    bool          `marlow:"tableName=genres&dialect=postgres&primaryKey=id"`
 	ID       uint          `marlow:"column=id&autoIncrement=true"`
 	Name     string        `marlow:"column=name"`
-	ParentID sql.NullInt64 `marlow:"column=parent_id"`
+	ParentID sql.NullInt64 `marlow:"column=parent_id&references=Genre"`
 }
 
 func (g *Genre) String() string {
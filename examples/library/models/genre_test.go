@@ -126,7 +126,7 @@ func Test_Genre(t *testing.T) {
 				})
 
 				g.It("supports or-ing clauses when blueprint set to be inclusive", func() {
-					genres, e := store.FindGenres(&GenreBlueprint{
+					genres, _, e := store.FindGenres(&GenreBlueprint{
 						NameLike:  []string{"%European%", "%American%"},
 						Inclusive: true,
 					})
@@ -181,7 +181,7 @@ func Test_Genre(t *testing.T) {
 				})
 
 				g.It("allows finding by id range (with offset and limit)", func() {
-					genres, e := store.FindGenres(&GenreBlueprint{
+					genres, _, e := store.FindGenres(&GenreBlueprint{
 						IDRange: []uint{0, 10},
 						Offset:  1,
 						Limit:   1,
@@ -192,7 +192,7 @@ func Test_Genre(t *testing.T) {
 				})
 
 				g.It("allows finding by id range", func() {
-					genres, e := store.FindGenres(&GenreBlueprint{
+					genres, _, e := store.FindGenres(&GenreBlueprint{
 						IDRange: []uint{0, 10},
 					})
 					g.Assert(e).Equal(nil)
@@ -4,6 +4,6 @@ package models
 type MultiAuto struct {
 	table  bool   `marlow:"tableName=multi_auto&dialect=postgres&primaryKey=id"`
 	ID     uint   `marlow:"column=id&autoIncrement=true"`
-	Status string `marlow:"column=status&autoIncrement=true"`
+	Status string `marlow:"column=status&autoIncrement=true&default='pending'"`
 	Name   string `marlow:"column=name"`
 }
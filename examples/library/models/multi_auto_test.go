@@ -96,32 +96,32 @@ func Test_MultiAuto(t *testing.T) {
 			})
 
 			g.It("allows the consumer to query the records (by status)", func() {
-				_, e := store.FindMultiAutos(&MultiAutoBlueprint{Status: []string{"pending"}})
+				_, _, e := store.FindMultiAutos(&MultiAutoBlueprint{Status: []string{"pending"}})
 				g.Assert(e).Equal(nil)
 			})
 
 			g.It("allows the consumer to query the records (by status like)", func() {
-				_, e := store.FindMultiAutos(&MultiAutoBlueprint{StatusLike: []string{"%%pending%%"}})
+				_, _, e := store.FindMultiAutos(&MultiAutoBlueprint{StatusLike: []string{"%%pending%%"}})
 				g.Assert(e).Equal(nil)
 			})
 
 			g.It("allows the consumer to query the records (by name like)", func() {
-				_, e := store.FindMultiAutos(&MultiAutoBlueprint{NameLike: []string{"%%-1%%"}})
+				_, _, e := store.FindMultiAutos(&MultiAutoBlueprint{NameLike: []string{"%%-1%%"}})
 				g.Assert(e).Equal(nil)
 			})
 
 			g.It("allows the consumer to query the records (by name)", func() {
-				_, e := store.FindMultiAutos(&MultiAutoBlueprint{Name: []string{"first"}})
+				_, _, e := store.FindMultiAutos(&MultiAutoBlueprint{Name: []string{"first"}})
 				g.Assert(e).Equal(nil)
 			})
 
 			g.It("allows the consumer to query the records (by id)", func() {
-				_, e := store.FindMultiAutos(&MultiAutoBlueprint{ID: []uint{1}})
+				_, _, e := store.FindMultiAutos(&MultiAutoBlueprint{ID: []uint{1}})
 				g.Assert(e).Equal(nil)
 			})
 
 			g.It("allows the consumer to query the records (w limit)", func() {
-				_, e := store.FindMultiAutos(&MultiAutoBlueprint{Limit: 1})
+				_, _, e := store.FindMultiAutos(&MultiAutoBlueprint{Limit: 1})
 				g.Assert(e).Equal(nil)
 			})
 
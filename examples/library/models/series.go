@@ -0,0 +1,61 @@
+package models
+
+import "fmt"
+import "time"
+import "strings"
+import "database/sql"
+
+//go:generate marlowc -input series.go
+
+// Series records group books published as part of the same collection. Deleted series are kept around until purged
+// and concurrent updates are guarded by the version of the series they were made from.
+type Series struct {
+	table       bool           `marlow:"tableName=series&primaryKey=system_id&softDelete=deleted_at"`
+	ID          int            `marlow:"column=system_id&autoIncrement=true"`
+	Title       string         `marlow:"column=title"`
+	Subtitle    sql.NullString `marlow:"column=subtitle"`
+	Version     int            `marlow:"column=version&version=true&default=0"`
+	CompletedAt sql.NullTime   `marlow:"column=completed_at"`
+	CreatedAt   time.Time      `marlow:"column=created_at&autoCreateTime=true"`
+	UpdatedAt   time.Time      `marlow:"column=updated_at&autoUpdateTime=true"`
+}
+
+// BeforeCreate trims the title of the series, refusing to create series without one.
+func (s *Series) BeforeCreate() error {
+	return s.normalizeTitle()
+}
+
+// BeforeUpdate trims the title of the series, refusing to save series without one. Pinned series, whose titles are
+// prefixed with "pinned", are not updated.
+func (s *Series) BeforeUpdate() error {
+	if strings.HasPrefix(s.Title, "pinned") {
+		return fmt.Errorf("pinned series %d cannot be updated", s.ID)
+	}
+
+	return s.normalizeTitle()
+}
+
+// AfterFind converts the timestamps of the series to UTC, regardless of the location used by the database driver.
+func (s *Series) AfterFind() error {
+	s.CreatedAt, s.UpdatedAt = s.CreatedAt.UTC(), s.UpdatedAt.UTC()
+	return nil
+}
+
+// BeforeDelete prevents pinned series, whose titles are prefixed with "pinned", from being deleted.
+func (s *Series) BeforeDelete() error {
+	if strings.HasPrefix(s.Title, "pinned") {
+		return fmt.Errorf("pinned series %d cannot be deleted", s.ID)
+	}
+
+	return nil
+}
+
+func (s *Series) normalizeTitle() error {
+	s.Title = strings.TrimSpace(s.Title)
+
+	if s.Title == "" {
+		return fmt.Errorf("series must have a title")
+	}
+
+	return nil
+}
@@ -0,0 +1,481 @@
+package models
+
+import "os"
+import "io"
+import "fmt"
+import "bytes"
+import "time"
+import "testing"
+import "reflect"
+import _ "github.com/mattn/go-sqlite3"
+import "database/sql"
+import "github.com/franela/goblin"
+
+func Test_Series(t *testing.T) {
+	var db *sql.DB
+	var store SeriesStore
+	var queryLog io.Writer
+
+	g := goblin.Goblin(t)
+
+	dbFile := "./series-testing.db"
+
+	g.Describe("Series soft delete blueprint clauses", func() {
+		g.It("excludes soft deleted series by default", func() {
+			g.Assert(fmt.Sprintf("%s", &SeriesBlueprint{})).Equal("WHERE series.deleted_at IS NULL")
+		})
+
+		g.It("limits every clause of inclusive blueprints to the series that were not deleted", func() {
+			str := fmt.Sprintf("%s", &SeriesBlueprint{ID: []int{1}, Title: []string{"a"}, Inclusive: true})
+			expected := "WHERE (series.system_id IN (?) OR series.title IN (?)) AND series.deleted_at IS NULL"
+			g.Assert(str).Equal(expected)
+		})
+
+		g.It("omits the clause when including deleted series", func() {
+			g.Assert(fmt.Sprintf("%s", &SeriesBlueprint{IncludeDeleted: true})).Equal("")
+		})
+
+		g.It("limits lookups to deleted series when only deleted series are requested", func() {
+			str := fmt.Sprintf("%s", &SeriesBlueprint{OnlyDeleted: true, IncludeDeleted: true})
+			g.Assert(str).Equal("WHERE series.deleted_at IS NOT NULL")
+		})
+	})
+
+	g.Describe("Series model & generated store", func() {
+		g.Before(func() {
+			var e error
+			db, e = loadDB(dbFile)
+			g.Assert(e).Equal(nil)
+
+			statements := []string{
+				"insert into series (system_id,title) values(1,'first series');",
+				"insert into series (system_id,title) values(2,'second series');",
+				"insert into series (system_id,title) values(3,'third series');",
+			}
+
+			for _, statement := range statements {
+				_, e := db.Exec(statement)
+				g.Assert(e).Equal(nil)
+			}
+		})
+
+		g.BeforeEach(func() {
+			queryLog = new(bytes.Buffer)
+			store = NewSeriesStore(db, queryLog)
+		})
+
+		g.After(func() {
+			e := db.Close()
+			g.Assert(e).Equal(nil)
+			os.Remove(dbFile)
+		})
+
+		g.It("verifies the soft delete column of the series table", func() {
+			g.Assert(VerifySeriesSchema(db)).Equal(nil)
+		})
+
+		g.It("returns an error when deleting with an empty blueprint", func() {
+			_, e := store.DeleteSeries(&SeriesBlueprint{})
+			g.Assert(e == nil).Equal(false)
+		})
+
+		g.It("marks the series matched by the blueprint as deleted at the time read from the store's clock", func() {
+			now := time.Date(2000, time.March, 4, 5, 6, 7, 0, time.UTC)
+			clocked := store.WithClock(func() time.Time { return now })
+
+			count, e := clocked.DeleteSeries(&SeriesBlueprint{ID: []int{1, 2}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(2))
+
+			var deleted sql.NullTime
+			e = db.QueryRow("select deleted_at from series where system_id = 1").Scan(&deleted)
+			g.Assert(e).Equal(nil)
+			g.Assert(deleted.Valid).Equal(true)
+			g.Assert(deleted.Time.Equal(now)).Equal(true)
+		})
+
+		g.It("excludes deleted series from finds, counts and selects", func() {
+			series, _, e := store.FindSeries(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(series)).Equal(1)
+			g.Assert(series[0].ID).Equal(3)
+
+			count, e := store.CountSeries(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(1)
+
+			titles, e := store.SelectSeriesTitles(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(titles).Equal([]string{"third series"})
+		})
+
+		g.It("includes deleted series when requested by the blueprint", func() {
+			count, e := store.CountSeries(&SeriesBlueprint{IncludeDeleted: true})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(3)
+		})
+
+		g.It("finds only the deleted series when requested by the blueprint", func() {
+			ids, e := store.SelectSeriesIDs(&SeriesBlueprint{OnlyDeleted: true})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{1, 2})
+		})
+
+		g.It("restores the deleted series matched by the blueprint", func() {
+			count, e := store.RestoreSeries(&SeriesBlueprint{ID: []int{2, 3}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			ids, e := store.SelectSeriesIDs(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{2, 3})
+		})
+
+		g.It("stamps the creation and update times of created series with the store's clock", func() {
+			now := time.Date(2001, time.March, 4, 5, 6, 7, 0, time.UTC)
+			clocked := store.WithClock(func() time.Time { return now })
+
+			id, e := clocked.CreateSeries(Series{Title: "fourth series"})
+			g.Assert(e).Equal(nil)
+
+			series, e := clocked.GetSeries(int(id))
+			g.Assert(e).Equal(nil)
+			g.Assert(series.CreatedAt.Equal(now)).Equal(true)
+			g.Assert(series.UpdatedAt.Equal(now)).Equal(true)
+		})
+
+		g.It("stamps the update time of updated series in the same statement", func() {
+			created := time.Date(2001, time.March, 4, 5, 6, 7, 0, time.UTC)
+			now := created.Add(time.Hour)
+			clocked := store.WithClock(func() time.Time { return now })
+
+			blueprint := &SeriesBlueprint{Title: []string{"fourth series"}}
+			count, e := clocked.UpdateSeriesTitle("fourth series, revised", 0, blueprint)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			series, _, e := clocked.FindSeries(&SeriesBlueprint{Title: []string{"fourth series, revised"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(len(series)).Equal(1)
+			g.Assert(series[0].CreatedAt.Equal(created)).Equal(true)
+			g.Assert(series[0].UpdatedAt.Equal(now)).Equal(true)
+		})
+
+		g.It("stamps the update time of saved series on the record", func() {
+			now := time.Date(2002, time.March, 4, 5, 6, 7, 0, time.UTC)
+			series, _, e := store.FindSeries(&SeriesBlueprint{Title: []string{"fourth series, revised"}})
+			g.Assert(e).Equal(nil)
+
+			_, e = store.WithClock(func() time.Time { return now }).SaveSeries(series[0])
+			g.Assert(e).Equal(nil)
+			g.Assert(series[0].UpdatedAt.Equal(now)).Equal(true)
+		})
+
+		g.It("increments the version of updated series", func() {
+			series, _, e := store.FindSeries(&SeriesBlueprint{Title: []string{"fourth series, revised"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(len(series)).Equal(1)
+			g.Assert(series[0].Version).Equal(2)
+		})
+
+		g.It("returns a version conflict error when updating from an out of date version", func() {
+			count, e := store.UpdateSeriesTitle("stale title", 1, &SeriesBlueprint{Title: []string{"fourth series, revised"}})
+			g.Assert(count).Equal(int64(-1))
+			conflict, ok := e.(*SeriesVersionConflictError)
+			g.Assert(ok).Equal(true)
+			g.Assert(conflict.Version).Equal(1)
+		})
+
+		g.It("returns a version conflict error when the update matches no series", func() {
+			count, e := store.UpdateSeriesTitle("missing", 2, &SeriesBlueprint{Title: []string{"missing series"}})
+			g.Assert(count).Equal(int64(-1))
+			_, ok := e.(*SeriesVersionConflictError)
+			g.Assert(ok).Equal(true)
+		})
+
+		g.It("does not update the version of series directly", func() {
+			_, updated := reflect.TypeOf(store).MethodByName("UpdateSeriesVersion")
+			g.Assert(updated).Equal(false)
+		})
+
+		g.It("returns a version conflict error when saving an out of date series", func() {
+			series, _, e := store.FindSeries(&SeriesBlueprint{Title: []string{"fourth series, revised"}})
+			g.Assert(e).Equal(nil)
+
+			stale := *series[0]
+			_, e = store.SaveSeries(series[0])
+			g.Assert(e).Equal(nil)
+			g.Assert(series[0].Version).Equal(stale.Version + 1)
+
+			_, e = store.SaveSeries(&stale)
+			_, ok := e.(*SeriesVersionConflictError)
+			g.Assert(ok).Equal(true)
+		})
+
+		g.It("refuses to restore or purge series without a blueprint limiting the series affected", func() {
+			_, e := store.RestoreSeries(nil)
+			g.Assert(e == nil).Equal(false)
+
+			_, e = store.RestoreSeries(&SeriesBlueprint{})
+			g.Assert(e == nil).Equal(false)
+
+			_, e = store.PurgeSeries(&SeriesBlueprint{OnlyDeleted: true})
+			g.Assert(e == nil).Equal(false)
+		})
+
+		g.It("purges the deleted series, leaving the others in place", func() {
+			count, e := store.PurgeSeries(&SeriesBlueprint{ID: []int{1, 2}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			total, e := store.CountSeries(&SeriesBlueprint{IncludeDeleted: true})
+			g.Assert(e).Equal(nil)
+			g.Assert(total).Equal(3)
+		})
+
+		g.It("normalizes the titles of created series with their creation hook", func() {
+			id, e := store.CreateSeries(Series{Title: "  fifth series  "})
+			g.Assert(e).Equal(nil)
+
+			series, e := store.GetSeries(int(id))
+			g.Assert(e).Equal(nil)
+			g.Assert(series.Title).Equal("fifth series")
+		})
+
+		g.It("aborts the creation of series when their creation hook fails", func() {
+			_, e := store.CreateSeries(Series{Title: "sixth series"}, Series{Title: " "})
+			g.Assert(e == nil).Equal(false)
+
+			count, e := store.CountSeries(&SeriesBlueprint{Title: []string{"sixth series"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(0)
+		})
+
+		g.It("aborts saving series when their update hook fails", func() {
+			series, e := store.GetSeries(3)
+			g.Assert(e).Equal(nil)
+
+			series.Title = ""
+			_, e = store.SaveSeries(series)
+			g.Assert(e == nil).Equal(false)
+
+			series, e = store.GetSeries(3)
+			g.Assert(e).Equal(nil)
+			g.Assert(series.Title).Equal("third series")
+		})
+
+		g.It("calls the find hook of every series found or iterated", func() {
+			series, _, e := store.FindSeries(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(len(series) > 0).Equal(true)
+
+			for _, s := range series {
+				g.Assert(s.CreatedAt.Location()).Equal(time.UTC)
+			}
+
+			cursor, e := store.IterateSeries(nil)
+			g.Assert(e).Equal(nil)
+			defer cursor.Close()
+
+			for cursor.Next() {
+				g.Assert(cursor.Record().UpdatedAt.Location()).Equal(time.UTC)
+			}
+
+			g.Assert(cursor.Err()).Equal(nil)
+		})
+
+		g.It("aborts deletions when the deletion hook of any matched series fails", func() {
+			_, e := store.CreateSeries(Series{Title: "pinned series"})
+			g.Assert(e).Equal(nil)
+
+			blueprint := &SeriesBlueprint{Title: []string{"fifth series", "pinned series"}}
+			_, e = store.DeleteSeries(blueprint)
+			g.Assert(e == nil).Equal(false)
+
+			count, e := store.CountSeries(blueprint)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(2)
+		})
+
+		g.It("calls the deletion hook of every matched series regardless of the blueprint limit", func() {
+			blueprint := &SeriesBlueprint{Title: []string{"fifth series", "pinned series"}, Limit: 1}
+			_, e := store.DeleteSeries(blueprint)
+			g.Assert(e == nil).Equal(false)
+
+			count, e := store.CountSeries(&SeriesBlueprint{Title: blueprint.Title})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(2)
+		})
+
+		g.It("aborts updates when the update hook of any matched series fails", func() {
+			blueprint := &SeriesBlueprint{Title: []string{"fifth series", "pinned series"}, Limit: 1}
+			pinned, e := store.SelectSeriesIDs(&SeriesBlueprint{Title: []string{"pinned series"}})
+			g.Assert(e).Equal(nil)
+
+			_, e = store.UpdateSeriesSubtitle(&sql.NullString{String: "renamed", Valid: true}, 0, blueprint)
+			g.Assert(e.Error()).Equal(fmt.Sprintf("pinned series %d cannot be updated", pinned[0]))
+
+			count, e := store.CountSeries(&SeriesBlueprint{Subtitle: []sql.NullString{{String: "renamed", Valid: true}}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(0)
+		})
+	})
+
+	g.Describe("Series nullable lookups", func() {
+		nullableFile := "./series-nullable-testing.db"
+		completed := time.Date(2010, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+		g.Before(func() {
+			var e error
+			db, e = loadDB(nullableFile)
+			g.Assert(e).Equal(nil)
+			store = NewSeriesStore(db, new(bytes.Buffer))
+
+			_, e = store.CreateSeries(
+				Series{Title: "first", Subtitle: sql.NullString{String: "a trilogy", Valid: true}},
+				Series{Title: "second", CompletedAt: sql.NullTime{Time: completed, Valid: true}},
+				Series{Title: "third"},
+			)
+			g.Assert(e).Equal(nil)
+		})
+
+		g.After(func() {
+			e := db.Close()
+			g.Assert(e).Equal(nil)
+			os.Remove(nullableFile)
+		})
+
+		g.It("matches nullable strings by the LIKE patterns of the blueprint", func() {
+			titles, e := store.SelectSeriesTitles(&SeriesBlueprint{SubtitleLike: []string{"%trilogy"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(titles).Equal([]string{"first"})
+		})
+
+		g.It("matches nullable times by the range of the blueprint", func() {
+			bounds := []time.Time{completed.Add(-time.Hour), completed.Add(time.Hour)}
+			titles, e := store.SelectSeriesTitles(&SeriesBlueprint{CompletedAtRange: bounds})
+			g.Assert(e).Equal(nil)
+			g.Assert(titles).Equal([]string{"second"})
+		})
+
+		g.It("matches series with any subtitle when the lookup is empty", func() {
+			count, e := store.CountSeries(&SeriesBlueprint{Subtitle: []sql.NullString{}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(1)
+		})
+
+		g.It("updates nullable columns to NULL when given a nil value", func() {
+			count, e := store.UpdateSeriesCompletedAt(nil, 0, &SeriesBlueprint{Title: []string{"second"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			count, e = store.UpdateSeriesSubtitle(&sql.NullString{String: "a duology", Valid: true}, 0, &SeriesBlueprint{
+				Title: []string{"third"},
+			})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			completions, e := store.SelectSeriesCompletedAts(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(completions).Equal([]sql.NullTime{{}, {}, {}})
+
+			titles, e := store.SelectSeriesTitles(&SeriesBlueprint{SubtitleLike: []string{"%logy"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(titles).Equal([]string{"first", "third"})
+		})
+	})
+
+	g.Describe("Series in-memory store", func() {
+		var fake SeriesStore
+
+		g.BeforeEach(func() {
+			fake = NewFakeSeriesStore(Series{Title: "first series"}, Series{Title: "pinned series"})
+		})
+
+		g.It("stamps and versions the series created and saved", func() {
+			now := time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+			clocked := fake.WithClock(func() time.Time { return now })
+
+			id, e := clocked.CreateSeries(Series{Title: " third series "})
+			g.Assert(e).Equal(nil)
+			g.Assert(id).Equal(int64(3))
+
+			series, e := clocked.GetSeries(3)
+			g.Assert(e).Equal(nil)
+			g.Assert(series.Title).Equal("third series")
+			g.Assert(series.CreatedAt.Equal(now)).Equal(true)
+
+			_, e = clocked.SaveSeries(series)
+			g.Assert(e).Equal(nil)
+			g.Assert(series.Version).Equal(1)
+
+			series.Version = 0
+			_, e = clocked.SaveSeries(series)
+			_, ok := e.(*SeriesVersionConflictError)
+			g.Assert(ok).Equal(true)
+		})
+
+		g.It("keeps deleted series until they are purged", func() {
+			count, e := fake.DeleteSeries(&SeriesBlueprint{ID: []int{1}})
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(int64(1))
+
+			remaining, e := fake.CountSeries(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(remaining).Equal(1)
+
+			deleted, e := fake.CountSeries(&SeriesBlueprint{OnlyDeleted: true})
+			g.Assert(e).Equal(nil)
+			g.Assert(deleted).Equal(1)
+
+			purged, e := fake.PurgeSeries(&SeriesBlueprint{ID: []int{1}})
+			g.Assert(e).Equal(nil)
+			g.Assert(purged).Equal(int64(1))
+
+			total, e := fake.CountSeries(&SeriesBlueprint{IncludeDeleted: true})
+			g.Assert(e).Equal(nil)
+			g.Assert(total).Equal(1)
+		})
+
+		g.It("matches nullable values by their lookups, never matching series without one", func() {
+			completed := time.Date(2010, time.June, 1, 0, 0, 0, 0, time.UTC)
+			_, e := fake.UpdateSeriesCompletedAt(&sql.NullTime{Time: completed, Valid: true}, 0, &SeriesBlueprint{
+				ID: []int{1},
+			})
+			g.Assert(e).Equal(nil)
+
+			bounds := []time.Time{completed.Add(-time.Hour), completed.Add(time.Hour)}
+			ids, e := fake.SelectSeriesIDs(&SeriesBlueprint{CompletedAtRange: bounds})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{1})
+
+			ids, e = fake.SelectSeriesIDs(&SeriesBlueprint{SubtitleLike: []string{"%"}})
+			g.Assert(e).Equal(nil)
+			g.Assert(len(ids)).Equal(0)
+
+			ids, e = fake.SelectSeriesIDs(&SeriesBlueprint{CompletedAt: []sql.NullTime{{}}})
+			g.Assert(e).Equal(nil)
+			g.Assert(ids).Equal([]int{2})
+		})
+
+		g.It("aborts updates when the update hook of any matched series fails", func() {
+			_, e := fake.UpdateSeriesSubtitle(&sql.NullString{String: "renamed", Valid: true}, 0, &SeriesBlueprint{
+				ID: []int{1, 2},
+			})
+			g.Assert(e.Error()).Equal("pinned series 2 cannot be updated")
+
+			series, e := fake.GetSeries(1)
+			g.Assert(e).Equal(nil)
+			g.Assert(series.Subtitle.Valid).Equal(false)
+		})
+
+		g.It("aborts deletions when the deletion hook of any matched series fails", func() {
+			_, e := fake.DeleteSeries(&SeriesBlueprint{ID: []int{1, 2}})
+			g.Assert(e == nil).Equal(false)
+
+			count, e := fake.CountSeries(nil)
+			g.Assert(e).Equal(nil)
+			g.Assert(count).Equal(2)
+		})
+	})
+}
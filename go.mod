@@ -1,5 +1,7 @@
 module github.com/dadleyy/marlow
 
+go 1.27.1
+
 require (
 	github.com/VividCortex/ewma v0.0.0-20170804035156-43880d236f69
 	github.com/dustin/go-humanize v0.0.0-20171111073723-bb3d318650d4
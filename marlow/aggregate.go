@@ -0,0 +1,168 @@
+package marlow
+
+import "io"
+import "fmt"
+import "net/url"
+import "go/types"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type aggregateSymbols struct {
+	blueprint       string
+	queryString     string
+	statementResult string
+	statementError  string
+	result          string
+	scanError       string
+}
+
+// aggregateType returns the nullable type that the sum, minimum and maximum of the field are scanned into, along with
+// whether or not the field holds values that can be aggregated. Averages are always scanned into an sql.NullFloat64.
+func aggregateType(fieldType string) (string, bool) {
+	// Nullable fields are aggregated according to the type of the value they hold.
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		fieldType = nullable.underlying
+	}
+
+	// Custom numeric types (e.g time.Time) support range lookups but are not summed or averaged.
+	for _, t := range constants.NumericCustomTypes {
+		if t == fieldType {
+			return "", false
+		}
+	}
+
+	typeInfo := getTypeInfo(fieldType)
+
+	if typeInfo&types.IsInteger != 0 {
+		return "sql.NullInt64", true
+	}
+
+	if typeInfo&types.IsFloat != 0 {
+		return "sql.NullFloat64", true
+	}
+
+	return "", false
+}
+
+// identifierField returns true if the field holds the record's primary key or the primary key of a referenced record.
+func identifierField(record marlowRecord, fieldConfig url.Values) bool {
+	if fieldConfig.Get(constants.ReferencesConfigOption) != "" {
+		return true
+	}
+
+	column := fieldConfig.Get(constants.ColumnConfigOption)
+	return column != "" && column == record.primaryKeyColumn()
+}
+
+// aggregator returns a generator that writes the Sum, Avg, Min and Max store methods for a numeric field, each of which
+// applies the sql aggregate function of the same name to the field's column over the records matched by a blueprint.
+// The result is scanned into a nullable type that is not valid when no record matched or every value was NULL. The
+// primary key and fields referencing other records hold identifiers, which are not aggregated.
+func aggregator(record marlowRecord, fieldName string, fieldConfig url.Values) io.Reader {
+	pr, pw := io.Pipe()
+	resultType, ok := aggregateType(fieldConfig.Get("type"))
+
+	if ok != true || identifierField(record, fieldConfig) {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	symbols := aggregateSymbols{
+		blueprint:       "_blueprint",
+		queryString:     "_raw",
+		statementResult: "_statement",
+		statementError:  "_se",
+		result:          "_result",
+		scanError:       "_scanError",
+	}
+
+	aggregates := []struct {
+		prefix     string
+		function   string
+		resultType string
+	}{
+		{"Sum", "SUM", resultType},
+		{"Avg", "AVG", "sql.NullFloat64"},
+		{"Min", "MIN", resultType},
+		{"Max", "MAX", resultType},
+	}
+
+	columnReference := record.columnReference(fieldConfig.Get(constants.ColumnConfigOption))
+	table := record.quote(record.table())
+
+	params := []writing.FuncParam{
+		{Symbol: symbols.blueprint, Type: fmt.Sprintf("*%s", record.blueprint())},
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		for _, aggregate := range aggregates {
+			methodName := fmt.Sprintf("%s%s%s", aggregate.prefix, record.name(), fieldName)
+			returns := []string{aggregate.resultType, "error"}
+			zero := fmt.Sprintf("%s{}", aggregate.resultType)
+			query := fmt.Sprintf("SELECT %s(%s) FROM %s %%s;", aggregate.function, columnReference, table)
+
+			gosrc.Comment("[marlow feature]: %s aggregate of %s on table[%s]", aggregate.function, fieldName, table)
+
+			method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+			e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+				receiver := scope.Get("receiver")
+				logwriter := logWriter{output: gosrc, receiver: receiver}
+
+				gosrc.WithIf("%s == nil", func(url.Values) error {
+					return gosrc.Println("%s = &%s{}", symbols.blueprint, record.blueprint())
+				}, symbols.blueprint)
+
+				writeCursorCheck(gosrc, record, symbols.blueprint, zero)
+
+				gosrc.Println("%s := fmt.Sprintf(%q, %s)", symbols.queryString, query, symbols.blueprint)
+
+				logwriter.AddLog(symbols.queryString, fmt.Sprintf("%s.Values()", symbols.blueprint))
+
+				gosrc.Println(
+					"%s, %s := %s.PrepareContext(%s, %s)",
+					symbols.statementResult,
+					symbols.statementError,
+					receiver,
+					contextSymbol,
+					symbols.queryString,
+				)
+
+				gosrc.WithIf("%s != nil", func(url.Values) error {
+					return gosrc.Returns(zero, symbols.statementError)
+				}, symbols.statementError)
+
+				gosrc.Println("defer %s.Close()", symbols.statementResult)
+
+				gosrc.Println("var %s %s", symbols.result, aggregate.resultType)
+
+				gosrc.Println(
+					"%s := %s.QueryRowContext(%s, %s.Values()...).Scan(&%s)",
+					symbols.scanError,
+					symbols.statementResult,
+					contextSymbol,
+					symbols.blueprint,
+					symbols.result,
+				)
+
+				gosrc.WithIf("%s != nil", func(url.Values) error {
+					return gosrc.Returns(zero, symbols.scanError)
+				}, symbols.scanError)
+
+				return gosrc.Returns(symbols.result, writing.Nil)
+			})
+
+			if e != nil {
+				pw.CloseWithError(e)
+				return
+			}
+		}
+
+		record.registerImports("fmt", "database/sql")
+		pw.Close()
+	}()
+
+	return pr
+}
@@ -0,0 +1,218 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sync"
+import "bytes"
+import "strings"
+import "testing"
+import "net/url"
+import "go/token"
+import "go/parser"
+import "github.com/franela/goblin"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type aggregateTestScaffold struct {
+	buffer *bytes.Buffer
+
+	imports chan string
+	methods chan writing.FuncDecl
+
+	record url.Values
+	fields map[string]url.Values
+
+	received   map[string]bool
+	registered map[string]bool
+	closed     bool
+	wg         *sync.WaitGroup
+}
+
+func (s *aggregateTestScaffold) close() {
+	if s == nil || s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.imports)
+	close(s.methods)
+	s.wg.Wait()
+}
+
+func (s *aggregateTestScaffold) g(name string) io.Reader {
+	record := marlowRecord{
+		fields:        s.fields,
+		config:        s.record,
+		importChannel: s.imports,
+		storeChannel:  s.methods,
+	}
+
+	return aggregator(record, name, s.fields[name])
+}
+
+func Test_Aggregate(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	var scaffold *aggregateTestScaffold
+
+	g.Describe("aggregate feature generator test suite", func() {
+		g.BeforeEach(func() {
+			scaffold = &aggregateTestScaffold{
+				buffer:     new(bytes.Buffer),
+				wg:         &sync.WaitGroup{},
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				record:     make(url.Values),
+				fields:     make(map[string]url.Values),
+				received:   make(map[string]bool),
+				registered: make(map[string]bool),
+			}
+
+			scaffold.wg.Add(2)
+
+			go func() {
+				for method := range scaffold.methods {
+					scaffold.registered[method.Name] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			go func() {
+				for i := range scaffold.imports {
+					scaffold.received[i] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			scaffold.record.Set(constants.RecordNameConfigOption, "Author")
+			scaffold.record.Set(constants.TableNameConfigOption, "authors")
+			scaffold.record.Set(constants.StoreNameConfigOption, "AuthorStore")
+			scaffold.record.Set(constants.BlueprintNameConfigOption, "AuthorBlueprint")
+
+			scaffold.fields["Rating"] = url.Values{
+				"type":                       []string{"float64"},
+				constants.ColumnConfigOption: []string{"rating"},
+			}
+
+			scaffold.fields["Age"] = url.Values{
+				"type":                       []string{"uint8"},
+				constants.ColumnConfigOption: []string{"age"},
+			}
+
+			scaffold.fields["UniversityID"] = url.Values{
+				"type":                       []string{"sql.NullInt64"},
+				constants.ColumnConfigOption: []string{"university_id"},
+			}
+
+			scaffold.fields["Name"] = url.Values{
+				"type":                       []string{"string"},
+				constants.ColumnConfigOption: []string{"name"},
+			}
+
+			scaffold.fields["Birthday"] = url.Values{
+				"type":                       []string{"time.Time"},
+				constants.ColumnConfigOption: []string{"birthday"},
+			}
+		})
+
+		g.AfterEach(func() {
+			scaffold.close()
+		})
+
+		g.It("generates valid golang", func() {
+			fmt.Fprintln(scaffold.buffer, "package marlowt")
+			_, e := io.Copy(scaffold.buffer, scaffold.g("Rating"))
+			g.Assert(e).Equal(nil)
+			_, e = parser.ParseFile(token.NewFileSet(), "", scaffold.buffer, parser.AllErrors)
+			g.Assert(e).Equal(nil)
+		})
+
+		g.It("registers every aggregate and its context-aware variant with the store", func() {
+			io.Copy(scaffold.buffer, scaffold.g("Rating"))
+			scaffold.close()
+
+			for _, name := range []string{"SumAuthorRating", "AvgAuthorRating", "MinAuthorRating", "MaxAuthorRating"} {
+				g.Assert(scaffold.registered[name]).Equal(true)
+				g.Assert(scaffold.registered[name+"Context"]).Equal(true)
+			}
+
+			g.Assert(scaffold.received["database/sql"]).Equal(true)
+		})
+
+		g.It("applies the aggregate function to the column with the blueprint clauses", func() {
+			io.Copy(scaffold.buffer, scaffold.g("Age"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "_raw := fmt.Sprintf(\"SELECT SUM(authors.age) FROM authors %s;\", _blueprint)")).Equal(true)
+			g.Assert(strings.Contains(output, "\"SELECT MAX(authors.age) FROM authors %s;\"")).Equal(true)
+		})
+
+		g.It("returns nullable integers for integer fields and nullable floats for averages", func() {
+			io.Copy(scaffold.buffer, scaffold.g("Age"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "SumAuthorAgeContext(_ctx context.Context,_blueprint *AuthorBlueprint) (sql.NullInt64,error)")).Equal(true)
+			g.Assert(strings.Contains(output, "MinAuthorAgeContext(_ctx context.Context,_blueprint *AuthorBlueprint) (sql.NullInt64,error)")).Equal(true)
+			g.Assert(strings.Contains(output, "AvgAuthorAgeContext(_ctx context.Context,_blueprint *AuthorBlueprint) (sql.NullFloat64,error)")).Equal(true)
+		})
+
+		g.It("returns nullable floats for float fields", func() {
+			io.Copy(scaffold.buffer, scaffold.g("Rating"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "MaxAuthorRatingContext(_ctx context.Context,_blueprint *AuthorBlueprint) (sql.NullFloat64,error)")).Equal(true)
+		})
+
+		g.It("supports nullable numeric fields", func() {
+			io.Copy(scaffold.buffer, scaffold.g("UniversityID"))
+			scaffold.close()
+			g.Assert(scaffold.registered["SumAuthorUniversityID"]).Equal(true)
+		})
+
+		g.It("aggregates every nullable integer type into nullable 64 bit integers", func() {
+			for _, nullable := range []string{"sql.NullInt32", "sql.NullInt16", "sql.NullByte"} {
+				aggregate, ok := aggregateType(nullable)
+				g.Assert(ok).Equal(true)
+				g.Assert(aggregate).Equal("sql.NullInt64")
+			}
+
+			scaffold.fields["Rank"] = url.Values{
+				"type":                       []string{"sql.NullInt32"},
+				constants.ColumnConfigOption: []string{"rank"},
+			}
+
+			io.Copy(scaffold.buffer, scaffold.g("Rank"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "SumAuthorRankContext(_ctx context.Context,_blueprint *AuthorBlueprint) (sql.NullInt64,error)")).Equal(true)
+			g.Assert(strings.Contains(output, "AvgAuthorRankContext(_ctx context.Context,_blueprint *AuthorBlueprint) (sql.NullFloat64,error)")).Equal(true)
+		})
+
+		g.It("does not aggregate nullable strings, booleans or times", func() {
+			for _, nullable := range []string{"sql.NullString", "sql.NullBool", "sql.NullTime"} {
+				_, ok := aggregateType(nullable)
+				g.Assert(ok).Equal(false)
+			}
+		})
+
+		g.It("does not generate aggregates for string or time fields", func() {
+			io.Copy(scaffold.buffer, scaffold.g("Name"))
+			io.Copy(scaffold.buffer, scaffold.g("Birthday"))
+			scaffold.close()
+			g.Assert(scaffold.buffer.Len()).Equal(0)
+			g.Assert(len(scaffold.registered)).Equal(0)
+		})
+
+		g.It("does not generate aggregates for the primary key or fields referencing other records", func() {
+			scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "age")
+			scaffold.fields["UniversityID"].Set(constants.ReferencesConfigOption, "University")
+			io.Copy(scaffold.buffer, scaffold.g("Age"))
+			io.Copy(scaffold.buffer, scaffold.g("UniversityID"))
+			scaffold.close()
+			g.Assert(scaffold.buffer.Len()).Equal(0)
+			g.Assert(len(scaffold.registered)).Equal(0)
+		})
+
+		g.It("quotes the column and table for mysql records", func() {
+			scaffold.record.Set(constants.DialectConfigOption, "mysql")
+			io.Copy(scaffold.buffer, scaffold.g("Age"))
+			g.Assert(strings.Contains(scaffold.buffer.String(), "\"SELECT AVG(`authors`.`age`) FROM `authors` %s;\"")).Equal(true)
+		})
+	})
+}
@@ -4,15 +4,64 @@ import "io"
 import "fmt"
 import "sync"
 import "strings"
+import "strconv"
 import "net/url"
 import "go/types"
 import "github.com/dadleyy/marlow/marlow/writing"
 import "github.com/dadleyy/marlow/marlow/constants"
 
-func writeBlueprint(destination io.Writer, record marlowRecord) error {
-	out := writing.NewGoWriter(destination)
+const (
+	// blueprintOrderMethod is the name of the generated blueprint method that produces the ORDER BY clause for lookups.
+	blueprintOrderMethod = "orderByString"
+
+	// blueprintOrderKeysMethod is the name of the generated blueprint method that produces the validated order keys.
+	blueprintOrderKeysMethod = "orderKeys"
+
+	// blueprintClauseMethod is the name of the generated blueprint method that produces the lookup condition and values.
+	blueprintClauseMethod = "clause"
+
+	// blueprintConditionMethod is the name of the generated blueprint method that produces the condition of the fields
+	// and groups of the blueprint, without the clauses limiting every lookup.
+	blueprintConditionMethod = "conditionString"
+
+	// blueprintGroupMethod is the name of the generated blueprint method that joins the conditions of a group.
+	blueprintGroupMethod = "groupString"
+
+	// blueprintAndField is the blueprint field holding the group of blueprints whose conditions must all be met.
+	blueprintAndField = "And"
+
+	// blueprintOrField is the blueprint field holding the group of blueprints of which any condition must be met.
+	blueprintOrField = "Or"
+
+	// blueprintOffsetMethod is the name of the generated blueprint method returning the amount of records lookups skip.
+	blueprintOffsetMethod = "offset"
+
+	// blueprintCursorField is the blueprint field holding the opaque token of the lookup page to continue from.
+	blueprintCursorField = "Cursor"
+
+	// blueprintKeysetMethod is the name of the generated blueprint method that produces the keyset clause.
+	blueprintKeysetMethod = "keysetString"
 
-	e := out.WithStruct(record.blueprint(), func(url.Values) error {
+	// blueprintCursorKeysMethod is the name of the generated blueprint method that decodes the cursor token.
+	blueprintCursorKeysMethod = "cursorKeys"
+
+	// blueprintNextCursorMethod is the name of the generated blueprint method that encodes the cursor token of a record.
+	blueprintNextCursorMethod = "nextCursor"
+
+	// blueprintIncludeDeletedField is the blueprint field that includes soft deleted records in lookups.
+	blueprintIncludeDeletedField = "IncludeDeleted"
+
+	// blueprintOnlyDeletedField is the blueprint field that limits lookups to soft deleted records.
+	blueprintOnlyDeletedField = "OnlyDeleted"
+
+	// blueprintDeletedMethod is the name of the generated blueprint method that produces the soft deletion clause.
+	blueprintDeletedMethod = "deletedString"
+)
+
+// writeBlueprintStruct writes the blueprint type, holding the lookup values of every field along with the filters of
+// the records linked to the record through join records.
+func writeBlueprintStruct(out writing.GoWriter, record marlowRecord) error {
+	return out.WithStruct(record.blueprint(), func(url.Values) error {
 		for name, config := range record.fields {
 			fieldType := config.Get("type")
 
@@ -20,16 +69,37 @@ func writeBlueprint(destination io.Writer, record marlowRecord) error {
 				return fmt.Errorf("bad field type for field name: %s", name)
 			}
 
-			typeInfo := getTypeInfo(fieldType)
+			// Nullable fields are looked up by range and LIKE on the type of the value they hold.
+			rangeType := fieldType
+
+			nullable, isNullable := lookupNullableType(fieldType)
+
+			if isNullable {
+				rangeType = nullable.underlying
+				out.Println("%s%s *bool", name, record.config.Get(constants.BlueprintNullFieldSuffixConfigOption))
+			}
+
+			if rangeType == "time.Time" {
+				record.registerImports("time")
+			}
+
+			typeInfo := getTypeInfo(rangeType)
 
 			// Support IN lookup on string fields.
 			if typeInfo&types.IsNumeric != 0 {
-				out.Println("%s%s []%s", name, record.config.Get(constants.BlueprintRangeFieldSuffixConfigOption), fieldType)
+				out.Println("%s%s []%s", name, record.config.Get(constants.BlueprintRangeFieldSuffixConfigOption), rangeType)
+				out.Println("%s%s []%s", name, record.config.Get(constants.BlueprintNotRangeFieldSuffixConfigOption), rangeType)
 			}
 
 			// Support LIKE lookup on string fields.
 			if typeInfo&types.IsString != 0 {
 				out.Println("%s%s []string", name, record.config.Get(constants.BlueprintLikeFieldSuffixConfigOption))
+				out.Println("%s%s []string", name, record.config.Get(constants.BlueprintNotLikeFieldSuffixConfigOption))
+			}
+
+			// Support NOT IN lookup on the fields supporting IN lookups by value, nullable fields included.
+			if isNullable || typeInfo&types.IsConstType != 0 {
+				out.Println("%s%s []%s", name, record.config.Get(constants.BlueprintNotInFieldSuffixConfigOption), fieldType)
 			}
 
 			if fieldImport := config.Get("import"); fieldImport != "" {
@@ -39,6 +109,24 @@ func writeBlueprint(destination io.Writer, record marlowRecord) error {
 			out.Println("%s []%s", name, fieldType)
 		}
 
+		for _, link := range record.links() {
+			out.Println("%s *%s", link.filterField(), link.other.blueprint())
+		}
+
+		if _, _, ok := record.primaryKeyField(); ok {
+			out.Comment("%s continues from the record a page ended with, limiting every use of the", blueprintCursorField)
+			out.Comment("blueprint to the records that follow it. Lookups continuing from a cursor ignore the Offset.")
+			out.Println("%s string", blueprintCursorField)
+		}
+
+		if record.softDeleteColumn() != "" {
+			out.Println("%s bool", blueprintIncludeDeletedField)
+			out.Println("%s bool", blueprintOnlyDeletedField)
+		}
+
+		writeGroupComment(out, record)
+		out.Println("%s []*%s", blueprintAndField, record.blueprint())
+		out.Println("%s []*%s", blueprintOrField, record.blueprint())
 		out.Println("Inclusive bool")
 		out.Println("Limit int")
 		out.Println("Offset int")
@@ -47,8 +135,12 @@ func writeBlueprint(destination io.Writer, record marlowRecord) error {
 
 		return nil
 	})
+}
 
-	if e != nil {
+func writeBlueprint(destination io.Writer, record marlowRecord) error {
+	out := writing.NewGoWriter(destination)
+
+	if e := writeBlueprintStruct(out, record); e != nil {
 		return e
 	}
 
@@ -80,6 +172,10 @@ func writeBlueprint(destination io.Writer, record marlowRecord) error {
 		readers = append(readers, fieldGenerators...)
 	}
 
+	for _, link := range record.links() {
+		readers = append(readers, linkFilter(record, link, methodReceiver))
+	}
+
 	if _, e := io.Copy(destination, io.MultiReader(readers...)); e != nil {
 		return e
 	}
@@ -87,82 +183,585 @@ func writeBlueprint(destination io.Writer, record marlowRecord) error {
 	close(methodReceiver)
 	wg.Wait()
 
-	symbols := struct {
-		clauseMap   string
-		clauseSlice string
-		clauseItem  string
-		valueCount  string
-		values      string
-	}{"_map", "_clauses", "_item", "_count", "_values"}
+	if e := writeCursorMethods(out, record); e != nil {
+		return e
+	}
+
+	if e := writeDeletedMethod(out, record); e != nil {
+		return e
+	}
+
+	if e := writeConditionMethod(out, record, clauseMethods); e != nil {
+		return e
+	}
+
+	if e := writeGroupMethod(out, record); e != nil {
+		return e
+	}
+
+	if e := writeClauseMethod(out, record); e != nil {
+		return e
+	}
+
+	if e := writeStringMethod(out, record); e != nil {
+		return e
+	}
+
+	if e := writeValuesMethod(out, record); e != nil {
+		return e
+	}
+
+	if e := writeOffsetMethod(out, record); e != nil {
+		return e
+	}
+
+	return writeOrderMethod(out, record)
+}
+
+// writeConditionMethod generates the blueprint method joining the non-empty clauses of every field, along with the
+// clauses of the blueprint's groups, into a single condition. Unlike the clause method, the condition holds none of
+// the clauses limiting every lookup, allowing it to be nested within the condition of another blueprint.
+func writeConditionMethod(out writing.GoWriter, record marlowRecord, clauseMethods []string) error {
+	symbols := clauseSymbols{
+		clauseMap:   "_map",
+		clauseSlice: "_clauses",
+		clauseItem:  "_item",
+		valueCount:  "_count",
+		values:      "_values",
+		clauseValue: "_itemValues",
+	}
+
+	params := []writing.FuncParam{{Symbol: symbols.valueCount, Type: "int"}}
+	returns := []string{"string", "[]interface{}"}
 
 	// With all of our fields having generated non-exported clause generation methods on our struct, we can create the
-	// final 'String' method which iterates over all of these, calling them and adding the non-empty string clauses to
-	// a list, which eventually is returned as a joined string.
-	e = out.WithMethod("String", record.blueprint(), nil, []string{"string"}, func(scope url.Values) error {
-		out.Println("%s := make([]string, 0, %d)", symbols.clauseSlice, len(clauseMethods))
-		out.Println("%s := 1", symbols.valueCount)
+	// condition method which iterates over all of these, calling them and adding the non-empty string clauses to a
+	// list, which eventually is returned as a joined string.
+	return out.WithMethod(blueprintConditionMethod, record.blueprint(), params, returns, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		out.Println("%s := make([]string, 0, %d)", symbols.clauseSlice, len(clauseMethods)+2)
+		out.Println("%s := make([]interface{}, 0)", symbols.values)
+
+		calls := make([]string, 0, len(clauseMethods)+2)
 
 		for _, method := range clauseMethods {
-			out.WithIf("%s, %s := %s.%s(%s); %s != \"\"", func(url.Values) error {
+			calls = append(calls, fmt.Sprintf("%s.%s(%s+len(%s))", receiver, method, symbols.valueCount, symbols.values))
+		}
+
+		// The groups of the blueprint are clauses of their own, each holding the conditions of its blueprints.
+		for _, group := range []struct{ field, conjunction string }{{blueprintAndField, "AND"}, {blueprintOrField, "OR"}} {
+			calls = append(calls, fmt.Sprintf(
+				"%s.%s(%s.%s, \" %s \", %s+len(%s))",
+				receiver,
+				blueprintGroupMethod,
+				receiver,
+				group.field,
+				group.conjunction,
+				symbols.valueCount,
+				symbols.values,
+			))
+		}
+
+		for _, call := range calls {
+			out.WithIf("%s, %s := %s; %s != \"\"", func(url.Values) error {
 				out.Println("%s = append(%s, %s)", symbols.clauseSlice, symbols.clauseSlice, symbols.clauseItem)
-				out.Println("%s+=len(%s)", symbols.valueCount, symbols.values)
-				return nil
-			}, symbols.clauseItem, symbols.values, scope.Get("receiver"), method, symbols.valueCount, symbols.clauseItem)
+				return out.Println("%s = append(%s, %s...)", symbols.values, symbols.values, symbols.clauseValue)
+			}, symbols.clauseItem, symbols.clauseValue, call, symbols.clauseItem)
 		}
 
 		out.WithIf("len(%s) == 0", func(url.Values) error {
-			return out.Returns(writing.EmptyString)
+			return out.Returns(writing.EmptyString, symbols.values)
 		}, symbols.clauseSlice)
 
 		out.Println("%s := \" AND \"", symbols.clauseMap)
 
 		out.WithIf("%s.Inclusive == true", func(url.Values) error {
 			return out.Println("%s = \" OR \"", symbols.clauseMap)
-		}, scope.Get("receiver"))
+		}, receiver)
 
-		return out.Returns(fmt.Sprintf("\"WHERE \" + strings.Join(%s, %s)", symbols.clauseSlice, symbols.clauseMap))
+		return out.Returns(fmt.Sprintf("strings.Join(%s, %s)", symbols.clauseSlice, symbols.clauseMap), symbols.values)
 	})
+}
 
-	if e != nil {
-		return e
+// writeGroupComment writes the doc comment of the blueprint's group fields, listing the fields of the group members
+// that only apply to the blueprint given to the store and are ignored when set on a member.
+func writeGroupComment(out writing.GoWriter, record marlowRecord) {
+	ignored := []string{"Limit", "Offset", "OrderBy"}
+
+	if _, _, keyed := record.primaryKeyField(); keyed {
+		ignored = append(ignored, blueprintCursorField)
 	}
 
-	return out.WithMethod("Values", record.blueprint(), nil, []string{"[]interface{}"}, func(scope url.Values) error {
-		out.Println("%s := make([]interface{}, 0, %d)", symbols.clauseSlice, len(clauseMethods))
+	if record.softDeleteColumn() != "" {
+		ignored = append(ignored, blueprintIncludeDeletedField, blueprintOnlyDeletedField)
+	}
 
+	last := len(ignored) - 1
+	list := fmt.Sprintf("%s and %s", strings.Join(ignored[:last], ", "), ignored[last])
+
+	groups := fmt.Sprintf("%s and %s", blueprintAndField, blueprintOrField)
+	out.Comment("%s nest the conditions of other blueprints within this one. Only the lookups, groups", groups)
+	out.Comment("and Inclusive flag of the members apply; these fields are ignored when set on a member:")
+	out.Comment("%s.", list)
+}
+
+// writeGroupMethod generates the blueprint method joining the conditions of a group of blueprints using the conjunction
+// provided. Each condition is enclosed in parentheses, as is the group itself when it holds more than one condition.
+// Placeholders are numbered following the values of the conditions that precede them; the paging, ordering, cursor and
+// soft delete fields of the members are ignored.
+func writeGroupMethod(out writing.GoWriter, record marlowRecord) error {
+	params := []writing.FuncParam{
+		{Symbol: "_group", Type: fmt.Sprintf("[]*%s", record.blueprint())},
+		{Symbol: "_conjunction", Type: "string"},
+		{Symbol: "_count", Type: "int"},
+	}
+
+	returns := []string{"string", "[]interface{}"}
+
+	return out.WithMethod(blueprintGroupMethod, record.blueprint(), params, returns, func(url.Values) error {
+		out.Println("_clauses := make([]string, 0, len(_group))")
+		out.Println("_values := make([]interface{}, 0)")
+
+		out.WithIter("_, _member := range _group", func(url.Values) error {
+			out.WithIf("_member == nil", func(url.Values) error {
+				return out.Println("continue")
+			})
+
+			return out.WithIf("_item, _itemValues := _member.%s(_count+len(_values)); _item != \"\"", func(url.Values) error {
+				out.Println("_clauses = append(_clauses, \"(\"+_item+\")\")")
+				return out.Println("_values = append(_values, _itemValues...)")
+			}, blueprintConditionMethod)
+		})
+
+		out.WithIf("len(_clauses) < 2", func(url.Values) error {
+			return out.Returns("strings.Join(_clauses, _conjunction)", "_values")
+		})
+
+		return out.Returns("\"(\"+strings.Join(_clauses, _conjunction)+\")\"", "_values")
+	})
+}
+
+// writeClauseMethod generates the blueprint method producing the condition used by the store's lookups, along with the
+// values of its placeholders. Placeholders are numbered from the count provided so that the condition can be embedded
+// within a larger query.
+func writeClauseMethod(out writing.GoWriter, record marlowRecord) error {
+	symbols := clauseSymbols{
+		clause:      "_clause",
+		valueCount:  "_count",
+		values:      "_values",
+		limits:      "_limits",
+		keyset:      "_keyset",
+		keysetValue: "_keysetValues",
+		deleted:     "_deleted",
+	}
+
+	_, _, keyed := record.primaryKeyField()
+	limited := keyed || record.softDeleteColumn() != ""
+
+	params := []writing.FuncParam{{Symbol: symbols.valueCount, Type: "int"}}
+	returns := []string{"string", "[]interface{}"}
+
+	return out.WithMethod(blueprintClauseMethod, record.blueprint(), params, returns, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		out.Println(
+			"%s, %s := %s.%s(%s)",
+			symbols.clause,
+			symbols.values,
+			receiver,
+			blueprintConditionMethod,
+			symbols.valueCount,
+		)
+
+		if !limited {
+			return out.Returns(symbols.clause, symbols.values)
+		}
+
+		writeClauseLimits(out, record, receiver, symbols)
+
+		// The limiting clauses always apply to the results, even for inclusive blueprints.
+		out.WithIf("len(%s) > 0", func(url.Values) error {
+			limits := fmt.Sprintf("strings.Join(%s, \" AND \")", symbols.limits)
+			return out.Returns(fmt.Sprintf("fmt.Sprintf(\"(%%s) AND %%s\", %s, %s)", symbols.clause, limits), symbols.values)
+		}, symbols.limits)
+
+		return out.Returns(symbols.clause, symbols.values)
+	})
+}
+
+type clauseSymbols struct {
+	clause      string
+	clauseMap   string
+	clauseSlice string
+	clauseItem  string
+	valueCount  string
+	values      string
+	clauseValue string
+	limits      string
+	keyset      string
+	keysetValue string
+	deleted     string
+}
+
+// writeClauseLimits writes the portion of the clause method collecting the clauses that limit every lookup regardless
+// of the blueprint being inclusive: the soft deletion clause of records configured with soft deletes and the keyset
+// clause of records with a primary key. When no field clause is present, these are returned on their own.
+func writeClauseLimits(out writing.GoWriter, record marlowRecord, receiver string, symbols clauseSymbols) {
+	_, _, keyed := record.primaryKeyField()
+
+	out.Println("%s := make([]string, 0, 2)", symbols.limits)
+
+	if record.softDeleteColumn() != "" {
+		out.WithIf("%s := %s.%s(); %s != \"\"", func(url.Values) error {
+			return out.Println("%s = append(%s, %s)", symbols.limits, symbols.limits, symbols.deleted)
+		}, symbols.deleted, receiver, blueprintDeletedMethod, symbols.deleted)
+	}
+
+	if keyed {
+		out.Println(
+			"%s, %s := %s.%s(%s+len(%s))",
+			symbols.keyset,
+			symbols.keysetValue,
+			receiver,
+			blueprintKeysetMethod,
+			symbols.valueCount,
+			symbols.values,
+		)
+
+		out.Println("%s = append(%s, %s...)", symbols.values, symbols.values, symbols.keysetValue)
+
+		out.WithIf("%s != \"\"", func(url.Values) error {
+			return out.Println("%s = append(%s, %s)", symbols.limits, symbols.limits, symbols.keyset)
+		}, symbols.keyset)
+	}
+
+	out.WithIf("%s == \"\"", func(url.Values) error {
+		return out.Returns(fmt.Sprintf("strings.Join(%s, \" AND \")", symbols.limits), symbols.values)
+	}, symbols.clause)
+}
+
+// writeDeletedMethod generates the blueprint method producing the clause that limits lookups of records configured
+// with soft deletes to the records that have not been deleted, unless the blueprint asks for deleted records.
+func writeDeletedMethod(out writing.GoWriter, record marlowRecord) error {
+	column := record.softDeleteColumn()
+
+	if column == "" {
+		return nil
+	}
+
+	reference := record.columnReference(column)
+
+	return out.WithMethod(blueprintDeletedMethod, record.blueprint(), nil, []string{"string"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		out.WithIf("%s.%s == true", func(url.Values) error {
+			return out.Returns(fmt.Sprintf("\"%s IS NOT NULL\"", reference))
+		}, receiver, blueprintOnlyDeletedField)
+
+		out.WithIf("%s.%s == true", func(url.Values) error {
+			return out.Returns(writing.EmptyString)
+		}, receiver, blueprintIncludeDeletedField)
+
+		return out.Returns(fmt.Sprintf("\"%s IS NULL\"", reference))
+	})
+}
+
+// writeStringMethod generates the blueprint's String method, producing the WHERE clause used by the store's lookups.
+func writeStringMethod(out writing.GoWriter, record marlowRecord) error {
+	return out.WithMethod("String", record.blueprint(), nil, []string{"string"}, func(scope url.Values) error {
+		out.Println("_clause, _ := %s.%s(1)", scope.Get("receiver"), blueprintClauseMethod)
+
+		out.WithIf("_clause == \"\"", func(url.Values) error {
+			return out.Returns(writing.EmptyString)
+		})
+
+		return out.Returns("\"WHERE \" + _clause")
+	})
+}
+
+// writeValuesMethod generates the blueprint's Values method, returning the values of every clause in the same order as
+// their placeholders appear in the WHERE clause.
+func writeValuesMethod(out writing.GoWriter, record marlowRecord) error {
+	return out.WithMethod("Values", record.blueprint(), nil, []string{"[]interface{}"}, func(scope url.Values) error {
 		out.WithIf("%s == nil", func(url.Values) error {
 			return out.Returns(writing.Nil)
 		}, scope.Get("receiver"))
 
-		for _, method := range clauseMethods {
-			out.WithIf("_, %s := %s.%s(0); %s != nil && len(%s) > 0", func(url.Values) error {
-				return out.Println("%s = append(%s, %s...)", symbols.clauseSlice, symbols.clauseSlice, symbols.clauseItem)
-			}, symbols.clauseItem, scope.Get("receiver"), method, symbols.clauseItem, symbols.clauseItem)
+		out.Println("_, _values := %s.%s(1)", scope.Get("receiver"), blueprintClauseMethod)
+		return out.Returns("_values")
+	})
+}
+
+// writeOffsetMethod generates the blueprint method returning the amount of records skipped by lookups. Lookups that
+// continue from a cursor already start after the records of the previous pages and so skip none.
+func writeOffsetMethod(out writing.GoWriter, record marlowRecord) error {
+	_, _, keyed := record.primaryKeyField()
+
+	return out.WithMethod(blueprintOffsetMethod, record.blueprint(), nil, []string{"int"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		condition := fmt.Sprintf("%s == nil", receiver)
+
+		if keyed {
+			condition = fmt.Sprintf("%s || %s.%s != \"\"", condition, receiver, blueprintCursorField)
+		}
+
+		out.WithIf(condition, func(url.Values) error {
+			return out.Returns("0")
+		})
+
+		return out.Returns(fmt.Sprintf("%s.Offset", receiver))
+	})
+}
+
+// writeOrderMethod generates the blueprint method responsible for turning the OrderBy and OrderDirection fields into a
+// validated ORDER BY clause, falling back to the record's defaultOrder when no OrderBy value was provided.
+func writeOrderMethod(out writing.GoWriter, record marlowRecord) error {
+	if e := writeOrderKeysMethod(out, record); e != nil {
+		return e
+	}
+
+	symbols := struct {
+		keys      string
+		keysError string
+	}{"_keys", "_ke"}
+
+	returns := []string{"string", "error"}
+
+	out.Comment("[marlow] order clause for \"%s\"", record.table())
+
+	return out.WithMethod(blueprintOrderMethod, record.blueprint(), nil, returns, func(scope url.Values) error {
+		out.Println("%s, %s := %s.%s()", symbols.keys, symbols.keysError, scope.Get("receiver"), blueprintOrderKeysMethod)
+
+		out.WithIf("%s != nil || len(%s) == 0", func(url.Values) error {
+			return out.Returns(writing.EmptyString, symbols.keysError)
+		}, symbols.keysError, symbols.keys)
+
+		nullable := nullableColumns(record)
+
+		if len(nullable) == 0 {
+			return out.Returns(fmt.Sprintf("\"ORDER BY \" + strings.Join(%s, \", \")", symbols.keys), writing.Nil)
+		}
+
+		// Nullable columns are preceded by their NULL check so that NULL values are ordered before every other value
+		// regardless of the dialect, the same way keyset clauses and the in-memory fakes order them.
+		out.Println("_nullable, _clauses := %s, make([]string, 0, len(%s)+1)", nullableLiteral(nullable), symbols.keys)
+
+		e := out.WithIter("_, _key := range %s", func(url.Values) error {
+			out.Println("_parts := strings.Fields(_key)")
+
+			out.WithIf("_nullable[_parts[0]]", func(url.Values) error {
+				return out.Println("_clauses = append(_clauses, fmt.Sprintf(\"%%s IS NOT NULL %%s\", _parts[0], _parts[1]))")
+			})
+
+			return out.Println("_clauses = append(_clauses, _key)")
+		}, symbols.keys)
+
+		if e != nil {
+			return e
+		}
+
+		return out.Returns("\"ORDER BY \" + strings.Join(_clauses, \", \")", writing.Nil)
+	})
+}
+
+// writeOrderKeysMethod generates the blueprint method returning the validated keys (a column reference followed by its
+// direction) of the lookup order. For records with a primary key, the primary key is added as the final key when it is
+// not already present so that the order of records is stable enough to page through using cursors.
+func writeOrderKeysMethod(out writing.GoWriter, record marlowRecord) error {
+	defaultKeys, e := orderKeys(record, record.config.Get(constants.DefaultOrderConfigOption))
+
+	if e != nil {
+		return e
+	}
+
+	symbols := struct {
+		direction string
+		keys      string
+		key       string
+		parts     string
+		column    string
+		keyOrder  string
+		clauses   string
+		keyed     string
+	}{"_direction", "_keys", "_key", "_parts", "_column", "_keyDirection", "_clauses", "_keyed"}
+
+	_, _, keyed := record.primaryKeyField()
+	primaryKey := record.columnReference(record.primaryKeyColumn())
+
+	returns := []string{"[]string", "error"}
+
+	return out.WithMethod(blueprintOrderKeysMethod, record.blueprint(), nil, returns, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		out.WithIf("%s == nil || strings.TrimSpace(%s.OrderBy) == \"\"", func(url.Values) error {
+			if len(defaultKeys) == 0 {
+				return out.Returns(writing.Nil, writing.Nil)
+			}
+
+			return out.Returns(fmt.Sprintf("%#v", defaultKeys), writing.Nil)
+		}, receiver, receiver)
+
+		out.Println("%s := strings.ToUpper(strings.TrimSpace(%s.OrderDirection))", symbols.direction, receiver)
+
+		out.WithIf("%s == \"\"", func(url.Values) error {
+			return out.Println("%s = \"ASC\"", symbols.direction)
+		}, symbols.direction)
+
+		out.WithIf("%s != \"ASC\" && %s != \"DESC\"", func(url.Values) error {
+			return out.Returns(writing.Nil, fmt.Sprintf(
+				"fmt.Errorf(\"invalid order direction %%q\", %s.OrderDirection)",
+				receiver,
+			))
+		}, symbols.direction, symbols.direction)
+
+		out.Println("%s := strings.Split(%s.OrderBy, \",\")", symbols.keys, receiver)
+		out.Println("%s := make([]string, 0, len(%s)+1)", symbols.clauses, symbols.keys)
+
+		if keyed {
+			out.Println("%s := false", symbols.keyed)
 		}
 
-		return out.Returns(symbols.clauseSlice)
+		e := out.WithIter("_, %s := range %s", func(url.Values) error {
+			out.Println("%s := strings.Fields(%s)", symbols.parts, symbols.key)
+
+			out.WithIf("len(%s) == 0 || len(%s) > 2", func(url.Values) error {
+				return out.Returns(writing.Nil, fmt.Sprintf(
+					"fmt.Errorf(\"invalid order key %%q\", %s)",
+					symbols.key,
+				))
+			}, symbols.parts, symbols.parts)
+
+			out.Println("%s, %s := %s[0], %s", symbols.column, symbols.keyOrder, symbols.parts, symbols.direction)
+
+			out.WithIf("len(%s) == 2", func(url.Values) error {
+				return out.Println("%s = strings.ToUpper(%s[1])", symbols.keyOrder, symbols.parts)
+			}, symbols.parts)
+
+			out.WithIf("%s != \"ASC\" && %s != \"DESC\"", func(url.Values) error {
+				return out.Returns(writing.Nil, fmt.Sprintf(
+					"fmt.Errorf(\"invalid order direction %%q\", %s[1])",
+					symbols.parts,
+				))
+			}, symbols.keyOrder, symbols.keyOrder)
+
+			// Only columns known to the record are allowed to make their way into the clause.
+			out.Println("switch %s {", symbols.column)
+
+			for _, f := range record.fieldList(nil) {
+				out.Println("case \"%s\":", record.fields[f.name].Get(constants.ColumnConfigOption))
+				out.Println("%s = \"%s\"", symbols.column, f.column)
+			}
+
+			out.Println("default:")
+			out.Returns(writing.Nil, fmt.Sprintf(
+				"fmt.Errorf(\"invalid order column %%q\", %s)",
+				symbols.column,
+			))
+			out.Println("}")
+
+			if keyed {
+				out.Println("%s = %s || %s == %q", symbols.keyed, symbols.keyed, symbols.column, primaryKey)
+			}
+
+			return out.Println(
+				"%s = append(%s, fmt.Sprintf(\"%%s %%s\", %s, %s))",
+				symbols.clauses,
+				symbols.clauses,
+				symbols.column,
+				symbols.keyOrder,
+			)
+		}, symbols.key, symbols.keys)
+
+		if e != nil {
+			return e
+		}
+
+		if keyed {
+			out.WithIf("%s == false", func(url.Values) error {
+				return out.Println("%s = append(%s, \"%s ASC\")", symbols.clauses, symbols.clauses, primaryKey)
+			}, symbols.keyed)
+		}
+
+		return out.Returns(symbols.clauses, writing.Nil)
 	})
 }
 
+// orderKeys validates a comma separated list of order keys (each a column name optionally followed by ASC or DESC)
+// against the columns of the record, returning the column references and directions they represent. The primary key
+// of the record is added when it is not one of the keys.
+func orderKeys(record marlowRecord, keys string) ([]string, error) {
+	columns := make(map[string]string, len(record.fields))
+
+	for _, f := range record.fieldList(nil) {
+		columns[record.fields[f.name].Get(constants.ColumnConfigOption)] = f.column
+	}
+
+	items := make([]string, 0)
+
+	if strings.TrimSpace(keys) != "" {
+		items = strings.Split(keys, ",")
+	}
+
+	clauses := make([]string, 0, len(items)+1)
+	_, _, keyed := record.primaryKeyField()
+	primaryKey := record.columnReference(record.primaryKeyColumn())
+
+	for _, item := range items {
+		parts := strings.Fields(item)
+
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid order key \"%s\" for record %s", item, record.name())
+		}
+
+		reference, ok := columns[parts[0]]
+
+		if !ok {
+			return nil, fmt.Errorf("invalid order column \"%s\" for record %s", parts[0], record.name())
+		}
+
+		direction := "ASC"
+
+		if len(parts) == 2 {
+			direction = strings.ToUpper(parts[1])
+		}
+
+		if direction != "ASC" && direction != "DESC" {
+			return nil, fmt.Errorf("invalid order direction \"%s\" for record %s", parts[1], record.name())
+		}
+
+		keyed = keyed && reference != primaryKey
+		clauses = append(clauses, fmt.Sprintf("%s %s", reference, direction))
+	}
+
+	if keyed {
+		clauses = append(clauses, fmt.Sprintf("%s ASC", primaryKey))
+	}
+
+	return clauses, nil
+}
+
 func fieldMethods(record marlowRecord, name string, config url.Values, methods chan<- string) []io.Reader {
 	fieldType := config.Get("type")
 	results := make([]io.Reader, 0, len(record.fields))
 	typeInfo := getTypeInfo(fieldType)
 
-	if typeInfo&types.IsConstType != 0 {
-		results = append(results, simpleTypeIn(record, name, config, methods))
+	// Nullable fields receive the IN lookup of their own type, along with the range and LIKE lookups of their value's.
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		typeInfo = getTypeInfo(nullable.underlying)
+		results = append(results, nullableMethods(record, name, config, methods), nullMethods(record, name, config, methods))
+		results = append(results, nullableNotIn(record, name, config, methods))
+	} else if typeInfo&types.IsConstType != 0 {
+		results = append(results, simpleTypeIn(record, name, config, methods, false))
+		results = append(results, simpleTypeIn(record, name, config, methods, true))
 	}
 
 	if typeInfo&types.IsString != 0 {
-		results = append(results, stringMethods(record, name, config, methods))
+		results = append(results, stringMethods(record, name, config, methods, false))
+		results = append(results, stringMethods(record, name, config, methods, true))
 	}
 
 	if typeInfo&types.IsNumeric != 0 {
-		results = append(results, numericalMethods(record, name, config, methods))
-	}
-
-	if fieldType == "sql.NullInt64" {
-		results = append(results, nullableIntMethods(record, name, config, methods))
+		results = append(results, numericalMethods(record, name, config, methods, false))
+		results = append(results, numericalMethods(record, name, config, methods, true))
 	}
 
 	if len(results) == 0 {
@@ -173,7 +772,7 @@ func fieldMethods(record marlowRecord, name string, config url.Values, methods c
 	return results
 }
 
-func nullableIntMethods(record marlowRecord, fieldName string, config url.Values, methods chan<- string) io.Reader {
+func nullableMethods(record marlowRecord, fieldName string, config url.Values, methods chan<- string) io.Reader {
 	pr, pw := io.Pipe()
 	columnName := config.Get(constants.ColumnConfigOption)
 	methodName := fmt.Sprintf("%sInString", columnName)
@@ -187,14 +786,14 @@ func nullableIntMethods(record marlowRecord, fieldName string, config url.Values
 		index        string
 	}{"_placeholders", "_values", "_v", "_joined", "_count", "_"}
 
-	columnReference := fmt.Sprintf("%s.%s", record.table(), columnName)
+	columnReference := record.columnReference(columnName)
 
 	returns := []string{"string", "[]interface{}"}
 	params := []writing.FuncParam{
 		{Type: "int", Symbol: symbols.valueCount},
 	}
 
-	if record.dialect() == "postgres" {
+	if record.numberedPlaceholders() {
 		symbols.index = "_i"
 	}
 
@@ -212,13 +811,7 @@ func nullableIntMethods(record marlowRecord, fieldName string, config url.Values
 
 			// Add conditional check for length presence on lookup slice.
 			writer.WithIf("len(%s) == 0", func(url.Values) error {
-				query := fmt.Sprintf("\"%s NOT NULL\"", columnReference)
-
-				if record.dialect() == "postgres" {
-					query = fmt.Sprintf("\"%s IS NOT NULL\"", columnReference)
-				}
-
-				return writer.Returns(query, writing.Nil)
+				return writer.Returns(strconv.Quote(record.dialect().NotNull(columnReference)), writing.Nil)
 			}, fieldReference)
 
 			writer.Println("%s := make([]string, 0, len(%s))", symbols.placeholders, fieldReference)
@@ -229,27 +822,15 @@ func nullableIntMethods(record marlowRecord, fieldName string, config url.Values
 					return writer.Returns(fmt.Sprintf("\"%s IS NULL\"", columnReference), writing.Nil)
 				}, symbols.item)
 
-				// TODO: cleanup dialog placeholder generation...
-				switch record.dialect() {
-				case "postgres":
-					placeholderString := "%s = append(%s, fmt.Sprintf(\"$%%d\", %s+%s))"
-					writer.Println(
-						placeholderString,
-						symbols.placeholders,
-						symbols.placeholders,
-						symbols.index,
-						symbols.valueCount,
-					)
-				default:
-					writer.Println("%s = append(%s, \"?\")", symbols.placeholders, symbols.placeholders)
-				}
+				placeholder := record.placeholders("%s", fmt.Sprintf("%s+%s", symbols.index, symbols.valueCount))
+				writer.Println("%s = append(%s, %s)", symbols.placeholders, symbols.placeholders, placeholder)
 				writer.Println("%s = append(%s, %s)", symbols.values, symbols.values, symbols.item)
 				return nil
 			}, symbols.index, symbols.item, fieldReference)
 
 			writer.Println("%s := strings.Join(%s, \",\")", symbols.result, symbols.placeholders)
 
-			clauseString := fmt.Sprintf("fmt.Sprintf(\"%s.%s IN (%%s)\", %s)", record.table(), columnName, symbols.result)
+			clauseString := fmt.Sprintf("fmt.Sprintf(\"%s IN (%%s)\", %s)", columnReference, symbols.result)
 			return writer.Returns(clauseString, symbols.values)
 		})
 
@@ -265,34 +846,157 @@ func nullableIntMethods(record marlowRecord, fieldName string, config url.Values
 	return pr
 }
 
-func simpleTypeIn(record marlowRecord, fieldName string, fieldConfig url.Values, methods chan<- string) io.Reader {
+// nullableNotIn returns a generator for the blueprint method excluding the records holding any of the values of the
+// nullable field's NOT IN lookup slice. Like the NOT IN clause itself, it never matches records without a value; the
+// invalid values of the slice are skipped, leaving only those records excluded when the slice holds no other value.
+func nullableNotIn(record marlowRecord, fieldName string, config url.Values, methods chan<- string) io.Reader {
+	pr, pw := io.Pipe()
+	columnName := config.Get(constants.ColumnConfigOption)
+	methodName := fmt.Sprintf("%sNotInString", columnName)
+	fieldName = fmt.Sprintf("%s%s", fieldName, record.config.Get(constants.BlueprintNotInFieldSuffixConfigOption))
+	columnReference := record.columnReference(columnName)
+
+	symbols := struct {
+		placeholders string
+		values       string
+		item         string
+		result       string
+		valueCount   string
+	}{"_placeholders", "_values", "_v", "_joined", "_count"}
+
+	returns := []string{"string", "[]interface{}"}
+	params := []writing.FuncParam{
+		{Type: "int", Symbol: symbols.valueCount},
+	}
+
+	write := func() {
+		writer := writing.NewGoWriter(pw)
+		writer.Comment("[marlow] nullable NOT IN clause for \"%s\", never matching records without a value", columnReference)
+
+		e := writer.WithMethod(methodName, record.blueprint(), params, returns, func(scope url.Values) error {
+			fieldReference := fmt.Sprintf("%s.%s", scope.Get("receiver"), fieldName)
+
+			writer.WithIf("len(%s) == 0", func(url.Values) error {
+				return writer.Returns(writing.EmptyString, writing.Nil)
+			}, fieldReference)
+
+			writer.Println("%s := make([]string, 0, len(%s))", symbols.placeholders, fieldReference)
+			writer.Println("%s := make([]interface{}, 0, len(%s))", symbols.values, fieldReference)
+
+			writer.WithIter("_, %s := range %s", func(url.Values) error {
+				writer.WithIf("%s.Valid == false", func(url.Values) error {
+					return writer.Println("continue")
+				}, symbols.item)
+
+				position := fmt.Sprintf("len(%s)+%s", symbols.values, symbols.valueCount)
+				writer.Println("%s = append(%s, %s)", symbols.placeholders, symbols.placeholders, record.placeholders("%s", position))
+				return writer.Println("%s = append(%s, %s)", symbols.values, symbols.values, symbols.item)
+			}, symbols.item, fieldReference)
+
+			writer.WithIf("len(%s) == 0", func(url.Values) error {
+				return writer.Returns(strconv.Quote(record.dialect().NotNull(columnReference)), writing.Nil)
+			}, symbols.values)
+
+			writer.Println("%s := strings.Join(%s, \",\")", symbols.result, symbols.placeholders)
+
+			clauseString := fmt.Sprintf("fmt.Sprintf(\"%s NOT IN (%%s)\", %s)", columnReference, symbols.result)
+			return writer.Returns(clauseString, symbols.values)
+		})
+
+		if e == nil {
+			methods <- methodName
+		}
+
+		pw.CloseWithError(e)
+	}
+
+	go write()
+
+	return pr
+}
+
+// nullMethods returns a generator for the blueprint method limiting lookups to the records holding a value for the
+// nullable field, or to the records without one, based on the tri-state null field of the blueprint.
+func nullMethods(record marlowRecord, fieldName string, config url.Values, methods chan<- string) io.Reader {
+	pr, pw := io.Pipe()
+	columnName := config.Get(constants.ColumnConfigOption)
+	methodName := fmt.Sprintf("%sNullString", columnName)
+	nullFieldName := fmt.Sprintf("%s%s", fieldName, record.config.Get(constants.BlueprintNullFieldSuffixConfigOption))
+	columnReference := record.columnReference(columnName)
+
+	returns := []string{"string", "[]interface{}"}
+	params := []writing.FuncParam{{Type: "int", Symbol: "_"}}
+
+	write := func() {
+		writer := writing.NewGoWriter(pw)
+		writer.Comment("[marlow] null clause for \"%s\"", columnReference)
+
+		e := writer.WithMethod(methodName, record.blueprint(), params, returns, func(scope url.Values) error {
+			nullField := fmt.Sprintf("%s.%s", scope.Get("receiver"), nullFieldName)
+
+			writer.WithIf("%s == nil", func(url.Values) error {
+				return writer.Returns(writing.EmptyString, writing.Nil)
+			}, nullField)
+
+			writer.WithIf("*%s == true", func(url.Values) error {
+				return writer.Returns(fmt.Sprintf("\"%s IS NULL\"", columnReference), writing.Nil)
+			}, nullField)
+
+			return writer.Returns(strconv.Quote(record.dialect().NotNull(columnReference)), writing.Nil)
+		})
+
+		if e == nil {
+			methods <- methodName
+		}
+
+		pw.CloseWithError(e)
+	}
+
+	go write()
+
+	return pr
+}
+
+// simpleTypeIn returns a generator for the blueprint method limiting lookups to the records holding any of the values
+// of the field's lookup slice. Negated methods exclude these records instead, using the field's NOT IN lookup slice.
+func simpleTypeIn(
+	record marlowRecord,
+	fieldName string,
+	fieldConfig url.Values,
+	methods chan<- string,
+	negated bool,
+) io.Reader {
 	pr, pw := io.Pipe()
 	columnName := fieldConfig.Get(constants.ColumnConfigOption)
-	methodName := fmt.Sprintf("%sInString", columnName)
-	columnReference := fmt.Sprintf("%s.%s", record.table(), columnName)
+	methodName, operator := fmt.Sprintf("%sInString", columnName), "IN"
+	columnReference := record.columnReference(columnName)
+
+	if negated {
+		fieldName = fmt.Sprintf("%s%s", fieldName, record.config.Get(constants.BlueprintNotInFieldSuffixConfigOption))
+		methodName, operator = fmt.Sprintf("%sNotInString", columnName), "NOT IN"
+	}
 
 	symbols := struct {
-		placeholders    string
-		values          string
-		item            string
-		result          string
-		counter         string
-		index           string
-		placeholderItem string
-	}{"_placeholder", "_values", "_v", "_joined", "_count", "_i", "_p"}
+		placeholders string
+		values       string
+		item         string
+		result       string
+		counter      string
+		index        string
+	}{"_placeholder", "_values", "_v", "_joined", "_count", "_i"}
 
 	returns := []string{"string", "[]interface{}"}
 	params := []writing.FuncParam{
 		{Type: "int", Symbol: symbols.counter},
 	}
 
-	if record.dialect() != "postgres" {
+	if record.numberedPlaceholders() != true {
 		symbols.index = "_"
 	}
 
 	write := func() {
 		writer := writing.NewGoWriter(pw)
-		writer.Comment("[marlow] type IN clause for \"%s\"", columnReference)
+		writer.Comment("[marlow] type %s clause for \"%s\"", operator, columnReference)
 
 		e := writer.WithMethod(methodName, record.blueprint(), params, returns, func(scope url.Values) error {
 			fieldReference := fmt.Sprintf("%s.%s", scope.Get("receiver"), fieldName)
@@ -306,21 +1010,15 @@ func simpleTypeIn(record marlowRecord, fieldName string, fieldConfig url.Values,
 			writer.Println("%s := make([]interface{}, 0, len(%s))", symbols.values, fieldReference)
 
 			writer.WithIter("%s, %s := range %s", func(url.Values) error {
-				// TODO: cleanup dialog placeholder generation...
-				switch record.dialect() {
-				case "postgres":
-					writer.Println("%s := fmt.Sprintf(\"$%%d\", %s+%s)", symbols.placeholderItem, symbols.index, symbols.counter)
-					writer.Println("%s = append(%s, %s)", symbols.placeholders, symbols.placeholders, symbols.placeholderItem)
-				default:
-					writer.Println("%s = append(%s, \"?\")", symbols.placeholders, symbols.placeholders)
-				}
+				placeholder := record.placeholders("%s", fmt.Sprintf("%s+%s", symbols.index, symbols.counter))
+				writer.Println("%s = append(%s, %s)", symbols.placeholders, symbols.placeholders, placeholder)
 
 				writer.Println("%s = append(%s, %s)", symbols.values, symbols.values, symbols.item)
 				return nil
 			}, symbols.index, symbols.item, fieldReference)
 
 			writer.Println("%s := strings.Join(%s, \",\")", symbols.result, symbols.placeholders)
-			clauseString := fmt.Sprintf("fmt.Sprintf(\"%s IN (%%s)\", %s)", columnReference, symbols.result)
+			clauseString := fmt.Sprintf("fmt.Sprintf(\"%s %s (%%s)\", %s)", columnReference, operator, symbols.result)
 			return writer.Returns(clauseString, symbols.values)
 		})
 
@@ -336,12 +1034,30 @@ func simpleTypeIn(record marlowRecord, fieldName string, fieldConfig url.Values,
 	return pr
 }
 
-func stringMethods(record marlowRecord, fieldName string, fieldConfig url.Values, methods chan<- string) io.Reader {
+// stringMethods returns a generator for the blueprint method matching the field against the patterns of its LIKE lookup
+// slice. Negated methods exclude the records matching any of the patterns of the field's NOT LIKE lookup slice, their
+// clauses always being joined by AND.
+func stringMethods(
+	record marlowRecord,
+	fieldName string,
+	fieldConfig url.Values,
+	methods chan<- string,
+	negated bool,
+) io.Reader {
 	columnName := fieldConfig.Get(constants.ColumnConfigOption)
 	methodName := fmt.Sprintf("%sLikeString", columnName)
 	likeSuffix := record.config.Get(constants.BlueprintLikeFieldSuffixConfigOption)
+	columnReference := record.columnReference(columnName)
+	pattern, operator := record.dialect().PatternMatch(columnReference), "LIKE"
+
+	if negated {
+		operator = "NOT LIKE"
+		methodName = fmt.Sprintf("%sNotLikeString", columnName)
+		likeSuffix = record.config.Get(constants.BlueprintNotLikeFieldSuffixConfigOption)
+		pattern = fmt.Sprintf("NOT %s", pattern)
+	}
+
 	likeFieldName := fmt.Sprintf("%s%s", fieldName, likeSuffix)
-	columnReference := fmt.Sprintf("%s.%s", record.table(), columnName)
 
 	symbols := struct {
 		conjunction  string
@@ -353,7 +1069,7 @@ func stringMethods(record marlowRecord, fieldName string, fieldConfig url.Values
 		index        string
 	}{"_conjunc", "_placeholders", "_value", "_values", "_like", "_count", "_i"}
 
-	if record.dialect() != "postgres" {
+	if record.numberedPlaceholders() != true {
 		symbols.index = "_"
 	}
 
@@ -366,7 +1082,7 @@ func stringMethods(record marlowRecord, fieldName string, fieldConfig url.Values
 
 	write := func() {
 		writer := writing.NewGoWriter(pw)
-		writer.Comment("[marlow] string LIKE clause for \"%s\"", columnReference)
+		writer.Comment("[marlow] string %s clause for \"%s\"", operator, columnReference)
 
 		e := writer.WithMethod(methodName, record.blueprint(), params, returns, func(scope url.Values) error {
 			likeSlice := fmt.Sprintf("%s.%s", scope.Get("receiver"), likeFieldName)
@@ -379,12 +1095,8 @@ func stringMethods(record marlowRecord, fieldName string, fieldConfig url.Values
 			writer.Println("%s := make([]interface{}, 0, len(%s))", symbols.values, likeSlice)
 
 			writer.WithIter("%s, %s := range %s", func(url.Values) error {
-				likeString := fmt.Sprintf("\"%s LIKE ?\"", columnReference)
-
-				if record.dialect() == "postgres" {
-					psqlLike := "fmt.Sprintf(\"%s LIKE $%%d\", %s+%s)"
-					likeString = fmt.Sprintf(psqlLike, columnReference, symbols.count, symbols.index)
-				}
+				position := fmt.Sprintf("%s+%s", symbols.count, symbols.index)
+				likeString := record.placeholders(pattern, position)
 
 				writer.Println("%s := %s", symbols.statement, likeString)
 				writer.Println("%s = append(%s, %s)", symbols.placeholders, symbols.placeholders, symbols.statement)
@@ -393,6 +1105,11 @@ func stringMethods(record marlowRecord, fieldName string, fieldConfig url.Values
 
 			writer.Println("%s := \" AND \"", symbols.conjunction)
 
+			if negated {
+				clauseString := fmt.Sprintf("strings.Join(%s, %s)", symbols.placeholders, symbols.conjunction)
+				return writer.Returns(clauseString, symbols.values)
+			}
+
 			writer.WithIf("%s.Inclusive == true", func(url.Values) error {
 				return writer.Println("%s = \" OR \"", symbols.conjunction)
 			}, scope.Get("receiver"))
@@ -413,11 +1130,28 @@ func stringMethods(record marlowRecord, fieldName string, fieldConfig url.Values
 	return pr
 }
 
-func numericalMethods(record marlowRecord, fieldName string, fieldConfig url.Values, methods chan<- string) io.Reader {
+// numericalMethods returns a generator for the blueprint method limiting lookups to the records whose field falls
+// within the exclusive bounds of its range lookup. Negated methods limit lookups to the records falling outside of the
+// bounds of the field's NOT range lookup instead.
+func numericalMethods(
+	record marlowRecord,
+	fieldName string,
+	fieldConfig url.Values,
+	methods chan<- string,
+	negated bool,
+) io.Reader {
 	columnName := fieldConfig.Get(constants.ColumnConfigOption)
 	rangeMethodName := fmt.Sprintf("%sRangeString", columnName)
 	rangeFieldName := fmt.Sprintf("%s%s", fieldName, record.config.Get(constants.BlueprintRangeFieldSuffixConfigOption))
-	columnReference := fmt.Sprintf("%s.%s", record.table(), columnName)
+	columnReference := record.columnReference(columnName)
+	rangeTemplate, kind := fmt.Sprintf("(%s > %%s AND %s < %%s)", columnReference, columnReference), "range"
+
+	if negated {
+		kind = "outside of range"
+		rangeMethodName = fmt.Sprintf("%sNotRangeString", columnName)
+		rangeFieldName = fmt.Sprintf("%s%s", fieldName, record.config.Get(constants.BlueprintNotRangeFieldSuffixConfigOption))
+		rangeTemplate = fmt.Sprintf("(%s <= %%s OR %s >= %%s)", columnReference, columnReference)
+	}
 
 	pr, pw := io.Pipe()
 
@@ -434,7 +1168,7 @@ func numericalMethods(record marlowRecord, fieldName string, fieldConfig url.Val
 
 	write := func() {
 		writer := writing.NewGoWriter(pw)
-		writer.Comment("[marlow] range clause methods for %s", columnReference)
+		writer.Comment("[marlow] %s clause methods for %s", kind, columnReference)
 
 		e := writer.WithMethod(rangeMethodName, record.blueprint(), params, returns, func(scope url.Values) error {
 			receiver := scope.Get("receiver")
@@ -449,19 +1183,8 @@ func numericalMethods(record marlowRecord, fieldName string, fieldConfig url.Val
 			writer.Println("%s[0] = %s[0]", symbols.values, rangeArray)
 			writer.Println("%s[1] = %s[1]", symbols.values, rangeArray)
 
-			if record.dialect() == "postgres" {
-				rangeString := fmt.Sprintf(
-					"fmt.Sprintf(\"(%s > $%%d AND %s < $%%d)\", %s, %s+1)",
-					columnReference,
-					columnReference,
-					symbols.count,
-					symbols.count,
-				)
-
-				return writer.Returns(rangeString, symbols.values)
-			}
-
-			return writer.Returns(fmt.Sprintf("\"(%s > ? AND %s < ?)\"", columnReference, columnReference), symbols.values)
+			rangeString := record.placeholders(rangeTemplate, symbols.count, fmt.Sprintf("%s+1", symbols.count))
+			return writer.Returns(rangeString, symbols.values)
 		})
 
 		if e == nil {
@@ -4,6 +4,7 @@ import "io"
 import "fmt"
 import "sync"
 import "bytes"
+import "strings"
 import "testing"
 import "net/url"
 import "go/token"
@@ -124,11 +125,129 @@ func Test_Blueprint(t *testing.T) {
 				g.Assert(e).Equal(nil)
 			})
 
+			g.It("documents the fields of group members that are ignored", func() {
+				io.Copy(b, newBlueprintGenerator(record))
+				ignored := "// and Inclusive flag of the members apply; these fields are ignored when set on a member:\n"
+				g.Assert(strings.Contains(b.String(), ignored+"// Limit, Offset and OrderBy.\n")).Equal(true)
+			})
+
+			g.It("uses IS NOT NULL for present but empty nullable lookups of sqlite records", func() {
+				r.Set(constants.TableNameConfigOption, "books")
+				io.Copy(b, newBlueprintGenerator(record))
+				g.Assert(strings.Contains(b.String(), "return \"books.company_id IS NOT NULL\",nil")).Equal(true)
+			})
+
+			g.Describe("with a valid defaultOrder", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.TableNameConfigOption, "books")
+					r.Set(constants.DefaultOrderConfigOption, "page_count desc, name")
+				})
+
+				g.It("produced valid a golang struct", func() {
+					fmt.Fprintln(b, "package marlowt")
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e).Equal(nil)
+					_, e = parser.ParseFile(token.NewFileSet(), "", b, parser.AllErrors)
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("uses the validated default order as the fallback clause", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					expected := "[]string{\"books.page_count DESC\", \"books.name ASC\"}"
+					g.Assert(strings.Contains(b.String(), expected)).Equal(true)
+				})
+			})
+
+			g.Describe("with a defaultOrder referencing an unknown column", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.DefaultOrderConfigOption, "title")
+				})
+
+				g.It("returns an error", func() {
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e == nil).Equal(false)
+				})
+			})
+
+			g.Describe("with a defaultOrder using an invalid direction", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.DefaultOrderConfigOption, "name sideways")
+				})
+
+				g.It("returns an error", func() {
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e == nil).Equal(false)
+				})
+			})
+
+			g.Describe("with a mysql record dialect", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.TableNameConfigOption, "books")
+					r.Set(constants.DialectConfigOption, "mysql")
+				})
+
+				g.It("produced valid a golang struct", func() {
+					fmt.Fprintln(b, "package marlowt")
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e).Equal(nil)
+					_, e = parser.ParseFile(token.NewFileSet(), "", b, parser.AllErrors)
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("quotes column references used by IN and range clauses", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "fmt.Sprintf(\"`books`.`page_count` IN (%s)\", _joined)")).Equal(true)
+					expected := "\"(`books`.`page_count` > ? AND `books`.`page_count` < ?)\""
+					g.Assert(strings.Contains(b.String(), expected)).Equal(true)
+				})
+
+				g.It("uses the plain LIKE operator with unnumbered placeholders", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "_like := \"`books`.`name` LIKE ?\"")).Equal(true)
+				})
+
+				g.It("uses IS NOT NULL for present but empty nullable lookups", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "return \"`books`.`company_id` IS NOT NULL\",nil")).Equal(true)
+				})
+
+				g.It("quotes the columns used in the order clause", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "_column = \"`books`.`name`\"")).Equal(true)
+				})
+			})
+
 			g.Describe("with a postgres record dialect", func() {
 				g.BeforeEach(func() {
 					r.Set(constants.DialectConfigOption, "postgres")
 				})
 
+				g.It("numbers the placeholders of grouped blueprints following the values preceding them", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "And []*SomeBlueprint")).Equal(true)
+					g.Assert(strings.Contains(output, "Or []*SomeBlueprint")).Equal(true)
+					expected := "if _item, _itemValues := s.groupString(s.Or, \" OR \", _count+len(_values)); _item != \"\" {"
+					g.Assert(strings.Contains(output, expected)).Equal(true)
+					expected = "if _item, _itemValues := _member.conditionString(_count+len(_values)); _item != \"\" {"
+					g.Assert(strings.Contains(output, expected)).Equal(true)
+				})
+
+				g.It("numbers the placeholders of negated clauses from the clause count", func() {
+					r.Set(constants.TableNameConfigOption, "books")
+					r.Set(constants.BlueprintNotInFieldSuffixConfigOption, "NotIn")
+					r.Set(constants.BlueprintNotRangeFieldSuffixConfigOption, "NotRange")
+					r.Set(constants.BlueprintNotLikeFieldSuffixConfigOption, "NotLike")
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "fmt.Sprintf(\"books.page_count NOT IN (%s)\", _joined)")).Equal(true)
+					g.Assert(strings.Contains(output, "_placeholder = append(_placeholder, fmt.Sprintf(\"$%d\", _i+_count))")).Equal(true)
+					expected := "fmt.Sprintf(\"(books.page_count <= $%d OR books.page_count >= $%d)\", _count, _count+1)"
+					g.Assert(strings.Contains(output, expected)).Equal(true)
+					g.Assert(strings.Contains(output, "_like := fmt.Sprintf(\"NOT books.name LIKE $%d\", _count+_i)")).Equal(true)
+					g.Assert(strings.Contains(output, "NameNotLike []string")).Equal(true)
+				})
+
 				g.It("produced valid a golang struct", func() {
 					fmt.Fprintln(b, "package marlowt")
 					_, e := io.Copy(b, newBlueprintGenerator(record))
@@ -137,6 +256,182 @@ func Test_Blueprint(t *testing.T) {
 					g.Assert(e).Equal(nil)
 				})
 			})
+
+			g.Describe("with nullable fields of every kind", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.TableNameConfigOption, "books")
+					f["Subtitle"] = url.Values{"type": {"sql.NullString"}, "column": {"subtitle"}}
+					f["FinishedAt"] = url.Values{"type": {"sql.NullTime"}, "column": {"finished_at"}}
+					f["Rating"] = url.Values{"type": {"sql.NullFloat64"}, "column": {"rating"}}
+					f["Signed"] = url.Values{"type": {"sql.NullBool"}, "column": {"signed"}}
+					r.Set(constants.BlueprintLikeFieldSuffixConfigOption, "Like")
+					r.Set(constants.BlueprintRangeFieldSuffixConfigOption, "Range")
+				})
+
+				g.It("produced valid a golang struct", func() {
+					fmt.Fprintln(b, "package marlowt")
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e).Equal(nil)
+					_, e = parser.ParseFile(token.NewFileSet(), "", b, parser.AllErrors)
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("does not warn about unsupported types", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "unsupported type")).Equal(false)
+				})
+
+				g.It("adds the range and LIKE lookups of the underlying types", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "SubtitleLike []string")).Equal(true)
+					g.Assert(strings.Contains(output, "FinishedAtRange []time.Time")).Equal(true)
+					g.Assert(strings.Contains(output, "RatingRange []float64")).Equal(true)
+					g.Assert(strings.Contains(output, "SignedRange")).Equal(false)
+					g.Assert(strings.Contains(output, "\"books.subtitle LIKE ?\"")).Equal(true)
+					g.Assert(strings.Contains(output, "\"(books.finished_at > ? AND books.finished_at < ?)\"")).Equal(true)
+				})
+
+				g.It("adds the tri-state null filter of every nullable field", func() {
+					r.Set(constants.BlueprintNullFieldSuffixConfigOption, "IsNull")
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "SubtitleIsNull *bool")).Equal(true)
+					g.Assert(strings.Contains(output, "CompanyIDIsNull *bool")).Equal(true)
+					g.Assert(strings.Contains(output, "NameIsNull")).Equal(false)
+					g.Assert(strings.Contains(output, "if *s.SignedIsNull == true {\nreturn \"books.signed IS NULL\",nil")).Equal(true)
+					g.Assert(strings.Contains(output, "_item, _itemValues := s.signedNullString(_count+len(_values))")).Equal(true)
+				})
+
+				g.It("adds the NOT IN lookup of every nullable field, skipping invalid values", func() {
+					r.Set(constants.BlueprintNotInFieldSuffixConfigOption, "NotIn")
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "SubtitleNotIn []sql.NullString")).Equal(true)
+					g.Assert(strings.Contains(output, "SignedNotIn []sql.NullBool")).Equal(true)
+					g.Assert(strings.Contains(output, "if _v.Valid == false {\ncontinue")).Equal(true)
+					g.Assert(strings.Contains(output, "fmt.Sprintf(\"books.rating NOT IN (%s)\", _joined)")).Equal(true)
+					g.Assert(strings.Contains(output, "return \"books.finished_at IS NOT NULL\",nil")).Equal(true)
+				})
+
+				g.It("uses the nullable IN lookup for every nullable field", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "return \"books.signed IS NULL\",nil")).Equal(true)
+					g.Assert(strings.Contains(b.String(), "return \"books.subtitle IS NULL\",nil")).Equal(true)
+				})
+			})
+
+			g.Describe("with a primary key defined", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.TableNameConfigOption, "books")
+					r.Set(constants.PrimaryKeyColumnConfigOption, "page_count")
+				})
+
+				g.It("produced valid a golang struct", func() {
+					fmt.Fprintln(b, "package marlowt")
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e).Equal(nil)
+					_, e = parser.ParseFile(token.NewFileSet(), "", b, parser.AllErrors)
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("adds the cursor field", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "Cursor string")).Equal(true)
+				})
+
+				g.It("orders by the primary key when there is no other order", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "return []string{\"books.page_count ASC\"},nil")).Equal(true)
+				})
+
+				g.It("adds the primary key as the final order key when it is missing", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "_keyed = _keyed || _column == \"books.page_count\"")).Equal(true)
+					g.Assert(strings.Contains(output, "_clauses = append(_clauses, \"books.page_count ASC\")")).Equal(true)
+				})
+
+				g.It("decodes cursor values into the type of their field", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "case \"books.birthday\":\nvar _value time.Time")).Equal(true)
+					g.Assert(strings.Contains(output, "case \"books.page_count\":\n_values = append(_values, _record.PageCount)")).Equal(true)
+				})
+
+				g.It("combines the keyset clause with the field clauses", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "fmt.Sprintf(\"%s %s %s\", _parts[0], _operator, _placeholder)")).Equal(true)
+					g.Assert(strings.Contains(output, "fmt.Sprintf(\"(%s) AND %s\"")).Equal(true)
+				})
+
+				g.It("numbers the keyset placeholders after the field clause values for postgres records", func() {
+					r.Set(constants.DialectConfigOption, "postgres")
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "_placeholder := fmt.Sprintf(\"$%d\", _count+len(_values))")).Equal(true)
+				})
+
+				g.It("orders NULL values of nullable columns before every other value", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "map[string]bool{\"books.company_id\": true}")).Equal(true)
+					g.Assert(strings.Contains(output, "fmt.Sprintf(\"%s IS NOT NULL %s\", _parts[0], _parts[1])")).Equal(true)
+					g.Assert(strings.Contains(output, "fmt.Sprintf(\"(%s < %s OR %s IS NULL)\", _parts[0], _placeholder, _parts[0])")).Equal(true)
+				})
+
+				g.It("skips no records in lookups continuing from a cursor", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "if s == nil || s.Cursor != \"\" {\nreturn 0")).Equal(true)
+				})
+
+				g.It("does not add the cursor field to records without a primary key", func() {
+					r.Del(constants.PrimaryKeyColumnConfigOption)
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "Cursor string")).Equal(false)
+				})
+			})
+
+			g.Describe("with a soft delete column", func() {
+				g.BeforeEach(func() {
+					r.Set(constants.TableNameConfigOption, "books")
+					r.Set(constants.SoftDeleteConfigOption, "deleted_at")
+				})
+
+				g.It("documents that the soft delete fields of group members are ignored", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					expected := "// Limit, Offset, OrderBy, IncludeDeleted and OnlyDeleted.\n"
+					g.Assert(strings.Contains(b.String(), expected)).Equal(true)
+				})
+
+				g.It("produced valid a golang struct", func() {
+					fmt.Fprintln(b, "package marlowt")
+					_, e := io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(e).Equal(nil)
+					_, e = parser.ParseFile(token.NewFileSet(), "", b, parser.AllErrors)
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("adds the fields including or limiting lookups to deleted records", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "IncludeDeleted bool")).Equal(true)
+					g.Assert(strings.Contains(b.String(), "OnlyDeleted bool")).Equal(true)
+				})
+
+				g.It("excludes the deleted records unless requested otherwise", func() {
+					io.Copy(b, newBlueprintGenerator(record))
+					output := b.String()
+					g.Assert(strings.Contains(output, "return \"books.deleted_at IS NULL\"")).Equal(true)
+					g.Assert(strings.Contains(output, "return \"books.deleted_at IS NOT NULL\"")).Equal(true)
+					g.Assert(strings.Contains(output, "if _deleted := s.deletedString(); _deleted != \"\"")).Equal(true)
+				})
+
+				g.It("does not add the fields to records without a soft delete column", func() {
+					r.Del(constants.SoftDeleteConfigOption)
+					io.Copy(b, newBlueprintGenerator(record))
+					g.Assert(strings.Contains(b.String(), "IncludeDeleted bool")).Equal(false)
+				})
+			})
 		})
 
 	})
@@ -3,4 +3,7 @@ package constants
 const (
 	// DefaultMarlowFileExtension is the extension used by marlowc for the files it creates.
 	DefaultMarlowFileExtension = ".marlow.go"
+
+	// DefaultFakeFileSuffix is appended to the name of the files holding the fake stores generated by marlowc.
+	DefaultFakeFileSuffix = "_fake"
 )
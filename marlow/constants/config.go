@@ -4,6 +4,13 @@ const (
 	// StoreLoggerField is the internal field on stores for the io.Writer log stream
 	StoreLoggerField = "logger"
 
+	// StoreClockField is the internal field on stores of records with timestamp columns holding the func used to read the
+	// current time when stamping them.
+	StoreClockField = "clock"
+
+	// PackageStoresName is the name of the struct generated once per package grouping the stores of its records.
+	PackageStoresName = "Stores"
+
 	// PrimaryKeyColumnConfigOption specifies the primary key on the record
 	PrimaryKeyColumnConfigOption = "primaryKey"
 
@@ -16,6 +23,10 @@ const (
 	// DefaultLimitConfigOption is the 'table' field config key used to determine the default limit used in lookups.
 	DefaultLimitConfigOption = "defaultLimit"
 
+	// DefaultOrderConfigOption is the 'table' field config key holding the comma separated list of columns (and optional
+	// ASC/DESC directions) that lookups will be ordered by when the blueprint does not provide an OrderBy value.
+	DefaultOrderConfigOption = "defaultOrder"
+
 	// RecordNameConfigOption is the key used on the special table field to determine the return value of everything.
 	RecordNameConfigOption = "recordName"
 
@@ -30,9 +41,43 @@ const (
 	// searching by the queryable interface.
 	BlueprintLikeFieldSuffixConfigOption = "blueprintLikeFieldSuffix"
 
+	// BlueprintNotInFieldSuffixConfigOption is the string that will be appended to fields on the blueprint used for
+	// excluding records holding any of the values provided. Records of nullable fields without a value are excluded too.
+	BlueprintNotInFieldSuffixConfigOption = "blueprintNotInFieldSuffix"
+
+	// BlueprintNotRangeFieldSuffixConfigOption is the string that will be appended to fields on the blueprint used for
+	// excluding records whose numerical field types fall within a range.
+	BlueprintNotRangeFieldSuffixConfigOption = "blueprintNotRangeFieldSuffix"
+
+	// BlueprintNotLikeFieldSuffixConfigOption is the string that will be appended to string/text fields on the blueprint
+	// used for excluding records matching any of the patterns provided.
+	BlueprintNotLikeFieldSuffixConfigOption = "blueprintNotLikeFieldSuffix"
+
+	// BlueprintNullFieldSuffixConfigOption is the string that will be appended to nullable fields on the blueprint used
+	// for limiting lookups to records with or without a value.
+	BlueprintNullFieldSuffixConfigOption = "blueprintNullFieldSuffix"
+
 	// BlueprintNameSuffix is added after the record name for the type that can be stringifyed into valid sql code.
 	BlueprintNameSuffix = "Blueprint"
 
+	// NotFoundErrorSuffix is added after the record name for the error type returned by primary key lookups that find
+	// no record.
+	NotFoundErrorSuffix = "NotFoundError"
+
+	// VersionConflictErrorSuffix is added after the record name for the error type returned by updates of versioned
+	// records when no row holds the expected version.
+	VersionConflictErrorSuffix = "VersionConflictError"
+
+	// ValidationErrorSuffix is added after the record name for the error type returned by creates and updates of records
+	// whose fields fail their validation rules.
+	ValidationErrorSuffix = "ValidationError"
+
+	// FieldErrorSuffix is added after the record name for the type describing a single failed validation rule.
+	FieldErrorSuffix = "FieldError"
+
+	// CursorNameSuffix is added after the record name for the type returned by the store's iterator.
+	CursorNameSuffix = "Cursor"
+
 	// BlueprintNameConfigOption holds the blueprint name on the record config.
 	BlueprintNameConfigOption = "blueprintName"
 
@@ -54,9 +99,51 @@ const (
 	// ColumnConfigOption is the key of the value used on individual fields that represents which column marlow queries.
 	ColumnConfigOption = "column"
 
+	// ColumnAutoCreateTimeFlag indicates the time.Time field is stamped with the store's clock when records are created.
+	ColumnAutoCreateTimeFlag = "autoCreateTime"
+
+	// ColumnAutoUpdateTimeFlag indicates the time.Time field is stamped with the store's clock when records are created and
+	// whenever they are updated.
+	ColumnAutoUpdateTimeFlag = "autoUpdateTime"
+
+	// ColumnVersionFlag indicates the integer field holds the version of the record used for optimistic locking. Updates
+	// increment it and are only applied to rows still holding the version the update was made from.
+	ColumnVersionFlag = "version"
+
+	// ColumnValidateOption holds the comma separated validation rules of a field (required, min=<n> & max=<n>) checked
+	// by the generated Validate method of the record before it is created or updated.
+	ColumnValidateOption = "validate"
+
+	// ColumnUniqueFlag indicates the column is covered by a unique constraint of its own. The only column flagged is the
+	// conflict target of upserts, unless the record configures a unique key.
+	ColumnUniqueFlag = "unique"
+
+	// ColumnIndexFlag indicates the column is covered by its own index in the CREATE TABLE statements of the record.
+	ColumnIndexFlag = "index"
+
+	// ColumnDefaultOption holds the sql expression used as the default value of the column in the CREATE TABLE statements
+	// of the record, written as is (e.g. default='pending' or default=CURRENT_TIMESTAMP).
+	ColumnDefaultOption = "default"
+
 	// ColumnBitmaskOption is used to indicate a field is a bitmask & can be used to generate bitwise ops.
 	ColumnBitmaskOption = "bitmask"
 
+	// ReferencesConfigOption is the field config key naming the record (declared in the same package) whose primary key
+	// the field holds. It is used to generate the preload methods that load related records in a single lookup.
+	ReferencesConfigOption = "references"
+
+	// JoinsConfigOption is the 'table' field config key holding the comma separated pair of records (declared in the same
+	// package) that the record joins. Each of them must be referenced by a single field of the record.
+	JoinsConfigOption = "joins"
+
+	// UniqueKeyConfigOption holds the comma separated columns covered together by a unique constraint of the record (e.g
+	// uniqueKey=book_id,author_id), used as the conflict target of upserts.
+	UniqueKeyConfigOption = "uniqueKey"
+
+	// SoftDeleteConfigOption is the 'table' field config key naming the nullable timestamp column set when records are
+	// deleted. Records configured with it are hidden from lookups until restored or purged.
+	SoftDeleteConfigOption = "softDelete"
+
 	// QueryableConfigOption boolean value, true/false based on fields ability to be updated.
 	QueryableConfigOption = "queryable"
 
@@ -4,6 +4,17 @@ const (
 	// InvalidDeletionBlueprint returned from the delete api when the blueprint generates no where clause.
 	InvalidDeletionBlueprint = "deletion blueprints must generate limiting clauses"
 
+	// UnsupportedTransactionExecutor returned from the transaction api when the store is bound to an executor that is
+	// neither a *sql.DB or a *sql.Tx.
+	UnsupportedTransactionExecutor = "transactions require a *sql.DB or *sql.Tx executor (received %T)"
+
+	// EmptyStoresTransaction returned from the transaction api of the package's stores when none of them are present.
+	EmptyStoresTransaction = "transactions require at least one store"
+
+	// SavepointRollbackError returned from the transaction api when a nested transaction's callback fails and the savepoint
+	// it was given is unable to be rolled back, receiving the callback's error followed by the rollback error.
+	SavepointRollbackError = "%w (unable to roll back savepoint: %v)"
+
 	// InvalidGeneratedCodeError is the message that is returned when marlow generates invalid code. Typically a problem
 	// with marlow, not necessarily the source data.
 	InvalidGeneratedCodeError = "Marlow was unable to generate valid golang code. " +
@@ -21,8 +21,7 @@ type createableSymbolList struct {
 	execError                string
 	affectedResult           string
 	affectedError            string
-
-	recordIndex string
+	now                      string
 }
 
 // newCreateableGenerator returns a reader that will generate a record store's creation api.
@@ -43,7 +42,7 @@ func newCreateableGenerator(record marlowRecord) io.Reader {
 		execError:                "_execError",
 		affectedResult:           "_affectedResult",
 		affectedError:            "_affectedError",
-		recordIndex:              "_",
+		now:                      "_now",
 	}
 
 	params := []writing.FuncParam{
@@ -55,94 +54,45 @@ func newCreateableGenerator(record marlowRecord) io.Reader {
 		"error",
 	}
 
-	if record.dialect() == "postgres" && record.primaryKeyColumn() == "" {
-		pw.CloseWithError(fmt.Errorf("postgres records are required to have a primaryKey defined"))
+	strategy := record.dialect().InsertID()
+
+	if strategy == ReturningInsertID && record.primaryKeyColumn() == "" {
+		pw.CloseWithError(fmt.Errorf("records using a RETURNING insert are required to have a primaryKey defined"))
 		return pr
 	}
 
+	// Upserts share the creation api's feature flag; they are written after the insert methods.
+	upserts := newUpsertableGenerator(record)
+
 	go func() {
 		gosrc := writing.NewGoWriter(pw)
 
 		gosrc.Comment("[marlow] createable")
 
-		if record.dialect() == "postgres" {
-			symbols.recordIndex = "_recordIndex"
-		}
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
 
-		e := gosrc.WithMethod(methodName, record.store(), params, returns, func(scope url.Values) error {
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
 			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
 
 			gosrc.WithIf("len(%s) == 0", func(url.Values) error {
 				return gosrc.Returns("0", writing.Nil)
 			}, symbols.recordParam)
 
-			columns := make([]string, 0, len(record.fields))
-			placeholders := make([]string, 0, len(record.fields))
-			index := 1
-
-			// Skip fields that have the `autoIncrement` directive.
-			fields := record.fieldList(func(config url.Values) bool {
-				return config.Get(constants.ColumnAutoIncrementFlag) == ""
-			})
-
-			for _, field := range fields {
-				placeholder := "?"
-
-				if record.dialect() == "postgres" {
-					fmtStr := "fmt.Sprintf(\"$%%d\", (%s*%d)+%d)"
-					placeholder = fmt.Sprintf(fmtStr, symbols.recordIndex, len(fields), index)
-				}
-
-				columns = append(columns, strings.Split(field.column, ".")[1])
-				placeholders = append(placeholders, placeholder)
-				index++
-			}
-
-			gosrc.Println("%s := make([]string, 0, len(%s))", symbols.statementPlaceholderList, symbols.recordParam)
-			gosrc.Println("%s := make([]interface{}, 0, len(%s))", symbols.statementValueList, symbols.recordParam)
-
-			gosrc.WithIter("%s, %s := range %s", func(url.Values) error {
-				if record.dialect() == "postgres" {
-					gosrc.Println("%s := []string{%s}", symbols.rowValueString, strings.Join(placeholders, ", "))
-				} else {
-					gosrc.Println("%s := %s", symbols.rowValueString, writing.StringSliceLiteral(placeholders))
-				}
-
-				fieldReferences := make([]string, 0, len(placeholders))
-
-				for _, field := range fields {
-					config := record.fields[field.name]
-
-					if config.Get(constants.ColumnAutoIncrementFlag) != "" {
-						continue
-					}
-
-					fieldReferences = append(fieldReferences, fmt.Sprintf("%s.%s", symbols.singleRecord, field.name))
-				}
+			// The creation hooks are called on the records given, allowing them to be normalized before they are inserted.
+			writeHookLoop(gosrc, record, beforeCreateHook, symbols.recordParam, "-1")
+			writeRecordValidations(gosrc, record, symbols.recordParam, "-1")
 
-				gosrc.Println(
-					"%s = append(%s, %s)",
-					symbols.statementValueList,
-					symbols.statementValueList,
-					strings.Join(fieldReferences, ","),
-				)
-
-				return gosrc.Println(
-					"%s = append(%s, fmt.Sprintf(\"(%%s)\", strings.Join(%s, \",\")))",
-					symbols.statementPlaceholderList,
-					symbols.statementPlaceholderList,
-					symbols.rowValueString,
-				)
-			}, symbols.recordIndex, symbols.singleRecord, symbols.recordParam)
+			columns := writeInsertRows(gosrc, record, scope.Get("receiver"), symbols)
 
 			gosrc.Println("%s := new(bytes.Buffer)", symbols.queryBuffer)
 
-			insertStatement := fmt.Sprintf("INSERT INTO %s (%s) VALUES %%s;", record.table(), strings.Join(columns, ","))
+			table := record.quote(record.table())
+			insertStatement := fmt.Sprintf("INSERT INTO %s (%s) VALUES %%s;", table, strings.Join(columns, ","))
 
-			if record.dialect() == "postgres" {
+			if strategy == ReturningInsertID {
 				template := "INSERT INTO %s (%s) VALUES %%s RETURNING %s;"
 				primary := record.primaryKeyColumn()
-				insertStatement = fmt.Sprintf(template, record.table(), strings.Join(columns, ","), primary)
+				insertStatement = fmt.Sprintf(template, table, strings.Join(columns, ","), primary)
 			}
 
 			gosrc.Println(
@@ -155,10 +105,11 @@ func newCreateableGenerator(record marlowRecord) io.Reader {
 			logwriter.AddLog(symbols.queryBuffer, symbols.statementValueList)
 
 			gosrc.Println(
-				"%s, %s := %s.Prepare(%s.String())",
+				"%s, %s := %s.PrepareContext(%s, %s.String())",
 				symbols.statement,
 				symbols.statementError,
 				scope.Get("receiver"),
+				contextSymbol,
 				symbols.queryBuffer,
 			)
 
@@ -168,51 +119,150 @@ func newCreateableGenerator(record marlowRecord) io.Reader {
 
 			gosrc.Println("defer %s.Close()\n", symbols.statement)
 
-			execution := "%s, %s := %s.Exec(%s...)"
+			execution := "%s, %s := %s.ExecContext(%s, %s...)"
 
-			if record.dialect() == "postgres" {
-				execution = "%s, %s := %s.Query(%s...)"
+			if strategy == ReturningInsertID {
+				execution = "%s, %s := %s.QueryContext(%s, %s...)"
 			}
 
-			gosrc.Println(execution, symbols.execResult, symbols.execError, symbols.statement, symbols.statementValueList)
+			gosrc.Println(
+				execution,
+				symbols.execResult,
+				symbols.execError,
+				symbols.statement,
+				contextSymbol,
+				symbols.statementValueList,
+			)
 
 			gosrc.WithIf("%s != nil", func(url.Values) error {
 				return gosrc.Returns("-1", symbols.execError)
 			}, symbols.execError)
 
-			if record.dialect() != "postgres" {
-				gosrc.Println("%s, %s := %s.LastInsertId()", symbols.affectedResult, symbols.affectedError, symbols.execResult)
-				return gosrc.Returns(symbols.affectedResult, symbols.affectedError)
-			}
-
-			gosrc.Println("var %s int64", symbols.affectedResult)
-
-			// Close the rows
-			gosrc.Println("defer %s.Close()\n", symbols.execResult)
+			writeInsertID(gosrc, strategy, symbols)
 
-			// Iterate over rows scanning into result
-			gosrc.WithIter("%s.Next()", func(url.Values) error {
-				gosrc.WithIf("%s := %s.Scan(&%s); %s != nil", func(url.Values) error {
-					return gosrc.Returns("-1", symbols.affectedError)
-				}, symbols.affectedError, symbols.execResult, symbols.affectedResult, symbols.affectedError)
-
-				return nil
-			}, symbols.execResult)
+			// The creation hook runs once the rows are inserted and their ids read; failing hooks cannot undo the insert.
+			writeHookLoop(gosrc, record, afterCreateHook, symbols.recordParam, "-1")
 
 			return gosrc.Returns(symbols.affectedResult, writing.Nil)
 		})
 
 		if e == nil {
 			record.registerImports("fmt", "bytes", "strings")
-			record.registerStoreMethod(writing.FuncDecl{
-				Name:    methodName,
-				Params:  params,
-				Returns: returns,
-			})
 		}
 
 		pw.CloseWithError(e)
 	}()
 
-	return pr
+	return io.MultiReader(pr, upserts)
+}
+
+// writeInsertID writes the read of the id of the last row inserted into the affected result symbol, using the strategy of
+// the record's dialect.
+func writeInsertID(gosrc writing.GoWriter, strategy InsertIDStrategy, symbols createableSymbolList) {
+	if strategy == ReturningInsertID {
+		gosrc.Println("var %s int64", symbols.affectedResult)
+
+		// Close the rows
+		gosrc.Println("defer %s.Close()\n", symbols.execResult)
+
+		// Iterate over rows scanning into result
+		gosrc.WithIter("%s.Next()", func(url.Values) error {
+			return gosrc.WithIf("%s := %s.Scan(&%s); %s != nil", func(url.Values) error {
+				return gosrc.Returns("-1", symbols.affectedError)
+			}, symbols.affectedError, symbols.execResult, symbols.affectedResult, symbols.affectedError)
+		}, symbols.execResult)
+
+		return
+	}
+
+	gosrc.Println("%s, %s := %s.LastInsertId()", symbols.affectedResult, symbols.affectedError, symbols.execResult)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns("-1", symbols.affectedError)
+	}, symbols.affectedError)
+
+	// The id of the last row is derived from the first when that is what the dialect reports for multi-row inserts so
+	// the return value matches that of the other dialects.
+	if strategy == FirstInsertID {
+		gosrc.Println("%s += int64(len(%s)) - 1", symbols.affectedResult, symbols.recordParam)
+	}
+}
+
+// writeInsertRows writes the construction of the placeholder and value lists for each of the records being inserted by a
+// multi-row insert statement, returning the (quoted) columns that are being inserted in the order of their values. The
+// timestamp fields of every record are stamped with a single read of the store's clock.
+func writeInsertRows(gosrc writing.GoWriter, record marlowRecord, receiver string, symbols createableSymbolList) []string {
+	recordIndex := "_"
+
+	if record.numberedPlaceholders() {
+		recordIndex = "_recordIndex"
+	}
+
+	columns := make([]string, 0, len(record.fields))
+	placeholders := make([]string, 0, len(record.fields))
+	index := 1
+
+	// Skip fields that have the `autoIncrement` directive.
+	fields := record.fieldList(func(config url.Values) bool {
+		return config.Get(constants.ColumnAutoIncrementFlag) == ""
+	})
+
+	for _, field := range fields {
+		position := fmt.Sprintf("(%s*%d)+%d", recordIndex, len(fields), index)
+		placeholder := record.placeholders("%s", position)
+
+		columns = append(columns, record.quote(field.bare))
+		placeholders = append(placeholders, placeholder)
+		index++
+	}
+
+	stamps := make(map[string]bool)
+
+	for _, field := range record.timestampFields(constants.ColumnAutoCreateTimeFlag, constants.ColumnAutoUpdateTimeFlag) {
+		stamps[field.name] = true
+	}
+
+	if len(stamps) > 0 {
+		writeClockRead(gosrc, receiver, symbols.now)
+	}
+
+	gosrc.Println("%s := make([]string, 0, len(%s))", symbols.statementPlaceholderList, symbols.recordParam)
+	gosrc.Println("%s := make([]interface{}, 0, len(%s))", symbols.statementValueList, symbols.recordParam)
+
+	gosrc.WithIter("%s, %s := range %s", func(url.Values) error {
+		gosrc.Println("%s := []string{%s}", symbols.rowValueString, strings.Join(placeholders, ", "))
+
+		fieldReferences := make([]string, 0, len(placeholders))
+
+		for _, field := range fields {
+			config := record.fields[field.name]
+
+			if config.Get(constants.ColumnAutoIncrementFlag) != "" {
+				continue
+			}
+
+			if stamps[field.name] {
+				fieldReferences = append(fieldReferences, symbols.now)
+				continue
+			}
+
+			fieldReferences = append(fieldReferences, fmt.Sprintf("%s.%s", symbols.singleRecord, field.name))
+		}
+
+		gosrc.Println(
+			"%s = append(%s, %s)",
+			symbols.statementValueList,
+			symbols.statementValueList,
+			strings.Join(fieldReferences, ","),
+		)
+
+		return gosrc.Println(
+			"%s = append(%s, fmt.Sprintf(\"(%%s)\", strings.Join(%s, \",\")))",
+			symbols.statementPlaceholderList,
+			symbols.statementPlaceholderList,
+			symbols.rowValueString,
+		)
+	}, recordIndex, symbols.singleRecord, symbols.recordParam)
+
+	return columns
 }
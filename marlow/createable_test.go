@@ -3,6 +3,7 @@ package marlow
 import "io"
 import "sync"
 import "bytes"
+import "strings"
 import "testing"
 import "net/url"
 import "github.com/franela/goblin"
@@ -17,6 +18,7 @@ type createableTestScaffold struct {
 
 	record url.Values
 	fields map[string]url.Values
+	hooks  map[string]bool
 
 	received map[string]bool
 	closed   bool
@@ -40,6 +42,7 @@ func (s *createableTestScaffold) g() io.Reader {
 		config:        s.record,
 		importChannel: s.imports,
 		storeChannel:  s.methods,
+		hooks:         s.hooks,
 	}
 
 	return newCreateableGenerator(record)
@@ -62,6 +65,7 @@ func Test_Createable(t *testing.T) {
 
 				record:   make(url.Values),
 				fields:   make(map[string]url.Values),
+				hooks:    make(map[string]bool),
 				received: make(map[string]bool),
 				closed:   false,
 			}
@@ -128,6 +132,98 @@ func Test_Createable(t *testing.T) {
 					g.Assert(e).Equal(nil)
 				})
 			})
+
+			g.Describe("with a mysql record dialect", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.DialectConfigOption, "mysql")
+					scaffold.fields["ID"].Set(constants.ColumnConfigOption, "id")
+					scaffold.fields["ID"].Set(constants.ColumnAutoIncrementFlag, "true")
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+					scaffold.fields["UniversityID"].Set(constants.ColumnConfigOption, "university_id")
+				})
+
+				g.It("compiles successfully without a primaryKey defined", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("quotes the table and column identifiers with backticks", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "\"INSERT INTO `authors` (`name`,`university_id`) VALUES %s;\""
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("quotes the bare name of columns containing dots", func() {
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "legacy.name")
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "\"INSERT INTO `authors` (`legacy.name`,`university_id`) VALUES %s;\""
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("uses unnumbered placeholders", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "_placeholders := []string{\"?\", \"?\"}"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("returns the id of the last row created from the id of the first", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "_affectedResult += int64(len(_records)) - 1"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("calls the creation hook once the id of the rows has been read", func() {
+					scaffold.hooks[afterCreateHook] = true
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					hook, read := strings.Index(output, "_records[_i].AfterCreate()"), strings.Index(output, "LastInsertId()")
+					g.Assert(read > 0).Equal(true)
+					g.Assert(hook > read).Equal(true)
+				})
+			})
+
+			g.Describe("with timestamp fields", func() {
+				g.BeforeEach(func() {
+					scaffold.fields["ID"].Set(constants.ColumnAutoIncrementFlag, "true")
+					scaffold.fields["CreatedAt"] = url.Values{
+						"type":                             []string{"time.Time"},
+						constants.ColumnAutoCreateTimeFlag: []string{"true"},
+					}
+					scaffold.fields["UpdatedAt"] = url.Values{
+						"type":                             []string{"time.Time"},
+						constants.ColumnAutoUpdateTimeFlag: []string{"true"},
+					}
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("stamps every timestamp field with a single read of the store's clock", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					g.Assert(strings.Count(output, "_now := a.clock()")).Equal(1)
+					g.Assert(strings.Contains(output, "_valueList = append(_valueList, _now,_record.Name,_record.UniversityID,_now)")).Equal(true)
+				})
+			})
+
+			g.Describe("with validation rules", func() {
+				g.BeforeEach(func() {
+					scaffold.fields["ID"].Set(constants.ColumnAutoIncrementFlag, "true")
+					scaffold.fields["Name"].Set(constants.ColumnValidateOption, "required")
+				})
+
+				g.It("validates every record before inserting any of them", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+					output := scaffold.buffer.String()
+					validation := strings.Index(output, "_invalid := _records[_i].Validate()")
+					g.Assert(validation > 0).Equal(true)
+					g.Assert(validation < strings.Index(output, "INSERT INTO")).Equal(true)
+					g.Assert(strings.Contains(output, "return -1,&AuthorValidationError{Fields: _invalid}")).Equal(true)
+				})
+			})
 		})
 	})
 }
@@ -15,6 +15,11 @@ type deleteableSymbols struct {
 	statement      string
 	prepared       string
 	statementError string
+	unscoped       string
+	scoped         string
+	values         string
+	now            string
+	stamp          string
 }
 
 // newDeleteableGenerator is responsible for creating a generator that will write out the Delete api methods.
@@ -30,6 +35,11 @@ func newDeleteableGenerator(record marlowRecord) io.Reader {
 		prepared:       "_statement",
 		statementError: "_se",
 		result:         "_execResult",
+		unscoped:       "_unscoped",
+		scoped:         "_scoped",
+		values:         "_values",
+		now:            "_now",
+		stamp:          "_stamp",
 	}
 
 	params := []writing.FuncParam{
@@ -46,55 +56,37 @@ func newDeleteableGenerator(record marlowRecord) io.Reader {
 
 		gosrc.Comment("[marlow] deleteable")
 
-		e := gosrc.WithMethod(methodName, record.store(), params, returns, func(scope url.Values) error {
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
 			receiver := scope.Get("receiver")
 			logwriter := logWriter{receiver: receiver, output: gosrc}
 
-			gosrc.WithIf("%s == nil || %s.String() == \"\"", func(url.Values) error {
-				return gosrc.Returns("-1", fmt.Sprintf("fmt.Errorf(\"%s\")", constants.InvalidDeletionBlueprint))
-			}, symbols.blueprint, symbols.blueprint)
-
-			deleteString := fmt.Sprintf("DELETE FROM %s", record.table())
-
-			gosrc.Println("%s := fmt.Sprintf(\"%s %%s\", %s)", symbols.statement, deleteString, symbols.blueprint)
-			gosrc.Println("%s, %s := %s.Prepare(%s + \";\")", symbols.prepared, symbols.e, receiver, symbols.statement)
-
-			// Check for preparation error.
-			gosrc.WithIf("%s != nil", func(url.Values) error { return gosrc.Returns("-1", symbols.e) }, symbols.e)
-
-			// Always close the prepared statement.
-			gosrc.Println("defer %s.Close()", symbols.prepared)
-
-			logwriter.AddLog(symbols.statement, fmt.Sprintf("%s.Values()", symbols.blueprint))
+			writeDeletionGuard(gosrc, record, symbols)
+			writeCursorCheck(gosrc, record, symbols.blueprint, "-1")
 
-			// Executre the prepared statement with the values from the blueprint.
-			gosrc.Println(
-				"%s, %s := %s.Exec(%s.Values()...)",
-				symbols.result,
-				symbols.e,
-				symbols.prepared,
-				symbols.blueprint,
-			)
+			if e := writeMatchedHooks(gosrc, record, beforeDeleteHook, receiver, symbols.blueprint); e != nil {
+				return e
+			}
 
-			// Check for statement.Exec error
-			gosrc.WithIf("%s != nil", func(url.Values) error { return gosrc.Returns("-1", symbols.e) }, symbols.e)
+			command := fmt.Sprintf("DELETE FROM %s", record.quote(record.table()))
 
-			gosrc.Println("%s, %s := %s.RowsAffected()", symbols.count, symbols.e, symbols.result)
+			// Records configured with soft deletes are only marked as deleted, keeping their rows around until purged. They
+			// are marked with the time read from the store's clock.
+			if column := record.softDeleteColumn(); column != "" {
+				command = fmt.Sprintf("UPDATE %s SET %s = %%s", record.quote(record.table()), record.quote(column))
+				return writeSoftDeletionExec(gosrc, logwriter, record, receiver, command, symbols)
+			}
 
-			gosrc.WithIf("%s != nil", func(url.Values) error {
-				return gosrc.Returns("-1", symbols.e)
-			}, symbols.e)
-
-			return gosrc.Returns(symbols.count, writing.Nil)
+			return writeDeletionExec(gosrc, logwriter, receiver, command, symbols)
 		})
 
+		if e == nil && record.softDeleteColumn() != "" {
+			e = writeSoftDeleteMethods(gosrc, record, symbols)
+		}
+
 		if e == nil {
 			record.registerImports("fmt")
-			record.registerStoreMethod(writing.FuncDecl{
-				Name:    methodName,
-				Returns: returns,
-				Params:  params,
-			})
 		}
 
 		pw.CloseWithError(e)
@@ -102,3 +94,116 @@ func newDeleteableGenerator(record marlowRecord) io.Reader {
 
 	return pr
 }
+
+// writeDeletionGuard writes the check preventing deletions with blueprints that would match every record. The soft
+// deletion clause is ignored by the check since it is present on every blueprint of records configured with it.
+func writeDeletionGuard(gosrc writing.GoWriter, record marlowRecord, symbols deleteableSymbols) {
+	invalid := fmt.Sprintf("fmt.Errorf(\"%s\")", constants.InvalidDeletionBlueprint)
+
+	if record.softDeleteColumn() == "" {
+		gosrc.WithIf("%s == nil || %s.String() == \"\"", func(url.Values) error {
+			return gosrc.Returns("-1", invalid)
+		}, symbols.blueprint, symbols.blueprint)
+		return
+	}
+
+	gosrc.WithIf("%s == nil", func(url.Values) error {
+		return gosrc.Returns("-1", invalid)
+	}, symbols.blueprint)
+
+	gosrc.Println("%s := *%s", symbols.unscoped, symbols.blueprint)
+	gosrc.Println(
+		"%s.%s, %s.%s = true, false",
+		symbols.unscoped,
+		blueprintIncludeDeletedField,
+		symbols.unscoped,
+		blueprintOnlyDeletedField,
+	)
+
+	gosrc.WithIf("%s.String() == \"\"", func(url.Values) error {
+		return gosrc.Returns("-1", invalid)
+	}, symbols.unscoped)
+}
+
+// writeSoftDeletionExec writes the execution of the statement marking the records matched by the blueprint as deleted.
+// The command's placeholder receives the time read from the store's clock; numbered placeholders follow those of the
+// blueprint while positional ones precede them.
+func writeSoftDeletionExec(
+	gosrc writing.GoWriter,
+	logwriter logWriter,
+	record marlowRecord,
+	receiver string,
+	command string,
+	symbols deleteableSymbols,
+) error {
+	writeClockRead(gosrc, receiver, symbols.now)
+	gosrc.Println("%s := %s.Values()", symbols.values, symbols.blueprint)
+	gosrc.Println("%s := %s", symbols.stamp, record.placeholders("%s", fmt.Sprintf("len(%s)+1", symbols.values)))
+
+	if record.numberedPlaceholders() {
+		gosrc.Println("%s = append(%s, %s)", symbols.values, symbols.values, symbols.now)
+	} else {
+		gosrc.Println("%s = append([]interface{}{%s}, %s...)", symbols.values, symbols.now, symbols.values)
+	}
+
+	gosrc.Println("%s := fmt.Sprintf(\"%s %%s\", %s, %s)", symbols.statement, command, symbols.stamp, symbols.blueprint)
+
+	return writeDeletionStatement(gosrc, logwriter, receiver, symbols)
+}
+
+// writeDeletionExec writes the execution of the statement starting with the command provided and ending with the WHERE
+// clause of the blueprint, returning the amount of rows affected by it.
+func writeDeletionExec(
+	gosrc writing.GoWriter,
+	logwriter logWriter,
+	receiver string,
+	command string,
+	symbols deleteableSymbols,
+) error {
+	gosrc.Println("%s := %s.Values()", symbols.values, symbols.blueprint)
+	gosrc.Println("%s := fmt.Sprintf(\"%s %%s\", %s)", symbols.statement, command, symbols.blueprint)
+
+	return writeDeletionStatement(gosrc, logwriter, receiver, symbols)
+}
+
+// writeDeletionStatement writes the preparation and execution of the statement with the values held by the values
+// symbol, returning the amount of rows affected by it.
+func writeDeletionStatement(gosrc writing.GoWriter, logwriter logWriter, receiver string, symbols deleteableSymbols) error {
+	gosrc.Println(
+		"%s, %s := %s.PrepareContext(%s, %s + \";\")",
+		symbols.prepared,
+		symbols.e,
+		receiver,
+		contextSymbol,
+		symbols.statement,
+	)
+
+	// Check for preparation error.
+	gosrc.WithIf("%s != nil", func(url.Values) error { return gosrc.Returns("-1", symbols.e) }, symbols.e)
+
+	// Always close the prepared statement.
+	gosrc.Println("defer %s.Close()", symbols.prepared)
+
+	logwriter.AddLog(symbols.statement, symbols.values)
+
+	// Executre the prepared statement with the values from the blueprint.
+	gosrc.Println(
+		"%s, %s := %s.ExecContext(%s, %s...)",
+		symbols.result,
+		symbols.e,
+		symbols.prepared,
+		contextSymbol,
+		symbols.values,
+	)
+
+	// Check for statement.Exec error
+	gosrc.WithIf("%s != nil", func(url.Values) error { return gosrc.Returns("-1", symbols.e) }, symbols.e)
+
+	gosrc.Println("%s, %s := %s.RowsAffected()", symbols.count, symbols.e, symbols.result)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns("-1", symbols.e)
+	}, symbols.e)
+
+	return gosrc.Returns(symbols.count, writing.Nil)
+}
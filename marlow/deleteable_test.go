@@ -3,6 +3,7 @@ package marlow
 import "io"
 import "sync"
 import "bytes"
+import "strings"
 import "net/url"
 import "testing"
 import "github.com/franela/goblin"
@@ -16,10 +17,12 @@ type deleteableTestScaffold struct {
 	methods chan writing.FuncDecl
 	record  url.Values
 	fields  map[string]url.Values
+	hooks   map[string]bool
 
-	received map[string]bool
-	closed   bool
-	wg       *sync.WaitGroup
+	received   map[string]bool
+	registered map[string]bool
+	closed     bool
+	wg         *sync.WaitGroup
 }
 
 func (s *deleteableTestScaffold) g() io.Reader {
@@ -28,6 +31,7 @@ func (s *deleteableTestScaffold) g() io.Reader {
 		fields:        s.fields,
 		importChannel: s.imports,
 		storeChannel:  s.methods,
+		hooks:         s.hooks,
 	}
 	return newDeleteableGenerator(record)
 }
@@ -41,14 +45,16 @@ func Test_Deleteable(t *testing.T) {
 
 		g.BeforeEach(func() {
 			scaffold = &deleteableTestScaffold{
-				buffer:   new(bytes.Buffer),
-				imports:  make(chan string),
-				methods:  make(chan writing.FuncDecl),
-				record:   make(url.Values),
-				fields:   make(map[string]url.Values),
-				received: make(map[string]bool),
-				closed:   false,
-				wg:       &sync.WaitGroup{},
+				buffer:     new(bytes.Buffer),
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				record:     make(url.Values),
+				fields:     make(map[string]url.Values),
+				hooks:      make(map[string]bool),
+				received:   make(map[string]bool),
+				registered: make(map[string]bool),
+				closed:     false,
+				wg:         &sync.WaitGroup{},
 			}
 
 			scaffold.wg.Add(2)
@@ -61,7 +67,8 @@ func Test_Deleteable(t *testing.T) {
 			}()
 
 			go func() {
-				for range scaffold.methods {
+				for m := range scaffold.methods {
+					scaffold.registered[m.Name] = true
 				}
 				scaffold.wg.Done()
 			}()
@@ -101,6 +108,119 @@ func Test_Deleteable(t *testing.T) {
 				g.Assert(e).Equal(nil)
 			})
 
+			g.It("registers both the context-aware and context-free deletion methods", func() {
+				io.Copy(scaffold.buffer, scaffold.g())
+				close(scaffold.imports)
+				close(scaffold.methods)
+				scaffold.wg.Wait()
+				scaffold.closed = true
+
+				g.Assert(scaffold.registered["DeleteAuthors"]).Equal(true)
+				g.Assert(scaffold.registered["DeleteAuthorsContext"]).Equal(true)
+				g.Assert(scaffold.received["context"]).Equal(true)
+			})
+
+			g.It("executes the deletion using the received context", func() {
+				io.Copy(scaffold.buffer, scaffold.g())
+				g.Assert(strings.Contains(scaffold.buffer.String(), "ExecContext(_ctx,")).Equal(true)
+			})
+
+			g.It("quotes the table name with backticks for mysql records", func() {
+				scaffold.record.Set(constants.DialectConfigOption, "mysql")
+				io.Copy(scaffold.buffer, scaffold.g())
+				expected := "fmt.Sprintf(\"DELETE FROM `authors` %s\", _blueprint)"
+				g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+			})
+
+			g.Describe("with a soft delete column", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.SoftDeleteConfigOption, "deleted_at")
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("marks the records as deleted instead of deleting them", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					source := scaffold.buffer.String()
+					expected := "fmt.Sprintf(\"UPDATE authors SET deleted_at = %s %s\", _stamp, _blueprint)"
+					g.Assert(strings.Contains(source, expected)).Equal(true)
+					g.Assert(strings.Contains(source, "_unscoped.IncludeDeleted, _unscoped.OnlyDeleted = true, false")).Equal(true)
+				})
+
+				g.It("marks the records with the time read from the store's clock", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					source := scaffold.buffer.String()
+					g.Assert(strings.Contains(source, ".clock()")).Equal(true)
+					g.Assert(strings.Contains(source, "_stamp := \"?\"")).Equal(true)
+					g.Assert(strings.Contains(source, "_values = append([]interface{}{_now}, _values...)")).Equal(true)
+				})
+
+				g.It("numbers the placeholder of the deletion time after the blueprint's for postgres records", func() {
+					scaffold.record.Set(constants.DialectConfigOption, "postgres")
+					io.Copy(scaffold.buffer, scaffold.g())
+					source := scaffold.buffer.String()
+					g.Assert(strings.Contains(source, "_stamp := fmt.Sprintf(\"$%d\", len(_values)+1)")).Equal(true)
+					g.Assert(strings.Contains(source, "_values = append(_values, _now)")).Equal(true)
+				})
+
+				g.It("restores and purges the soft deleted records", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					source := scaffold.buffer.String()
+					restore := "fmt.Sprintf(\"UPDATE authors SET deleted_at = NULL %s\", _blueprint)"
+					g.Assert(strings.Contains(source, restore)).Equal(true)
+					g.Assert(strings.Contains(source, "fmt.Sprintf(\"DELETE FROM authors %s\", _blueprint)")).Equal(true)
+					g.Assert(strings.Contains(source, "_scoped.IncludeDeleted, _scoped.OnlyDeleted = false, true")).Equal(true)
+				})
+
+				g.It("rejects restore and purge blueprints that generate no limiting clauses", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					source := scaffold.buffer.String()
+					g.Assert(strings.Count(source, "_unscoped.String() == \"\"")).Equal(3)
+					g.Assert(strings.Count(source, "deletion blueprints must generate limiting clauses")).Equal(6)
+				})
+
+				g.It("registers the restore and purge methods", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					close(scaffold.imports)
+					close(scaffold.methods)
+					scaffold.wg.Wait()
+					scaffold.closed = true
+
+					g.Assert(scaffold.registered["RestoreAuthors"]).Equal(true)
+					g.Assert(scaffold.registered["RestoreAuthorsContext"]).Equal(true)
+					g.Assert(scaffold.registered["PurgeAuthors"]).Equal(true)
+					g.Assert(scaffold.registered["PurgeAuthorsContext"]).Equal(true)
+				})
+			})
+
+			g.It("calls the deletion hook on each matched record before deleting them", func() {
+				scaffold.hooks[beforeDeleteHook] = true
+				_, e := io.Copy(scaffold.buffer, scaffold.g())
+				g.Assert(e).Equal(nil)
+				source := scaffold.buffer.String()
+				g.Assert(strings.Contains(source, ".IterateAuthorsContext(_ctx, &_hooked)")).Equal(true)
+				g.Assert(strings.Contains(source, "_cursor.Record().BeforeDelete()")).Equal(true)
+				hook, deletion := strings.Index(source, "BeforeDelete()"), strings.Index(source, "DELETE FROM authors")
+				g.Assert(hook < deletion).Equal(true)
+			})
+
+			g.It("iterates the records deleted without the limit and offset of the blueprint", func() {
+				scaffold.hooks[beforeDeleteHook] = true
+				io.Copy(scaffold.buffer, scaffold.g())
+				source := scaffold.buffer.String()
+				g.Assert(strings.Contains(source, "_hooked = *_blueprint")).Equal(true)
+				g.Assert(strings.Contains(source, "_hooked.Limit, _hooked.Offset = 0, 0")).Equal(true)
+			})
+
+			g.It("does not call deletion hooks with invalid signatures", func() {
+				scaffold.hooks[beforeDeleteHook] = false
+				io.Copy(scaffold.buffer, scaffold.g())
+				g.Assert(strings.Contains(scaffold.buffer.String(), "BeforeDelete")).Equal(false)
+			})
+
 		})
 
 	})
@@ -0,0 +1,318 @@
+package marlow
+
+import "fmt"
+import "sync"
+import "strings"
+
+// InsertIDStrategy describes how the generated creation api determines the id returned after inserting records.
+type InsertIDStrategy int
+
+const (
+	// LastInsertID uses the sql.Result LastInsertId of the insert statement, which is the id of the last row created.
+	LastInsertID InsertIDStrategy = iota
+
+	// FirstInsertID uses the sql.Result LastInsertId of the insert statement, which is the id of the first row created by
+	// multi-row inserts. The id of the last row is derived from it.
+	FirstInsertID
+
+	// ReturningInsertID appends a RETURNING clause for the record's primary key to the insert statement and scans the
+	// returned rows. Records using dialects with this strategy are required to have a primaryKey defined.
+	ReturningInsertID
+)
+
+// Dialect implementations are responsible for the portions of the generated sql that differ between database engines.
+type Dialect interface {
+	// QuoteIdentifier returns the table or column name wrapped with the dialect's identifier quoting characters.
+	QuoteIdentifier(string) string
+
+	// Placeholder receives a golang expression evaluating to the 1-based position of a value in the query and returns the
+	// format string used to render that value's placeholder along with the argument for it. Dialects whose placeholders
+	// do not depend on the value position (e.g "?") return an empty argument.
+	Placeholder(string) (string, string)
+
+	// PatternMatch returns the clause used to match the column reference against a pattern. The placeholder for the
+	// pattern value is written into the clause where the "%s" verb appears.
+	PatternMatch(string) string
+
+	// NotNull returns the clause matching rows where the column reference holds a value.
+	NotNull(string) string
+
+	// LimitOffset returns the format string appended to lookup queries, receiving the limit and offset integers.
+	LimitOffset() string
+
+	// InsertID returns the strategy used by the creation api to determine the id of the records it creates.
+	InsertID() InsertIDStrategy
+}
+
+// UpsertDialect is implemented by dialects able to overwrite conflicting rows while inserting them. The upsert api is
+// omitted from the stores of records whose dialect does not implement it.
+type UpsertDialect interface {
+	// Upsert returns the clause appended to insert statements that overwrites the rows conflicting on the (quoted) columns
+	// provided. The comma separated assignments of the overwritten columns are written where the "%s" verb appears and
+	// the clause is expected to return the primary key of every affected row. Dialects without support for this return an
+	// error, causing the upsert api to be omitted from their stores. An empty clause has the upsert api emulate it; every
+	// record is looked up by its conflicting columns and then updated or inserted within a transaction.
+	Upsert(conflict []string, primaryKey string) (string, error)
+
+	// UpsertAssignment returns the assignment that overwrites the column of a conflicting row with the value that was being
+	// inserted for it.
+	UpsertAssignment(string) string
+}
+
+// SchemaDialect is implemented by dialects able to define the columns of CREATE TABLE statements. Compiling the schema
+// of records whose dialect does not implement it fails.
+type SchemaDialect interface {
+	// ColumnType returns the type used by the CREATE TABLE statements of records for columns holding the golang type
+	// provided. Serial columns are the integer columns flagged autoIncrement, whose values are assigned by the database.
+	// Types the dialect is unable to store return an error.
+	ColumnType(fieldType string, serial bool) (string, error)
+}
+
+// VerificationDialect is implemented by dialects able to list the columns of live tables. The schema verification
+// functions are omitted for records whose dialect does not implement it.
+type VerificationDialect interface {
+	// TableColumns returns the statement listing the columns of the table provided, used to verify the schema of the live
+	// database. Its rows are expected to hold (at least) the "name", "type" and "notnull" (as a boolean) of each column.
+	TableColumns(table string) string
+}
+
+// DefaultDialect is the name of the dialect used by records that do not specify one.
+const DefaultDialect = "sqlite"
+
+var dialects = struct {
+	sync.RWMutex
+	registry map[string]Dialect
+}{
+	registry: map[string]Dialect{
+		"sqlite":   sqliteDialect{},
+		"postgres": postgresDialect{},
+		"mysql":    mysqlDialect{},
+	},
+}
+
+// RegisterDialect makes a dialect available to records compiled afterwards under the name provided, which is the value
+// used in a record's `dialect` config option.
+func RegisterDialect(name string, dialect Dialect) error {
+	if name == "" || dialect == nil {
+		return fmt.Errorf("dialects must be registered with a name and an implementation")
+	}
+
+	dialects.Lock()
+	defer dialects.Unlock()
+
+	if _, dupe := dialects.registry[name]; dupe {
+		return fmt.Errorf("duplicate dialect registration for \"%s\"", name)
+	}
+
+	dialects.registry[name] = dialect
+	return nil
+}
+
+func lookupDialect(name string) (Dialect, bool) {
+	if name == "" {
+		name = DefaultDialect
+	}
+
+	dialects.RLock()
+	defer dialects.RUnlock()
+	dialect, ok := dialects.registry[name]
+	return dialect, ok
+}
+
+type sqliteDialect struct {
+}
+
+func (d sqliteDialect) QuoteIdentifier(identifier string) string {
+	return identifier
+}
+
+func (d sqliteDialect) Placeholder(string) (string, string) {
+	return "?", ""
+}
+
+func (d sqliteDialect) PatternMatch(column string) string {
+	return fmt.Sprintf("%s LIKE %%s", column)
+}
+
+func (d sqliteDialect) NotNull(column string) string {
+	return fmt.Sprintf("%s IS NOT NULL", column)
+}
+
+func (d sqliteDialect) LimitOffset() string {
+	return " LIMIT %d OFFSET %d"
+}
+
+func (d sqliteDialect) InsertID() InsertIDStrategy {
+	return LastInsertID
+}
+
+// Upsert for sqlite is emulated; older sqlite versions lack the upsert (3.24.0) and returning (3.35.0) syntax.
+func (d sqliteDialect) Upsert([]string, string) (string, error) {
+	return "", nil
+}
+
+func (d sqliteDialect) UpsertAssignment(column string) string {
+	return fmt.Sprintf("%s = excluded.%s", column, column)
+}
+
+func (d sqliteDialect) TableColumns(table string) string {
+	return fmt.Sprintf("PRAGMA table_info(%s)", table)
+}
+
+// ColumnType for sqlite relies on the rowid aliasing of INTEGER PRIMARY KEY columns for serial primary keys.
+func (d sqliteDialect) ColumnType(fieldType string, serial bool) (string, error) {
+	return lookupColumnType(sqliteColumnTypes, fieldType)
+}
+
+type postgresDialect struct {
+	sqliteDialect
+}
+
+func (d postgresDialect) Placeholder(position string) (string, string) {
+	return "$%d", position
+}
+
+func (d postgresDialect) InsertID() InsertIDStrategy {
+	return ReturningInsertID
+}
+
+func (d postgresDialect) Upsert(conflict []string, primaryKey string) (string, error) {
+	template := "ON CONFLICT (%s) DO UPDATE SET %%s RETURNING %s"
+	return fmt.Sprintf(template, strings.Join(conflict, ","), primaryKey), nil
+}
+
+func (d postgresDialect) TableColumns(table string) string {
+	template := "SELECT column_name AS name, data_type AS type, is_nullable = 'NO' AS notnull " +
+		"FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = '%s'"
+	return fmt.Sprintf(template, table)
+}
+
+func (d postgresDialect) ColumnType(fieldType string, serial bool) (string, error) {
+	columnType, e := lookupColumnType(postgresColumnTypes, fieldType)
+
+	if serial && e == nil {
+		return lookupColumnType(postgresSerialTypes, columnType)
+	}
+
+	return columnType, e
+}
+
+// mysqlDialect uses the plain LIKE operator; MySQL does not support ILIKE and its LIKE is already case-insensitive under
+// the default collations.
+type mysqlDialect struct {
+	sqliteDialect
+}
+
+func (d mysqlDialect) QuoteIdentifier(identifier string) string {
+	return fmt.Sprintf("`%s`", identifier)
+}
+
+func (d mysqlDialect) InsertID() InsertIDStrategy {
+	return FirstInsertID
+}
+
+func (d mysqlDialect) TableColumns(table string) string {
+	template := "SELECT column_name AS name, column_type AS type, is_nullable = 'NO' AS `notnull` " +
+		"FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = '%s'"
+	return fmt.Sprintf(template, table)
+}
+
+func (d mysqlDialect) ColumnType(fieldType string, serial bool) (string, error) {
+	columnType, e := lookupColumnType(mysqlColumnTypes, fieldType)
+
+	if serial && e == nil {
+		return fmt.Sprintf("%s AUTO_INCREMENT", columnType), nil
+	}
+
+	return columnType, e
+}
+
+// Upsert is unsupported for mysql; while it does support `ON DUPLICATE KEY UPDATE`, there is no way to return the ids of
+// every row affected by it.
+func (d mysqlDialect) Upsert([]string, string) (string, error) {
+	return "", fmt.Errorf("mysql upserts are unable to return the ids of affected rows")
+}
+
+var sqliteColumnTypes = map[string]string{
+	"bool":      "BOOLEAN",
+	"string":    "TEXT",
+	"float32":   "REAL",
+	"float64":   "REAL",
+	"time.Time": "DATETIME",
+	"int":       "INTEGER",
+	"int8":      "INTEGER",
+	"int16":     "INTEGER",
+	"int32":     "INTEGER",
+	"int64":     "INTEGER",
+	"uint":      "INTEGER",
+	"uint8":     "INTEGER",
+	"uint16":    "INTEGER",
+	"uint32":    "INTEGER",
+	"uint64":    "INTEGER",
+	"byte":      "INTEGER",
+	"rune":      "INTEGER",
+}
+
+var postgresColumnTypes = map[string]string{
+	"bool":      "BOOLEAN",
+	"string":    "TEXT",
+	"float32":   "REAL",
+	"float64":   "DOUBLE PRECISION",
+	"time.Time": "TIMESTAMP WITH TIME ZONE",
+	"int":       "BIGINT",
+	"int8":      "SMALLINT",
+	"int16":     "SMALLINT",
+	"int32":     "INTEGER",
+	"int64":     "BIGINT",
+	"uint":      "BIGINT",
+	"uint8":     "SMALLINT",
+	"uint16":    "INTEGER",
+	"uint32":    "BIGINT",
+	"uint64":    "BIGINT",
+	"byte":      "SMALLINT",
+	"rune":      "INTEGER",
+}
+
+// postgresSerialTypes holds the serial pseudo-types of the postgres integer column types.
+var postgresSerialTypes = map[string]string{
+	"SMALLINT": "SMALLSERIAL",
+	"INTEGER":  "SERIAL",
+	"BIGINT":   "BIGSERIAL",
+}
+
+var mysqlColumnTypes = map[string]string{
+	"bool":      "BOOLEAN",
+	"string":    "VARCHAR(255)",
+	"float32":   "FLOAT",
+	"float64":   "DOUBLE",
+	"time.Time": "DATETIME",
+	"int":       "BIGINT",
+	"int8":      "TINYINT",
+	"int16":     "SMALLINT",
+	"int32":     "INT",
+	"int64":     "BIGINT",
+	"uint":      "BIGINT UNSIGNED",
+	"uint8":     "TINYINT UNSIGNED",
+	"uint16":    "SMALLINT UNSIGNED",
+	"uint32":    "INT UNSIGNED",
+	"uint64":    "BIGINT UNSIGNED",
+	"byte":      "TINYINT UNSIGNED",
+	"rune":      "INT",
+}
+
+func lookupColumnType(columnTypes map[string]string, fieldType string) (string, error) {
+	held := fieldType
+
+	// Nullable types are held by the column type of the value they hold.
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		held = nullable.underlying
+	}
+
+	columnType, ok := columnTypes[held]
+
+	if !ok {
+		return "", fmt.Errorf("no column type for %s", fieldType)
+	}
+
+	return columnType, nil
+}
@@ -0,0 +1,164 @@
+package marlow
+
+import "fmt"
+import "bytes"
+import "strings"
+import "testing"
+import "github.com/franela/goblin"
+
+type testDialect struct {
+	sqliteDialect
+}
+
+func (d testDialect) Placeholder(position string) (string, string) {
+	return ":p%d", position
+}
+
+func (d testDialect) LimitOffset() string {
+	return " OFFSET %[2]d ROWS FETCH NEXT %[1]d ROWS ONLY"
+}
+
+func (d testDialect) PatternMatch(column string) string {
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(%%s)", column)
+}
+
+// minimalDialect implements the Dialect interface alone, without any of the optional dialect interfaces.
+type minimalDialect struct {
+}
+
+func (d minimalDialect) QuoteIdentifier(identifier string) string {
+	return identifier
+}
+
+func (d minimalDialect) Placeholder(string) (string, string) {
+	return "?", ""
+}
+
+func (d minimalDialect) PatternMatch(column string) string {
+	return fmt.Sprintf("%s LIKE %%s", column)
+}
+
+func (d minimalDialect) NotNull(column string) string {
+	return fmt.Sprintf("%s IS NOT NULL", column)
+}
+
+func (d minimalDialect) LimitOffset() string {
+	return " LIMIT %d OFFSET %d"
+}
+
+func (d minimalDialect) InsertID() InsertIDStrategy {
+	return LastInsertID
+}
+
+func Test_Dialect(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	g.Describe("RegisterDialect", func() {
+		g.It("returns an error if the name is empty", func() {
+			g.Assert(RegisterDialect("", testDialect{}) == nil).Equal(false)
+		})
+
+		g.It("returns an error if the dialect is nil", func() {
+			g.Assert(RegisterDialect("dialect-test-nil", nil) == nil).Equal(false)
+		})
+
+		g.It("returns an error if the name has already been registered", func() {
+			g.Assert(RegisterDialect("postgres", testDialect{}) == nil).Equal(false)
+		})
+	})
+
+	g.Describe("Compile with a registered dialect", func() {
+		var output *bytes.Buffer
+
+		g.Before(func() {
+			g.Assert(RegisterDialect("dialect-test", testDialect{})).Equal(nil)
+		})
+
+		g.BeforeEach(func() {
+			output = new(bytes.Buffer)
+		})
+
+		g.It("uses the dialect for records that name it in their config", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				table  bool   ` + "`marlow:\"tableName=books&dialect=dialect-test\"`" + `
+				ID     uint   ` + "`marlow:\"column=id&autoIncrement=true\"`" + `
+				Title  string ` + "`marlow:\"column=title\"`" + `
+			}
+			`)
+
+			g.Assert(Compile(output, source)).Equal(nil)
+			generated := output.String()
+			g.Assert(strings.Contains(generated, `fmt.Sprintf("LOWER(books.title) LIKE LOWER(:p%d)", _count+_i)`)).Equal(true)
+			g.Assert(strings.Contains(generated, `fmt.Sprintf("(books.id > :p%d AND books.id < :p%d)", _count, _count+1)`)).Equal(true)
+			g.Assert(strings.Contains(generated, `" OFFSET %[2]d ROWS FETCH NEXT %[1]d ROWS ONLY"`)).Equal(true)
+		})
+
+		g.Describe("that does not implement the optional dialect interfaces", func() {
+			var source string
+
+			g.Before(func() {
+				g.Assert(RegisterDialect("dialect-minimal", minimalDialect{})).Equal(nil)
+			})
+
+			g.BeforeEach(func() {
+				source = `
+				package marlowt
+
+				type Book struct {
+					table  bool   ` + "`marlow:\"tableName=books&dialect=dialect-minimal&primaryKey=id\"`" + `
+					ID     uint   ` + "`marlow:\"column=id&autoIncrement=true\"`" + `
+					Title  string ` + "`marlow:\"column=title&unique=true\"`" + `
+				}
+				`
+			})
+
+			g.It("omits the upsert and schema verification apis", func() {
+				g.Assert(Compile(output, strings.NewReader(source))).Equal(nil)
+				generated := output.String()
+				g.Assert(strings.Contains(generated, "upserts unsupported: dialect does not implement UpsertDialect")).Equal(true)
+				g.Assert(strings.Contains(generated, "UpsertBooks(")).Equal(false)
+				g.Assert(strings.Contains(generated, "func VerifyBookSchema")).Equal(false)
+			})
+
+			g.It("fails to compile the schema of the record", func() {
+				e := CompileSchema(output, "dialect-minimal", strings.NewReader(source))
+				g.Assert(strings.Contains(e.Error(), "does not define column types")).Equal(true)
+			})
+		})
+
+		g.It("fails if the record names a dialect that has not been registered", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				table  bool   ` + "`marlow:\"tableName=books&dialect=dialect-missing\"`" + `
+				Title  string ` + "`marlow:\"column=title\"`" + `
+			}
+			`)
+
+			g.Assert(Compile(output, source) == nil).Equal(false)
+		})
+	})
+
+	g.Describe("marlowRecord placeholders", func() {
+		var record *marlowRecord
+
+		g.BeforeEach(func() {
+			record = &marlowRecord{config: newRecordConfig("Book")}
+		})
+
+		g.It("returns a string literal for dialects with positional placeholders", func() {
+			g.Assert(record.placeholders("(a > %s AND a < %s)", "_c", "_c+1")).Equal(`"(a > ? AND a < ?)"`)
+			g.Assert(record.numberedPlaceholders()).Equal(false)
+		})
+
+		g.It("returns a formatted string for dialects with numbered placeholders", func() {
+			record.config.Set("dialect", "postgres")
+			g.Assert(record.placeholders("(a > %s AND a < %s)", "_c", "_c+1")).Equal(`fmt.Sprintf("(a > $%d AND a < $%d)", _c, _c+1)`)
+			g.Assert(record.numberedPlaceholders()).Equal(true)
+		})
+	})
+}
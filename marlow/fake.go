@@ -0,0 +1,964 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "regexp"
+import "strings"
+import "net/url"
+import "go/ast"
+import "go/types"
+import "io/ioutil"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+const (
+	// fakeStorePrefix is prepended to the name of a record's store to produce the name of its in-memory fake.
+	fakeStorePrefix = "Fake"
+
+	// fakePostgresDialect is the dialect whose LIKE operator is case sensitive, matched as such by the fakes.
+	fakePostgresDialect = "postgres"
+)
+
+// fakeComparison holds the templates comparing two values of a field type within the in-memory fakes, each receiving
+// the expression of the value being compared followed by the expression of the value it is compared against.
+type fakeComparison struct {
+	less    string
+	greater string
+	equal   string
+}
+
+var fakeComparisons = map[string]fakeComparison{
+	"bool":      {less: "!%[1]s && %[2]s", greater: "%[1]s && !%[2]s", equal: "%[1]s == %[2]s"},
+	"time.Time": {less: "%[1]s.Before(%[2]s)", greater: "%[1]s.After(%[2]s)", equal: "%[1]s.Equal(%[2]s)"},
+}
+
+// fakeSignatureImport matches the package qualifiers of the types held by store method signatures.
+var fakeSignatureImport = regexp.MustCompile("([A-Za-z_][A-Za-z0-9_]*)\\.")
+
+// lookupFakeComparison returns the comparison of values of the field type provided. Nullable values are ordered before
+// every valid value, the same way NULLs are ordered by the databases supported.
+func lookupFakeComparison(fieldType string) (fakeComparison, bool) {
+	if comparison, ok := fakeComparisons[fieldType]; ok {
+		return comparison, true
+	}
+
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		inner, _ := lookupFakeComparison(nullable.underlying)
+		left, right := fmt.Sprintf("%%[1]s.%s", nullable.member), fmt.Sprintf("%%[2]s.%s", nullable.member)
+
+		return fakeComparison{
+			less: fmt.Sprintf(
+				"(%%[1]s.Valid == false && %%[2]s.Valid) || (%%[1]s.Valid && %%[2]s.Valid && %s)",
+				fmt.Sprintf(inner.less, left, right),
+			),
+			greater: fmt.Sprintf(
+				"(%%[1]s.Valid && %%[2]s.Valid == false) || (%%[1]s.Valid && %%[2]s.Valid && %s)",
+				fmt.Sprintf(inner.greater, left, right),
+			),
+			equal: fmt.Sprintf(
+				"%%[1]s.Valid == %%[2]s.Valid && (%%[1]s.Valid == false || %s)",
+				fmt.Sprintf(inner.equal, left, right),
+			),
+		}, true
+	}
+
+	if getTypeInfo(fieldType)&(types.IsNumeric|types.IsString) != 0 {
+		return fakeComparison{less: "%[1]s < %[2]s", greater: "%[1]s > %[2]s", equal: "%[1]s == %[2]s"}, true
+	}
+
+	return fakeComparison{}, false
+}
+
+// fakeZeroValue returns the expression of the zero value of the type provided.
+func fakeZeroValue(typeName string) string {
+	for _, prefix := range []string{"*", "[]", "map[", "func("} {
+		if strings.HasPrefix(typeName, prefix) {
+			return writing.Nil
+		}
+	}
+
+	for _, t := range constants.NumericCustomTypes {
+		if t == typeName {
+			return fmt.Sprintf("%s{}", typeName)
+		}
+	}
+
+	switch info := getTypeInfo(typeName); {
+	case typeName == "error":
+		return writing.Nil
+	case info&types.IsString != 0:
+		return writing.EmptyString
+	case info&types.IsBoolean != 0:
+		return "false"
+	case info&types.IsNumeric != 0:
+		return "0"
+	}
+
+	return fmt.Sprintf("%s{}", typeName)
+}
+
+// fakeNames holds the names of the types making up the in-memory fake of a record's store.
+type fakeNames struct {
+	store string
+	table string
+	row   string
+}
+
+// newFakeReader returns a reader that generates the in-memory fake of the store generated for a struct declaration.
+// The registry holds the records of the declaration's package that fields may reference.
+func newFakeReader(root ast.Decl, imports chan<- string, registry recordRegistry) (io.Reader, bool) {
+	record, ok, e := loadRecord(root, registry)
+
+	if !ok {
+		return nil, false
+	}
+
+	pr, pw := io.Pipe()
+
+	if e != nil {
+		pw.CloseWithError(e)
+		return pr, true
+	}
+
+	go func() {
+		methods, e := storeMethods(record)
+
+		if e == nil && len(methods) > 0 {
+			record.importChannel = imports
+			e = writeFake(pw, record, methods)
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr, true
+}
+
+// storeMethods returns the methods of the store generated for the record by generating its api without writing it
+// anywhere. Records without any features enabled have no store, and no methods.
+func storeMethods(record marlowRecord) (map[string]writing.FuncDecl, error) {
+	discarded := make(chan string)
+
+	go func() {
+		for range discarded {
+		}
+	}()
+
+	record.importChannel, record.storeChannel = discarded, make(chan writing.FuncDecl)
+	readers := recordFeatures(record)
+
+	if len(readers) == 0 {
+		close(discarded)
+		return nil, nil
+	}
+
+	methods, e := collectFeatures(ioutil.Discard, record, readers)
+
+	if e != nil {
+		return nil, e
+	}
+
+	// The store adds the executor, clock and transaction methods to those registered by the features.
+	e = writeStore(ioutil.Discard, record, methods)
+	close(discarded)
+	return methods, e
+}
+
+// writeFake writes the in-memory fake of the record's store, implementing each of the store methods provided.
+func writeFake(writer io.Writer, record marlowRecord, methods map[string]writing.FuncDecl) error {
+	gosrc := writing.NewGoWriter(writer)
+
+	// The fake is written as the store of a copy of the record, allowing the store api writers to be shared with it.
+	fake := record
+	fake.config = make(url.Values)
+
+	for key, values := range record.config {
+		fake.config[key] = values
+	}
+
+	fake.config.Set(constants.StoreNameConfigOption, fmt.Sprintf("%s%s", fakeStorePrefix, record.external()))
+
+	names := fakeNames{
+		store: fake.store(),
+		table: fmt.Sprintf("fake%sTable", record.name()),
+		row:   fmt.Sprintf("fake%sRow", record.name()),
+	}
+
+	writers := []func(writing.GoWriter, marlowRecord, fakeNames) error{
+		writeFakeTypes,
+		writeFakeConstructor,
+		writeFakeRows,
+		writeFakeLookup,
+		writeFakeMatches,
+		writeFakeOrdering,
+	}
+
+	for _, w := range writers {
+		if e := w(gosrc, record, names); e != nil {
+			return e
+		}
+	}
+
+	record.registerImports("fmt", "sort", "strings", "sync", "bytes", "regexp")
+
+	if record.stamped() {
+		record.registerImports("time")
+	}
+
+	return writeFakeMethods(gosrc, record, fake, methods)
+}
+
+// writeFakeTypes writes the fake store along with the table holding its rows, which is shared by the copies of the
+// store bound to other executors or clocks.
+func writeFakeTypes(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	constructor := fmt.Sprintf("New%s%s", fakeStorePrefix, record.external())
+	gosrc.Comment("%s is the in-memory %s returned by %s.", names.store, record.external(), constructor)
+
+	e := gosrc.WithStruct(names.store, func(url.Values) error {
+		gosrc.Println("table *%s", names.table)
+
+		if record.stamped() {
+			gosrc.Println("%s func() time.Time", constants.StoreClockField)
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return e
+	}
+
+	comment := "%s holds the rows of the in-memory %s along with the last value of its serial fields."
+	gosrc.Comment(comment, names.table, record.external())
+
+	e = gosrc.WithStruct(names.table, func(url.Values) error {
+		gosrc.Println("sync.Mutex")
+		gosrc.Println("rows []%s", names.row)
+
+		for _, serial := range fakeSerials(record) {
+			gosrc.Println("serial%s int64", serial.name)
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("%s is a single %s held by the in-memory %s.", names.row, record.name(), record.external())
+
+	return gosrc.WithStruct(names.row, func(url.Values) error {
+		gosrc.Println("record %s", record.name())
+		return gosrc.Println("deleted bool")
+	})
+}
+
+// fakeSerials returns the integer autoIncrement fields of the record, which are assigned values by the fake.
+func fakeSerials(record marlowRecord) fieldList {
+	return record.fieldList(func(config url.Values) bool {
+		return config.Get(constants.ColumnAutoIncrementFlag) != "" && getTypeInfo(config.Get("type"))&types.IsInteger != 0
+	})
+}
+
+// writeFakeConstructor writes the function returning a new fake store holding the records it receives.
+func writeFakeConstructor(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	name := fmt.Sprintf("New%s%s", fakeStorePrefix, record.external())
+
+	gosrc.Comment(
+		"%s returns a %s holding the records provided in memory, evaluating blueprints against them without a database.",
+		name,
+		record.external(),
+	)
+	gosrc.Comment("Records without a value for their autoIncrement fields are assigned the next value of the field.")
+
+	params := []writing.FuncParam{{Type: fmt.Sprintf("...%s", record.name()), Symbol: "_records"}}
+
+	return gosrc.WithFunc(name, params, []string{record.external()}, func(url.Values) error {
+		if record.stamped() {
+			gosrc.Println("_store := &%s{table: &%s{}, %s: time.Now}", names.store, names.table, constants.StoreClockField)
+		} else {
+			gosrc.Println("_store := &%s{table: &%s{}}", names.store, names.table)
+		}
+
+		gosrc.WithIter("_, _record := range _records", func(url.Values) error {
+			return gosrc.Println("_store.insert(_record)")
+		})
+
+		return gosrc.Returns("_store")
+	})
+}
+
+// writeFakeRows writes the methods adding and removing the rows of the fake's table.
+func writeFakeRows(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	gosrc.Comment("insert adds the record to the table, assigning its autoIncrement fields unless they hold a value.")
+
+	params := []writing.FuncParam{{Type: record.name(), Symbol: "_record"}}
+
+	e := gosrc.WithMethod("insert", names.store, params, []string{record.name()}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		for _, serial := range fakeSerials(record) {
+			value := fmt.Sprintf("_record.%s", serial.name)
+			counter := fmt.Sprintf("%s.table.serial%s", receiver, serial.name)
+
+			gosrc.WithIf("%s == 0", func(url.Values) error {
+				return gosrc.Println("%s = %s(%s + 1)", value, record.fields[serial.name].Get("type"), counter)
+			}, value)
+
+			gosrc.WithIf("int64(%s) > %s", func(url.Values) error {
+				return gosrc.Println("%s = int64(%s)", counter, value)
+			}, value, counter)
+		}
+
+		gosrc.Println("%s.table.rows = append(%s.table.rows, %s{record: _record})", receiver, receiver, names.row)
+		return gosrc.Returns("_record")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("remove drops the rows at the positions provided from the table, returning the amount removed.")
+
+	params = []writing.FuncParam{{Type: "[]int", Symbol: "_positions"}}
+
+	return gosrc.WithMethod("remove", names.store, params, []string{"int64"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		gosrc.Println("_removed := make(map[int]bool, len(_positions))")
+
+		gosrc.WithIter("_, _i := range _positions", func(url.Values) error {
+			return gosrc.Println("_removed[_i] = true")
+		})
+
+		gosrc.Println("_rows := make([]%s, 0, len(%s.table.rows))", names.row, receiver)
+
+		gosrc.WithIter("_i, _row := range %s.table.rows", func(url.Values) error {
+			return gosrc.WithIf("_removed[_i] == false", func(url.Values) error {
+				return gosrc.Println("_rows = append(_rows, _row)")
+			})
+		}, receiver)
+
+		gosrc.Println("%s.table.rows = _rows", receiver)
+		return gosrc.Returns("int64(len(_removed))")
+	})
+}
+
+// writeFakeLookup writes the method returning the positions of the rows matched by a blueprint, sorted by its order and
+// starting after its cursor.
+func writeFakeLookup(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	_, _, keyed := record.primaryKeyField()
+
+	gosrc.Comment("lookup returns the positions of the rows matched by the blueprint, in the order requested by it.")
+
+	params := []writing.FuncParam{{Type: fmt.Sprintf("*%s", record.blueprint()), Symbol: "_blueprint"}}
+
+	return gosrc.WithMethod("lookup", names.store, params, []string{"[]int", "error"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.WithIf("_blueprint == nil", func(url.Values) error {
+			return gosrc.Println("_blueprint = &%s{}", record.blueprint())
+		})
+
+		// Filters on linked records require the join records, which are held by another store.
+		for _, link := range record.links() {
+			gosrc.WithIf("_blueprint.%s != nil", func(url.Values) error {
+				message := fmt.Sprintf("%s lookups are not supported by the in-memory %s", link.filterField(), record.external())
+				return gosrc.Returns(writing.Nil, fmt.Sprintf("fmt.Errorf(%q)", message))
+			}, link.filterField())
+		}
+
+		gosrc.Println("_keys, _ke := _blueprint.%s()", blueprintOrderKeysMethod)
+
+		gosrc.WithIf("_ke != nil", func(url.Values) error {
+			return gosrc.Returns(writing.Nil, "_ke")
+		})
+
+		if keyed {
+			gosrc.Println("_, _after, _ce := _blueprint.%s()", blueprintCursorKeysMethod)
+
+			gosrc.WithIf("_ce != nil", func(url.Values) error {
+				return gosrc.Returns(writing.Nil, "_ce")
+			})
+		}
+
+		gosrc.Println("_matched := make([]int, 0, len(%s.table.rows))", receiver)
+
+		gosrc.WithIter("_i := range %s.table.rows", func(url.Values) error {
+			gosrc.Println("_row := &%s.table.rows[_i]", receiver)
+
+			gosrc.WithIf("%s.matches(_blueprint, _row) == false", func(url.Values) error {
+				return gosrc.Println("continue")
+			}, receiver)
+
+			if keyed {
+				gosrc.WithIf("len(_after) > 0 && %s.position(_keys, &_row.record, _after) <= 0", func(url.Values) error {
+					return gosrc.Println("continue")
+				}, receiver)
+			}
+
+			return gosrc.Println("_matched = append(_matched, _i)")
+		}, receiver)
+
+		gosrc.Println("sort.SliceStable(_matched, func(_a, _b int) bool {")
+		gosrc.Println("_other := %s.values(_keys, &%s.table.rows[_matched[_b]].record)", receiver, receiver)
+		gosrc.Returns(fmt.Sprintf("%s.position(_keys, &%s.table.rows[_matched[_a]].record, _other) < 0", receiver, receiver))
+		gosrc.Println("})")
+
+		return gosrc.Returns("_matched", writing.Nil)
+	})
+}
+
+// writeFakeMatches writes the method evaluating the filters of a blueprint against a single row, along with the LIKE
+// pattern matching used by the filters of string fields.
+func writeFakeMatches(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	gosrc.Comment("matches returns true if the row is matched by the filters of the blueprint, combined according to its")
+	gosrc.Comment("Inclusive flag.")
+
+	params := []writing.FuncParam{
+		{Type: fmt.Sprintf("*%s", record.blueprint()), Symbol: "_blueprint"},
+		{Type: fmt.Sprintf("*%s", names.row), Symbol: "_row"},
+	}
+
+	e := gosrc.WithMethod("matches", names.store, params, []string{"bool"}, func(scope url.Values) error {
+		if record.softDeleteColumn() != "" {
+			gosrc.WithIf("_blueprint.%s && _row.deleted == false", func(url.Values) error {
+				return gosrc.Returns("false")
+			}, blueprintOnlyDeletedField)
+
+			gosrc.WithIf("_blueprint.%s == false && _blueprint.%s == false && _row.deleted", func(url.Values) error {
+				return gosrc.Returns("false")
+			}, blueprintOnlyDeletedField, blueprintIncludeDeletedField)
+		}
+
+		gosrc.Println("_matched, _ := %s.conditions(_blueprint, _row)", scope.Get("receiver"))
+		return gosrc.Returns("_matched")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	if e := writeFakeConditions(gosrc, record, names); e != nil {
+		return e
+	}
+
+	gosrc.Comment("like returns true if the value matches the LIKE pattern, where a percent sign matches any sequence")
+	gosrc.Comment("of characters and an underscore matches any single character.")
+
+	params = []writing.FuncParam{{Type: "string", Symbol: "_pattern"}, {Type: "string", Symbol: "_value"}}
+
+	return gosrc.WithMethod("like", names.store, params, []string{"bool"}, func(url.Values) error {
+		flags := "(?is)"
+
+		if record.config.Get(constants.DialectConfigOption) == fakePostgresDialect {
+			flags = "(?s)"
+		}
+
+		gosrc.Println("_expression := bytes.NewBufferString(%q)", fmt.Sprintf("%s^", flags))
+
+		gosrc.WithIter("_, _character := range _pattern", func(url.Values) error {
+			gosrc.Println("switch _character {")
+			gosrc.Println("case '%%':")
+			gosrc.Println("_expression.WriteString(\".*\")")
+			gosrc.Println("case '_':")
+			gosrc.Println("_expression.WriteString(\".\")")
+			gosrc.Println("default:")
+			gosrc.Println("_expression.WriteString(regexp.QuoteMeta(string(_character)))")
+			return gosrc.Println("}")
+		})
+
+		gosrc.Println("_expression.WriteString(\"$\")")
+		gosrc.Println("_matched, _ := regexp.MatchString(_expression.String(), _value)")
+		return gosrc.Returns("_matched")
+	})
+}
+
+// writeFakeConditions writes the methods evaluating the field filters and groups of a blueprint against a row. Both
+// return whether the row was matched along with whether any filter was evaluated, since blueprints without filters
+// are left out of the groups holding them the same way their empty conditions are left out of the WHERE clause.
+func writeFakeConditions(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	gosrc.Comment("conditions evaluates the filters and groups of the blueprint against the row.")
+
+	params := []writing.FuncParam{
+		{Type: fmt.Sprintf("*%s", record.blueprint()), Symbol: "_blueprint"},
+		{Type: fmt.Sprintf("*%s", names.row), Symbol: "_row"},
+	}
+
+	returns := []string{"bool", "bool"}
+
+	e := gosrc.WithMethod("conditions", names.store, params, returns, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		gosrc.Println("_clauses := make([]bool, 0, %d)", len(record.fields)+2)
+
+		if filtered := record.fieldList(fakeFiltered); len(filtered) > 0 {
+			gosrc.Println("_record := &_row.record")
+
+			for _, field := range filtered {
+				writeFakeFilters(gosrc, record, receiver, field.name)
+			}
+		}
+
+		for _, group := range []struct{ field, inclusive string }{{blueprintAndField, "false"}, {blueprintOrField, "true"}} {
+			gosrc.WithIf("_matched, _ok := %s.group(_blueprint.%s, %s, _row); _ok", func(url.Values) error {
+				return gosrc.Println("_clauses = append(_clauses, _matched)")
+			}, receiver, group.field, group.inclusive)
+		}
+
+		return gosrc.Returns(fmt.Sprintf("%s.combine(_clauses, _blueprint.Inclusive)", receiver), "len(_clauses) > 0")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("group evaluates the conditions of the blueprints of a group against the row.")
+
+	params = []writing.FuncParam{
+		{Type: fmt.Sprintf("[]*%s", record.blueprint()), Symbol: "_group"},
+		{Type: "bool", Symbol: "_inclusive"},
+		params[1],
+	}
+
+	e = gosrc.WithMethod("group", names.store, params, returns, func(scope url.Values) error {
+		gosrc.Println("_clauses := make([]bool, 0, len(_group))")
+
+		gosrc.WithIter("_, _member := range _group", func(url.Values) error {
+			gosrc.WithIf("_member == nil", func(url.Values) error {
+				return gosrc.Println("continue")
+			})
+
+			return gosrc.WithIf("_matched, _ok := %s.conditions(_member, _row); _ok", func(url.Values) error {
+				return gosrc.Println("_clauses = append(_clauses, _matched)")
+			}, scope.Get("receiver"))
+		})
+
+		return gosrc.Returns(fmt.Sprintf("%s.combine(_clauses, _inclusive)", scope.Get("receiver")), "len(_clauses) > 0")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("combine joins the results of clauses using OR when inclusive and AND otherwise.")
+
+	params = []writing.FuncParam{{Type: "[]bool", Symbol: "_clauses"}, {Type: "bool", Symbol: "_inclusive"}}
+
+	return gosrc.WithMethod("combine", names.store, params, []string{"bool"}, func(url.Values) error {
+		gosrc.WithIter("_, _clause := range _clauses", func(url.Values) error {
+			return gosrc.WithIf("_clause == _inclusive", func(url.Values) error {
+				return gosrc.Returns("_clause")
+			})
+		})
+
+		return gosrc.Returns("len(_clauses) == 0 || _inclusive == false")
+	})
+}
+
+// fakeFiltered returns true if the blueprint of the record holds filters for the field.
+func fakeFiltered(config url.Values) bool {
+	fieldType := config.Get("type")
+	_, comparable := lookupFakeComparison(fieldType)
+	_, nullable := lookupNullableType(fieldType)
+	return (comparable && getTypeInfo(fieldType)&types.IsConstType != 0) || nullable
+}
+
+// writeFakeFilters writes the evaluation of the blueprint filters of a field against the record, adding the result of
+// each to the clauses of the row the same way the blueprint adds them to its WHERE clause. The range and LIKE filters
+// of nullable fields are evaluated against the value they hold, never matching records without one.
+func writeFakeFilters(gosrc writing.GoWriter, record marlowRecord, receiver, field string) error {
+	fieldType := record.fields[field].Get("type")
+	comparison, _ := lookupFakeComparison(fieldType)
+	value, guard := fmt.Sprintf("_record.%s", field), ""
+
+	notIn := fmt.Sprintf("%s%s", field, record.config.Get(constants.BlueprintNotInFieldSuffixConfigOption))
+
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		writeFakeNullableFilter(gosrc, record, field, comparison.equal)
+		fieldType, guard = nullable.underlying, fmt.Sprintf("%s.Valid && ", value)
+
+		// Like the NOT IN clause, the NOT IN filter of nullable fields never matches records without a value.
+		writeFakeInFilter(gosrc, notIn, value, comparison.equal, fmt.Sprintf("%s!", guard))
+		comparison, _ = lookupFakeComparison(fieldType)
+		value = fmt.Sprintf("%s.%s", value, nullable.member)
+	} else {
+		writeFakeInFilter(gosrc, field, value, comparison.equal, "")
+		writeFakeInFilter(gosrc, notIn, value, comparison.equal, "!")
+	}
+
+	typeInfo := getTypeInfo(fieldType)
+
+	if typeInfo&types.IsString != 0 {
+		writeFakeLikeFilters(gosrc, record, receiver, field, value, guard)
+	}
+
+	if typeInfo&types.IsNumeric == 0 {
+		return nil
+	}
+
+	bounds := fmt.Sprintf("_blueprint.%s%s", field, record.config.Get(constants.BlueprintRangeFieldSuffixConfigOption))
+
+	gosrc.WithIf("len(%s) == 2", func(url.Values) error {
+		lower := fmt.Sprintf(comparison.greater, value, fmt.Sprintf("%s[0]", bounds))
+		upper := fmt.Sprintf(comparison.less, value, fmt.Sprintf("%s[1]", bounds))
+		return gosrc.Println("_clauses = append(_clauses, %s(%s) && (%s))", guard, lower, upper)
+	}, bounds)
+
+	// Records fall outside of the bounds of NOT range filters when they are not strictly within them.
+	bounds = fmt.Sprintf("_blueprint.%s%s", field, record.config.Get(constants.BlueprintNotRangeFieldSuffixConfigOption))
+
+	return gosrc.WithIf("len(%s) == 2", func(url.Values) error {
+		lower := fmt.Sprintf(comparison.greater, value, fmt.Sprintf("%s[0]", bounds))
+		upper := fmt.Sprintf(comparison.less, value, fmt.Sprintf("%s[1]", bounds))
+		return gosrc.Println("_clauses = append(_clauses, %s(!(%s) || !(%s)))", guard, lower, upper)
+	}, bounds)
+}
+
+// writeFakeInFilter writes the evaluation of the IN filter held by the blueprint field provided, matching records
+// holding any of its values. The result is prefixed by the operator provided, which negates it for NOT IN filters.
+func writeFakeInFilter(gosrc writing.GoWriter, lookup, value, equal, operator string) error {
+	return gosrc.WithIf("len(_blueprint.%s) > 0", func(url.Values) error {
+		gosrc.Println("_in := false")
+
+		gosrc.WithIter("_, _value := range _blueprint.%s", func(url.Values) error {
+			return gosrc.Println("_in = _in || %s", fmt.Sprintf(equal, value, "_value"))
+		}, lookup)
+
+		return gosrc.Println("_clauses = append(_clauses, %s_in)", operator)
+	}, lookup)
+}
+
+// writeFakeLikeFilters writes the evaluation of the LIKE and NOT LIKE filters of a string field. Each LIKE pattern is
+// a clause of its own while the NOT LIKE patterns make up a single clause, the same way the blueprint joins them.
+func writeFakeLikeFilters(gosrc writing.GoWriter, record marlowRecord, receiver, field, value, guard string) error {
+	like := fmt.Sprintf("_blueprint.%s%s", field, record.config.Get(constants.BlueprintLikeFieldSuffixConfigOption))
+
+	gosrc.WithIter("_, _pattern := range %s", func(url.Values) error {
+		return gosrc.Println("_clauses = append(_clauses, %s%s.like(_pattern, %s))", guard, receiver, value)
+	}, like)
+
+	unlike := fmt.Sprintf("_blueprint.%s%s", field, record.config.Get(constants.BlueprintNotLikeFieldSuffixConfigOption))
+
+	return gosrc.WithIf("len(%s) > 0", func(url.Values) error {
+		gosrc.Println("_unlike := %strue", guard)
+
+		gosrc.WithIter("_, _pattern := range %s", func(url.Values) error {
+			return gosrc.Println("_unlike = _unlike && !%s.like(_pattern, %s)", receiver, value)
+		}, unlike)
+
+		return gosrc.Println("_clauses = append(_clauses, _unlike)")
+	}, unlike)
+}
+
+// writeFakeNullableFilter writes the evaluation of the IN and null filters of a nullable field; an empty IN filter
+// matches the records holding a value while one holding an invalid value only matches the records without one.
+func writeFakeNullableFilter(gosrc writing.GoWriter, record marlowRecord, field, equal string) error {
+	suffix := record.config.Get(constants.BlueprintNullFieldSuffixConfigOption)
+	null := fmt.Sprintf("_blueprint.%s%s", field, suffix)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Println("_clauses = append(_clauses, *%s == (_record.%s.Valid == false))", null, field)
+	}, null)
+
+	return gosrc.WithIf("_blueprint.%s != nil", func(url.Values) error {
+		gosrc.Println("_in := len(_blueprint.%s) == 0 && _record.%s.Valid", field, field)
+
+		gosrc.WithIter("_, _value := range _blueprint.%s", func(url.Values) error {
+			gosrc.WithIf("_value.Valid == false", func(url.Values) error {
+				gosrc.Println("_in = _record.%s.Valid == false", field)
+				return gosrc.Println("break")
+			})
+
+			return gosrc.Println("_in = _in || %s", fmt.Sprintf(equal, "_value", fmt.Sprintf("_record.%s", field)))
+		}, field)
+
+		return gosrc.Println("_clauses = append(_clauses, _in)")
+	}, field)
+}
+
+// writeFakeOrdering writes the methods ordering the records of the fake by the keys produced by blueprints, along with
+// the method producing the bounds of a page of results.
+func writeFakeOrdering(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	fields := record.fieldList(func(config url.Values) bool {
+		_, comparable := lookupFakeComparison(config.Get("type"))
+		return comparable
+	})
+
+	gosrc.Comment("compare returns the order of the record's column value relative to the value provided.")
+
+	params := []writing.FuncParam{
+		{Type: fmt.Sprintf("*%s", record.name()), Symbol: "_record"},
+		{Type: "string", Symbol: "_column"},
+		{Type: "interface{}", Symbol: "_value"},
+	}
+
+	e := gosrc.WithMethod("compare", names.store, params, []string{"int"}, func(url.Values) error {
+		gosrc.Println("switch _column {")
+
+		for _, field := range fields {
+			config := record.fields[field.name]
+			comparison, _ := lookupFakeComparison(config.Get("type"))
+			value := fmt.Sprintf("_record.%s", field.name)
+
+			if fieldImport := config.Get("import"); fieldImport != "" {
+				record.registerImports(fieldImport)
+			}
+
+			gosrc.Println("case \"%s\":", field.column)
+			gosrc.Println("_other, _ := _value.(%s)", config.Get("type"))
+
+			gosrc.WithIf(fmt.Sprintf(comparison.less, value, "_other"), func(url.Values) error {
+				return gosrc.Returns("-1")
+			})
+
+			gosrc.WithIf(fmt.Sprintf(comparison.greater, value, "_other"), func(url.Values) error {
+				return gosrc.Returns("1")
+			})
+		}
+
+		gosrc.Println("}")
+		return gosrc.Returns("0")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("values returns the values of the record's columns held by the order keys provided.")
+
+	params = []writing.FuncParam{{Type: "[]string", Symbol: "_keys"}, params[0]}
+
+	e = gosrc.WithMethod("values", names.store, params, []string{"[]interface{}"}, func(url.Values) error {
+		gosrc.Println("_values := make([]interface{}, 0, len(_keys))")
+
+		gosrc.WithIter("_, _key := range _keys", func(url.Values) error {
+			gosrc.Println("switch strings.Fields(_key)[0] {")
+
+			for _, field := range record.fieldList(nil) {
+				gosrc.Println("case \"%s\":", field.column)
+				gosrc.Println("_values = append(_values, _record.%s)", field.name)
+			}
+
+			gosrc.Println("default:")
+			gosrc.Println("_values = append(_values, nil)")
+			return gosrc.Println("}")
+		})
+
+		return gosrc.Returns("_values")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	return writeFakePositions(gosrc, record, names)
+}
+
+// writeFakePositions writes the methods positioning a record relative to the values of order keys and the bounds of a
+// page within a list of results.
+func writeFakePositions(gosrc writing.GoWriter, record marlowRecord, names fakeNames) error {
+	gosrc.Comment("position returns a negative number if the record is ordered before the values of the order keys")
+	gosrc.Comment("provided, a positive number if it is ordered after them and zero otherwise.")
+
+	params := []writing.FuncParam{
+		{Type: "[]string", Symbol: "_keys"},
+		{Type: fmt.Sprintf("*%s", record.name()), Symbol: "_record"},
+		{Type: "[]interface{}", Symbol: "_values"},
+	}
+
+	e := gosrc.WithMethod("position", names.store, params, []string{"int"}, func(scope url.Values) error {
+		gosrc.WithIter("_i, _key := range _keys", func(url.Values) error {
+			gosrc.Println("_parts := strings.Fields(_key)")
+			gosrc.Println("_order := %s.compare(_record, _parts[0], _values[_i])", scope.Get("receiver"))
+
+			gosrc.WithIf("len(_parts) == 2 && _parts[1] == \"DESC\"", func(url.Values) error {
+				return gosrc.Println("_order = -_order")
+			})
+
+			return gosrc.WithIf("_order != 0", func(url.Values) error {
+				return gosrc.Returns("_order")
+			})
+		})
+
+		return gosrc.Returns("0")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("page returns the bounds of the page of results starting at the offset and holding at most limit")
+	gosrc.Comment("results, every remaining result being held when the limit is not positive.")
+
+	params = []writing.FuncParam{
+		{Type: "int", Symbol: "_count"},
+		{Type: "int", Symbol: "_limit"},
+		{Type: "int", Symbol: "_offset"},
+	}
+
+	return gosrc.WithMethod("page", names.store, params, []string{"int", "int"}, func(url.Values) error {
+		gosrc.WithIf("_offset > _count", func(url.Values) error {
+			return gosrc.Println("_offset = _count")
+		})
+
+		gosrc.WithIf("_limit < 1 || _offset+_limit > _count", func(url.Values) error {
+			return gosrc.Returns("_offset", "_count")
+		})
+
+		return gosrc.Returns("_offset", "_offset + _limit")
+	})
+}
+
+// writeFakeMethods writes each of the store methods provided on the fake, using the in-memory implementation of the
+// method when there is one and otherwise returning an error for the unsupported method.
+func writeFakeMethods(gosrc writing.GoWriter, record, fake marlowRecord, methods map[string]writing.FuncDecl) error {
+	bodies := fakeBodies(gosrc, record)
+	names := make([]string, 0, len(methods))
+
+	for name := range methods {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		method := methods[name]
+
+		// Context-aware methods are written alongside the method they are the counterpart of.
+		if base := strings.TrimSuffix(name, contextMethodSuffix); base != name {
+			if _, paired := methods[base]; paired {
+				continue
+			}
+		}
+
+		block, ok := bodies[name]
+
+		if !ok {
+			block = fakeUnsupportedBlock(gosrc, record, method)
+		}
+
+		for _, t := range append(method.Returns, fakeParamTypes(method)...) {
+			registerTypeImports(record, t)
+		}
+
+		if _, contextual := methods[name+contextMethodSuffix]; contextual != true {
+			if e := gosrc.WithMethod(name, fake.store(), method.Params, method.Returns, block); e != nil {
+				return e
+			}
+
+			continue
+		}
+
+		if _, e := writeContextVariants(gosrc, fake, method, block); e != nil {
+			return e
+		}
+
+		record.registerImports("context")
+	}
+
+	return nil
+}
+
+func fakeParamTypes(method writing.FuncDecl) []string {
+	paramTypes := make([]string, 0, len(method.Params))
+
+	for _, p := range method.Params {
+		paramTypes = append(paramTypes, p.Type)
+	}
+
+	return paramTypes
+}
+
+// registerTypeImports registers the imports of the packages qualifying the type provided.
+func registerTypeImports(record marlowRecord, typeName string) {
+	standard := map[string]string{"sql": "database/sql", "context": "context", "time": "time"}
+
+	for _, match := range fakeSignatureImport.FindAllStringSubmatch(typeName, -1) {
+		if path, ok := standard[match[1]]; ok {
+			record.registerImports(path)
+			continue
+		}
+
+		record.registerImports(match[1])
+	}
+}
+
+// fakeUnsupportedBlock returns the body of store methods that the in-memory fake is unable to implement, returning the
+// zero values of the method along with an error.
+func fakeUnsupportedBlock(gosrc writing.GoWriter, record marlowRecord, method writing.FuncDecl) writing.Block {
+	return func(url.Values) error {
+		message := fmt.Sprintf("%s is not supported by the in-memory %s", method.Name, record.external())
+		values := make([]string, 0, len(method.Returns))
+
+		for _, t := range method.Returns {
+			if t == "error" {
+				values = append(values, fmt.Sprintf("fmt.Errorf(%q)", message))
+				continue
+			}
+
+			values = append(values, fakeZeroValue(t))
+		}
+
+		return gosrc.Returns(values...)
+	}
+}
+
+// fakeBodies returns the in-memory implementations of the store methods generated for the record by name.
+func fakeBodies(gosrc writing.GoWriter, record marlowRecord) map[string]writing.Block {
+	plural := inflector.Pluralize(record.name())
+	prefixes := struct {
+		find   string
+		count  string
+		update string
+		sel    string
+	}{
+		record.config.Get(constants.StoreFindMethodPrefixConfigOption),
+		record.config.Get(constants.StoreCountMethodPrefixConfigOption),
+		record.config.Get(constants.UpdateFieldMethodPrefixConfigOption),
+		record.config.Get(constants.StoreSelectMethodPrefixConfigOption),
+	}
+
+	bodies := map[string]writing.Block{
+		prefixes.find + plural:               fakeFinderBlock(gosrc, record),
+		prefixes.count + plural:              fakeCounterBlock(gosrc, record),
+		fmt.Sprintf("Create%s", plural):      fakeCreateBlock(gosrc, record),
+		fmt.Sprintf("Delete%s", plural):      fakeDeleteBlock(gosrc, record),
+		fmt.Sprintf("Restore%s", plural):     fakeRestoreBlock(gosrc, record),
+		fmt.Sprintf("Purge%s", plural):       fakePurgeBlock(gosrc, record),
+		"Transaction":                        fakeTransactionBlock(gosrc, record),
+		"WithExecutor":                       fakeExecutorBlock(gosrc),
+		"WithClock":                          fakeClockBlock(gosrc),
+		fmt.Sprintf("Save%s", record.name()): fakeSaveBlock(gosrc, record),
+	}
+
+	if _, _, keyed := record.primaryKeyField(); keyed {
+		bodies[fmt.Sprintf("Get%s", record.name())] = getterBlock(gosrc, record)
+		bodies[fmt.Sprintf("Each%s", plural)] = walkerBlock(gosrc, record)
+	}
+
+	for name, config := range record.fields {
+		selector := fmt.Sprintf("%s%s%s", prefixes.sel, record.name(), inflector.Pluralize(name))
+		bodies[selector] = fakeSelectorBlock(gosrc, record, name)
+		bodies[fmt.Sprintf("%s%s%s", prefixes.update, record.name(), name)] = fakeUpdaterBlock(gosrc, record, name, "=")
+
+		if _, bit := config[constants.ColumnBitmaskOption]; bit {
+			bodies[fmt.Sprintf("Add%s%s", record.name(), name)] = fakeUpdaterBlock(gosrc, record, name, "|=")
+			bodies[fmt.Sprintf("Drop%s%s", record.name(), name)] = fakeUpdaterBlock(gosrc, record, name, "&^=")
+		}
+
+		if _, comparable := lookupFakeComparison(config.Get("type")); comparable {
+			bodies[fmt.Sprintf("%s%sBy%s", prefixes.count, plural, name)] = fakeGroupCounterBlock(gosrc, record, name)
+		}
+
+		if resultType, ok := aggregateType(config.Get("type")); ok {
+			for _, prefix := range []string{"Sum", "Avg", "Min", "Max"} {
+				aggregate := fmt.Sprintf("%s%s%s", prefix, record.name(), name)
+				bodies[aggregate] = fakeAggregateBlock(gosrc, record, name, prefix, resultType)
+			}
+		}
+	}
+
+	return bodies
+}
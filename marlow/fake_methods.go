@@ -0,0 +1,497 @@
+package marlow
+
+import "fmt"
+import "net/url"
+import "go/types"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+// writeFakeLock writes the lock of the fake's table, held until the method returns.
+func writeFakeLock(gosrc writing.GoWriter, receiver string) {
+	gosrc.Println("%s.table.Lock()", receiver)
+	gosrc.Println("defer %s.table.Unlock()", receiver)
+}
+
+// writeFakeMatched writes the lookup of the positions of the rows matched by the blueprint, returning the zero values
+// provided along with the error of the lookup.
+func writeFakeMatched(gosrc writing.GoWriter, receiver string, zeros ...string) error {
+	writeFakeLock(gosrc, receiver)
+	gosrc.Println("_matched, _le := %s.lookup(_blueprint)", receiver)
+
+	return gosrc.WithIf("_le != nil", func(url.Values) error {
+		return gosrc.Returns(append(zeros, "_le")...)
+	})
+}
+
+// writeFakePage writes the bounds of the page of matched rows requested by the blueprint, using the record's default
+// limit when the blueprint has none.
+func writeFakePage(gosrc writing.GoWriter, record marlowRecord, receiver string) {
+	limit := record.config.Get(constants.DefaultLimitConfigOption)
+	gosrc.Println("_limit, _offset := %s, _blueprint.%s()", limit, blueprintOffsetMethod)
+
+	gosrc.WithIf("_blueprint != nil && _blueprint.Limit >= 1", func(url.Values) error {
+		return gosrc.Println("_limit = _blueprint.Limit")
+	})
+
+	gosrc.Println("_start, _end := %s.page(len(_matched), _limit, _offset)", receiver)
+}
+
+// fakeFinderBlock returns the body of the fake's finder, producing the cursor of the next page the same way the store
+// does.
+func fakeFinderBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	symbols := finderSymbols{
+		blueprint:   "_blueprint",
+		results:     "_results",
+		limit:       "_limit",
+		nextCursor:  "_next",
+		cursorError: "_ce",
+	}
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		writeFakeMatched(gosrc, receiver, writing.Nil, writing.EmptyString)
+		writeFakePage(gosrc, record, receiver)
+
+		gosrc.Println("_results := make([]*%s, 0, _end-_start)", record.name())
+
+		gosrc.WithIter("_, _i := range _matched[_start:_end]", func(url.Values) error {
+			gosrc.Println("_row := %s.table.rows[_i].record", receiver)
+
+			writeHookCall(gosrc, record, afterFindHook, "_row", func(hookError string) error {
+				return gosrc.Returns(writing.Nil, writing.EmptyString, hookError)
+			})
+
+			return gosrc.Println("_results = append(_results, &_row)")
+		})
+
+		return writeNextCursor(gosrc, record, symbols)
+	}
+}
+
+// fakeCounterBlock returns the body of the fake's counter.
+func fakeCounterBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	return func(scope url.Values) error {
+		writeFakeMatched(gosrc, scope.Get("receiver"), "-1")
+		return gosrc.Returns("len(_matched)", writing.Nil)
+	}
+}
+
+// fakeSelectorBlock returns the body of the fake's selector for a field.
+func fakeSelectorBlock(gosrc writing.GoWriter, record marlowRecord, field string) writing.Block {
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		writeFakeMatched(gosrc, receiver, writing.Nil)
+		writeFakePage(gosrc, record, receiver)
+
+		gosrc.Println("_results := make([]%s, 0, _end-_start)", record.fields[field].Get("type"))
+
+		gosrc.WithIter("_, _i := range _matched[_start:_end]", func(url.Values) error {
+			return gosrc.Println("_results = append(_results, %s.table.rows[_i].record.%s)", receiver, field)
+		})
+
+		return gosrc.Returns("_results", writing.Nil)
+	}
+}
+
+// fakeAggregateBlock returns the body of the fake's aggregate of a field, skipping the NULL values of nullable fields
+// the same way the sql aggregate functions do.
+func fakeAggregateBlock(gosrc writing.GoWriter, record marlowRecord, field, prefix, resultType string) writing.Block {
+	fieldType := record.fields[field].Get("type")
+	member, _ := lookupNullableType(resultType)
+	value := fmt.Sprintf("%s(_record.%s)", member.underlying, field)
+
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		value = fmt.Sprintf("_record.%s.%s", field, nullable.member)
+	}
+
+	if prefix == "Avg" {
+		resultType = "sql.NullFloat64"
+	}
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		writeFakeMatched(gosrc, receiver, fmt.Sprintf("%s{}", resultType))
+
+		gosrc.Println("var _result %s", resultType)
+		gosrc.Println("_total, _count := 0.0, 0")
+
+		gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+			gosrc.Println("_record := &%s.table.rows[_i].record", receiver)
+
+			if _, nullable := lookupNullableType(fieldType); nullable {
+				gosrc.WithIf("_record.%s.Valid == false", func(url.Values) error {
+					return gosrc.Println("continue")
+				}, field)
+			}
+
+			gosrc.Println("_value := %s", value)
+
+			switch prefix {
+			case "Sum":
+				gosrc.Println("_result.%s += _value", member.member)
+			case "Min", "Max":
+				operator := map[string]string{"Min": "<", "Max": ">"}[prefix]
+
+				gosrc.WithIf("_result.Valid == false || _value %s _result.%s", func(url.Values) error {
+					return gosrc.Println("_result.%s = _value", member.member)
+				}, operator, member.member)
+			}
+
+			gosrc.Println("_total, _count = _total+float64(_value), _count+1")
+			return gosrc.Println("_result.Valid = true")
+		})
+
+		if prefix == "Avg" {
+			gosrc.WithIf("_count > 0", func(url.Values) error {
+				return gosrc.Println("_result.Float64 = _total / float64(_count)")
+			})
+		}
+
+		return gosrc.Returns("_result", writing.Nil)
+	}
+}
+
+// fakeGroupCounterBlock returns the body of the fake's group counter for a field, ordering the groups by their value.
+func fakeGroupCounterBlock(gosrc writing.GoWriter, record marlowRecord, field string) writing.Block {
+	comparison, _ := lookupFakeComparison(record.fields[field].Get("type"))
+	pairType := fmt.Sprintf("%s%sCount", record.name(), field)
+	group, tally := fmt.Sprintf("_results[_g].%s", field), groupTally(field)
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		writeFakeMatched(gosrc, receiver, writing.Nil)
+
+		gosrc.Println("_results := make([]%s, 0)", pairType)
+
+		gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+			gosrc.Println("_value, _found := %s.table.rows[_i].record.%s, false", receiver, field)
+
+			gosrc.WithIter("_g := range _results", func(url.Values) error {
+				return gosrc.WithIf(fmt.Sprintf(comparison.equal, group, "_value"), func(url.Values) error {
+					gosrc.Println("_results[_g].%s, _found = _results[_g].%s+1, true", tally, tally)
+					return gosrc.Println("break")
+				})
+			})
+
+			return gosrc.WithIf("_found == false", func(url.Values) error {
+				return gosrc.Println("_results = append(_results, %s{%s: _value, %s: 1})", pairType, field, tally)
+			})
+		})
+
+		gosrc.Println("sort.SliceStable(_results, func(_a, _b int) bool {")
+		left, right := fmt.Sprintf("_results[_a].%s", field), fmt.Sprintf("_results[_b].%s", field)
+		gosrc.Returns(fmt.Sprintf(comparison.less, left, right))
+		gosrc.Println("})")
+
+		gosrc.WithIf("_blueprint != nil && (_blueprint.Limit >= 1 || _blueprint.Offset >= 1)", func(url.Values) error {
+			gosrc.Println("_start, _end := %s.page(len(_results), _blueprint.Limit, _blueprint.Offset)", receiver)
+			return gosrc.Println("_results = _results[_start:_end]")
+		})
+
+		return gosrc.Returns("_results", writing.Nil)
+	}
+}
+
+// fakeCreateBlock returns the body of the fake's creation api, returning the primary key of the last record created.
+// The records of tables without an integer primary key are identified by their position instead, like a sqlite rowid.
+func fakeCreateBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	primaryKey, primaryConfig, keyed := record.primaryKeyField()
+	keyed = keyed && getTypeInfo(primaryConfig.Get("type"))&types.IsInteger != 0
+	stamps := record.timestampFields(constants.ColumnAutoCreateTimeFlag, constants.ColumnAutoUpdateTimeFlag)
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.WithIf("len(_records) == 0", func(url.Values) error {
+			return gosrc.Returns("0", writing.Nil)
+		})
+
+		writeHookLoop(gosrc, record, beforeCreateHook, "_records", "-1")
+		writeRecordValidations(gosrc, record, "_records", "-1")
+		writeFakeLock(gosrc, receiver)
+
+		if len(stamps) > 0 {
+			writeClockRead(gosrc, receiver, "_now")
+		}
+
+		gosrc.Println("_last := int64(0)")
+
+		gosrc.WithIter("_, _record := range _records", func(url.Values) error {
+			// The values of the autoIncrement fields are never inserted, leaving the table to assign them.
+			for _, serial := range fakeSerials(record) {
+				gosrc.Println("_record.%s = 0", serial.name)
+			}
+
+			for _, stamp := range stamps {
+				gosrc.Println("_record.%s = _now", stamp.name)
+			}
+
+			if keyed {
+				return gosrc.Println("_last = int64(%s.insert(_record).%s)", receiver, primaryKey)
+			}
+
+			gosrc.Println("%s.insert(_record)", receiver)
+			return gosrc.Println("_last = int64(len(%s.table.rows))", receiver)
+		})
+
+		writeHookLoop(gosrc, record, afterCreateHook, "_records", "-1")
+		return gosrc.Returns("_last", writing.Nil)
+	}
+}
+
+// fakeUpdaterBlock returns the body of the fake's updater of a field, applying the assignment operator provided with
+// the value given to the updater.
+func fakeUpdaterBlock(gosrc writing.GoWriter, record marlowRecord, field, operator string) writing.Block {
+	config := record.fields[field]
+	params, versioned := updaterParams(record, config, updaterSymbols{valueParam: "_updates", version: "_version"})
+	versionField, _, _ := record.versionField()
+
+	// Updates of the record stamp every update timestamp field other than the one being updated.
+	stamps := record.fieldList(func(stamp url.Values) bool {
+		column := stamp.Get(constants.ColumnConfigOption)
+		return stamp.Get(constants.ColumnAutoUpdateTimeFlag) != "" && column != config.Get(constants.ColumnConfigOption)
+	})
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		if operator == "=" {
+			writeUpdateValidation(gosrc, record, config, "_updates")
+		}
+
+		writeFakeMatched(gosrc, receiver, "-1")
+
+		if record.hasHook(beforeUpdateHook) {
+			gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+				gosrc.Println("_hooked := %s.table.rows[_i].record", receiver)
+
+				return writeHookCall(gosrc, record, beforeUpdateHook, "_hooked", func(hookError string) error {
+					return gosrc.Returns("-1", hookError)
+				})
+			})
+		}
+
+		if len(stamps) > 0 {
+			writeClockRead(gosrc, receiver, "_now")
+		}
+
+		gosrc.Println("_rowCount := int64(0)")
+
+		gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+			gosrc.Println("_record := &%s.table.rows[_i].record", receiver)
+
+			if versioned {
+				gosrc.WithIf("_record.%s != _version", func(url.Values) error {
+					return gosrc.Println("continue")
+				}, versionField)
+			}
+
+			// Nullable values are received by reference, the nil reference being assigned as NULL.
+			if params[0].Type != config.Get("type") {
+				gosrc.Println("_record.%s = %s{}", field, config.Get("type"))
+
+				gosrc.WithIf("_updates != nil", func(url.Values) error {
+					return gosrc.Println("_record.%s = *_updates", field)
+				})
+			} else {
+				gosrc.Println("_record.%s %s _updates", field, operator)
+			}
+
+			for _, stamp := range stamps {
+				gosrc.Println("_record.%s = _now", stamp.name)
+			}
+
+			if versioned {
+				gosrc.Println("_record.%s++", versionField)
+			}
+
+			return gosrc.Println("_rowCount++")
+		})
+
+		if versioned {
+			writeVersionConflict(gosrc, record, "_rowCount", "_version")
+		}
+
+		return gosrc.Returns("_rowCount", writing.Nil)
+	}
+}
+
+// fakeSaveBlock returns the body of the fake's whole-record save, which leaves the fields managed by the table as they
+// are held by the row being saved.
+func fakeSaveBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	primaryKey, _, _ := record.primaryKeyField()
+	versionField, _, versioned := record.versionField()
+	condition := fmt.Sprintf("_row.%s != _record.%s", primaryKey, primaryKey)
+
+	if versioned {
+		condition = fmt.Sprintf("%s || _row.%s != _record.%s", condition, versionField, versionField)
+	}
+
+	managed := record.fieldList(func(config url.Values) bool {
+		primary := config.Get(constants.ColumnConfigOption) == record.primaryKeyColumn()
+		return primary == false && config.Get(constants.ColumnAutoIncrementFlag) != ""
+	})
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.WithIf("_record == nil", func(url.Values) error {
+			return gosrc.Returns("-1", fmt.Sprintf("fmt.Errorf(\"unable to save nil %s\")", record.name()))
+		})
+
+		writeHookCall(gosrc, record, beforeUpdateHook, "_record", func(hookError string) error {
+			return gosrc.Returns("-1", hookError)
+		})
+
+		if record.validated() {
+			gosrc.Println("%s := _record.Validate()", validationSymbol)
+			writeValidationFailure(gosrc, record, validationSymbol, "-1")
+		}
+
+		writeFakeLock(gosrc, receiver)
+
+		if stamps := record.timestampFields(constants.ColumnAutoUpdateTimeFlag); len(stamps) > 0 {
+			writeClockRead(gosrc, receiver, "_now")
+
+			for _, stamp := range stamps {
+				gosrc.Println("_record.%s = _now", stamp.name)
+			}
+		}
+
+		gosrc.Println("_rowCount := int64(0)")
+
+		gosrc.WithIter("_i := range %s.table.rows", func(url.Values) error {
+			gosrc.Println("_row := &%s.table.rows[_i].record", receiver)
+
+			gosrc.WithIf(condition, func(url.Values) error {
+				return gosrc.Println("continue")
+			})
+
+			gosrc.Println("_saved := *_record")
+
+			for _, field := range managed {
+				gosrc.Println("_saved.%s = _row.%s", field.name, field.name)
+			}
+
+			if versioned {
+				gosrc.Println("_saved.%s++", versionField)
+			}
+
+			gosrc.Println("*_row = _saved")
+			return gosrc.Println("_rowCount++")
+		}, receiver)
+
+		if versioned {
+			writeVersionConflict(gosrc, record, "_rowCount", fmt.Sprintf("_record.%s", versionField))
+			gosrc.Println("_record.%s++", versionField)
+		}
+
+		return gosrc.Returns("_rowCount", writing.Nil)
+	}
+}
+
+// fakeDeleteBlock returns the body of the fake's deletion api, which marks the rows of records configured with soft
+// deletes as deleted and removes the rows of all others.
+func fakeDeleteBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	symbols := deleteableSymbols{blueprint: "_blueprint", unscoped: "_unscoped", scoped: "_scoped"}
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		writeDeletionGuard(gosrc, record, symbols)
+		writeFakeMatched(gosrc, receiver, "-1")
+
+		if record.hasHook(beforeDeleteHook) {
+			gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+				gosrc.Println("_record := %s.table.rows[_i].record", receiver)
+
+				return writeHookCall(gosrc, record, beforeDeleteHook, "_record", func(hookError string) error {
+					return gosrc.Returns("-1", hookError)
+				})
+			})
+		}
+
+		if record.softDeleteColumn() == "" {
+			return gosrc.Returns(fmt.Sprintf("%s.remove(_matched)", receiver), writing.Nil)
+		}
+
+		gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+			return gosrc.Println("%s.table.rows[_i].deleted = true", receiver)
+		})
+
+		return gosrc.Returns("int64(len(_matched))", writing.Nil)
+	}
+}
+
+// fakeRestoreBlock returns the body of the fake's restoration of soft deleted records.
+func fakeRestoreBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	symbols := deleteableSymbols{blueprint: "_blueprint", unscoped: "_unscoped", scoped: "_scoped"}
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		writeDeletionGuard(gosrc, record, symbols)
+		writeDeletedScope(gosrc, record, symbols)
+		writeFakeMatched(gosrc, receiver, "-1")
+
+		gosrc.WithIter("_, _i := range _matched", func(url.Values) error {
+			return gosrc.Println("%s.table.rows[_i].deleted = false", receiver)
+		})
+
+		return gosrc.Returns("int64(len(_matched))", writing.Nil)
+	}
+}
+
+// fakePurgeBlock returns the body of the fake's removal of soft deleted records.
+func fakePurgeBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	symbols := deleteableSymbols{blueprint: "_blueprint", unscoped: "_unscoped", scoped: "_scoped"}
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		writeDeletionGuard(gosrc, record, symbols)
+		writeDeletedScope(gosrc, record, symbols)
+		writeFakeMatched(gosrc, receiver, "-1")
+
+		return gosrc.Returns(fmt.Sprintf("%s.remove(_matched)", receiver), writing.Nil)
+	}
+}
+
+// fakeTransactionBlock returns the body of the fake's transaction helper, which hands the callback the fake itself and
+// restores the rows held before the callback when it fails. The executor given to the callback is nil.
+func fakeTransactionBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	row := fmt.Sprintf("fake%sRow", record.name())
+
+	return func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.Println("%s.table.Lock()", receiver)
+		gosrc.Println("_snapshot := append([]%s(nil), %s.table.rows...)", row, receiver)
+		gosrc.Println("%s.table.Unlock()", receiver)
+
+		gosrc.WithIf("_fe := _fn(%s, nil); _fe != nil", func(url.Values) error {
+			gosrc.Println("%s.table.Lock()", receiver)
+			gosrc.Println("%s.table.rows = _snapshot", receiver)
+			gosrc.Println("%s.table.Unlock()", receiver)
+			return gosrc.Returns("_fe")
+		}, receiver)
+
+		return gosrc.Returns(writing.Nil)
+	}
+}
+
+// fakeExecutorBlock returns the body of the fake's executor binding; the fake has no use for executors.
+func fakeExecutorBlock(gosrc writing.GoWriter) writing.Block {
+	return func(scope url.Values) error {
+		return gosrc.Returns(scope.Get("receiver"))
+	}
+}
+
+// fakeClockBlock returns the body of the fake's clock binding, sharing its table with the copy using the clock.
+func fakeClockBlock(gosrc writing.GoWriter) writing.Block {
+	return func(scope url.Values) error {
+		gosrc.Println("_copy := *%s", scope.Get("receiver"))
+		gosrc.Println("_copy.%s = _clock", constants.StoreClockField)
+		return gosrc.Returns("&_copy")
+	}
+}
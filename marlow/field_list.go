@@ -2,9 +2,12 @@ package marlow
 
 import "strings"
 
+// field holds the name of a record field along with the quoted, table-qualified reference to its column and the bare
+// (unquoted) name of the column itself.
 type field struct {
 	name   string
 	column string
+	bare   string
 }
 
 type fieldList []field
@@ -0,0 +1,209 @@
+package marlow
+
+import "io"
+import "fmt"
+import "strconv"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type groupCounterSymbols struct {
+	blueprint       string
+	queryString     string
+	statementResult string
+	statementError  string
+	queryResult     string
+	queryError      string
+	results         string
+	rowItem         string
+	scanError       string
+	limit           string
+	offset          string
+}
+
+const (
+	// groupTallyField is the field of the group counter pairs holding the number of records sharing a value.
+	groupTallyField = "Total"
+
+	// groupTallyFallback is the tally field of pairs grouping a field that is itself named after groupTallyField.
+	groupTallyFallback = "Count"
+)
+
+// groupTally returns the name of the field holding the number of records in the pairs of a group counter, which differs
+// from the name of the field being grouped.
+func groupTally(fieldName string) string {
+	if fieldName == groupTallyField {
+		return groupTallyFallback
+	}
+
+	return groupTallyField
+}
+
+// groupCounter returns a generator that writes the store method counting the records matched by a blueprint for each
+// distinct value of a field, along with the type holding every (value, count) pair. The pairs are returned in order of
+// the field's column; the blueprint's Limit and Offset apply to the pairs when provided. Every primary key value is held
+// by a single record, so no group counter is generated for the primary key field.
+func groupCounter(record marlowRecord, fieldName string, fieldConfig url.Values) io.Reader {
+	pr, pw := io.Pipe()
+
+	if column := fieldConfig.Get(constants.ColumnConfigOption); column != "" && column == record.primaryKeyColumn() {
+		pw.Close()
+		return pr
+	}
+
+	methodName := fmt.Sprintf(
+		"%s%sBy%s",
+		record.config.Get(constants.StoreCountMethodPrefixConfigOption),
+		inflector.Pluralize(record.name()),
+		fieldName,
+	)
+
+	pairType, tally := fmt.Sprintf("%s%sCount", record.name(), fieldName), groupTally(fieldName)
+	columnReference := record.columnReference(fieldConfig.Get(constants.ColumnConfigOption))
+
+	symbols := groupCounterSymbols{
+		blueprint:       "_blueprint",
+		queryString:     "_queryString",
+		statementResult: "_statement",
+		statementError:  "_se",
+		queryResult:     "_queryResult",
+		queryError:      "_qe",
+		results:         "_results",
+		rowItem:         "_row",
+		scanError:       "_re",
+		limit:           "_limit",
+		offset:          "_offset",
+	}
+
+	params := []writing.FuncParam{
+		{Symbol: symbols.blueprint, Type: fmt.Sprintf("*%s", record.blueprint())},
+	}
+
+	returns := []string{fmt.Sprintf("[]%s", pairType), "error"}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		gosrc.Comment("%s holds the number of %s sharing a %s value.", pairType, record.table(), fieldName)
+
+		e := gosrc.WithStruct(pairType, func(url.Values) error {
+			gosrc.Println("%s %s", fieldName, fieldConfig.Get("type"))
+			return gosrc.Println("%s int", tally)
+		})
+
+		if e != nil {
+			pw.CloseWithError(e)
+			return
+		}
+
+		gosrc.Comment("[marlow feature]: group counter by %s on table[%s]", fieldName, record.table())
+
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e = writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
+
+			gosrc.WithIf("%s == nil", func(url.Values) error {
+				return gosrc.Println("%s = &%s{}", symbols.blueprint, record.blueprint())
+			}, symbols.blueprint)
+
+			writeCursorCheck(gosrc, record, symbols.blueprint, writing.Nil)
+
+			gosrc.Println(
+				"%s := bytes.NewBufferString(\"SELECT %s, COUNT(*) FROM %s\")",
+				symbols.queryString,
+				columnReference,
+				record.quote(record.table()),
+			)
+
+			gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", symbols.queryString, symbols.blueprint)
+			grouping := fmt.Sprintf(" GROUP BY %s ORDER BY %s", columnReference, columnReference)
+			gosrc.Println("%s.WriteString(%q)", symbols.queryString, grouping)
+
+			gosrc.WithIf("%s.Limit >= 1 || %s.Offset >= 1", func(url.Values) error {
+				limits := "%s, %s := int64(math.MaxInt64), int64(%s.Offset)"
+				gosrc.Println(limits, symbols.limit, symbols.offset, symbols.blueprint)
+
+				gosrc.WithIf("%s.Limit >= 1", func(url.Values) error {
+					return gosrc.Println("%s = int64(%s.Limit)", symbols.limit, symbols.blueprint)
+				}, symbols.blueprint)
+
+				return gosrc.Println(
+					"fmt.Fprintf(%s, %s, %s, %s)",
+					symbols.queryString,
+					strconv.Quote(record.dialect().LimitOffset()),
+					symbols.limit,
+					symbols.offset,
+				)
+			}, symbols.blueprint, symbols.blueprint)
+
+			logwriter.AddLog(symbols.queryString, fmt.Sprintf("%s.Values()", symbols.blueprint))
+
+			gosrc.Println(
+				"%s, %s := %s.PrepareContext(%s, %s.String())",
+				symbols.statementResult,
+				symbols.statementError,
+				scope.Get("receiver"),
+				contextSymbol,
+				symbols.queryString,
+			)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				return gosrc.Returns(writing.Nil, symbols.statementError)
+			}, symbols.statementError)
+
+			gosrc.Println("defer %s.Close()", symbols.statementResult)
+
+			gosrc.Println(
+				"%s, %s := %s.QueryContext(%s, %s.Values()...)",
+				symbols.queryResult,
+				symbols.queryError,
+				symbols.statementResult,
+				contextSymbol,
+				symbols.blueprint,
+			)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				return gosrc.Returns(writing.Nil, symbols.queryError)
+			}, symbols.queryError)
+
+			gosrc.Println("defer %s.Close()", symbols.queryResult)
+			gosrc.Println("%s := make([]%s, 0)", symbols.results, pairType)
+
+			gosrc.WithIter("%s.Next()", func(url.Values) error {
+				gosrc.Println("var %s %s", symbols.rowItem, pairType)
+
+				gosrc.WithIf(
+					"%s := %s.Scan(&%s.%s, &%s.%s); %s != nil",
+					func(url.Values) error {
+						return gosrc.Returns(writing.Nil, symbols.scanError)
+					},
+					symbols.scanError,
+					symbols.queryResult,
+					symbols.rowItem,
+					fieldName,
+					symbols.rowItem,
+					tally,
+					symbols.scanError,
+				)
+
+				return gosrc.Println("%s = append(%s, %s)", symbols.results, symbols.results, symbols.rowItem)
+			}, symbols.queryResult)
+
+			gosrc.WithIf("%s := %s.Err(); %s != nil", func(url.Values) error {
+				return gosrc.Returns(writing.Nil, symbols.queryError)
+			}, symbols.queryError, symbols.queryResult, symbols.queryError)
+
+			return gosrc.Returns(symbols.results, writing.Nil)
+		})
+
+		if e == nil {
+			record.registerImports("fmt", "math", "bytes")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
@@ -0,0 +1,176 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sync"
+import "bytes"
+import "strings"
+import "testing"
+import "net/url"
+import "go/token"
+import "go/parser"
+import "github.com/franela/goblin"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type groupingTestScaffold struct {
+	buffer *bytes.Buffer
+
+	imports chan string
+	methods chan writing.FuncDecl
+
+	record url.Values
+	fields map[string]url.Values
+
+	received   map[string]bool
+	registered map[string]bool
+	closed     bool
+	wg         *sync.WaitGroup
+}
+
+func (s *groupingTestScaffold) close() {
+	if s == nil || s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.imports)
+	close(s.methods)
+	s.wg.Wait()
+}
+
+func (s *groupingTestScaffold) g(name string) io.Reader {
+	record := marlowRecord{
+		fields:        s.fields,
+		config:        s.record,
+		importChannel: s.imports,
+		storeChannel:  s.methods,
+	}
+
+	return groupCounter(record, name, s.fields[name])
+}
+
+func Test_Grouping(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	var scaffold *groupingTestScaffold
+
+	g.Describe("group counter feature generator test suite", func() {
+		g.BeforeEach(func() {
+			scaffold = &groupingTestScaffold{
+				buffer:     new(bytes.Buffer),
+				wg:         &sync.WaitGroup{},
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				record:     make(url.Values),
+				fields:     make(map[string]url.Values),
+				received:   make(map[string]bool),
+				registered: make(map[string]bool),
+			}
+
+			scaffold.wg.Add(2)
+
+			go func() {
+				for method := range scaffold.methods {
+					scaffold.registered[method.Name] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			go func() {
+				for i := range scaffold.imports {
+					scaffold.received[i] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			scaffold.record.Set(constants.RecordNameConfigOption, "Book")
+			scaffold.record.Set(constants.TableNameConfigOption, "books")
+			scaffold.record.Set(constants.StoreNameConfigOption, "BookStore")
+			scaffold.record.Set(constants.BlueprintNameConfigOption, "BookBlueprint")
+			scaffold.record.Set(constants.StoreCountMethodPrefixConfigOption, "Count")
+
+			scaffold.fields["AuthorID"] = url.Values{
+				"type":                       []string{"int"},
+				constants.ColumnConfigOption: []string{"author_id"},
+			}
+		})
+
+		g.AfterEach(func() {
+			scaffold.close()
+		})
+
+		g.It("generates valid golang", func() {
+			fmt.Fprintln(scaffold.buffer, "package marlowt")
+			_, e := io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			g.Assert(e).Equal(nil)
+			_, e = parser.ParseFile(token.NewFileSet(), "", scaffold.buffer, parser.AllErrors)
+			g.Assert(e).Equal(nil)
+		})
+
+		g.It("registers the group counter and its context-aware variant with the store", func() {
+			io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			scaffold.close()
+			g.Assert(scaffold.registered["CountBooksByAuthorID"]).Equal(true)
+			g.Assert(scaffold.registered["CountBooksByAuthorIDContext"]).Equal(true)
+			g.Assert(scaffold.received["math"]).Equal(true)
+		})
+
+		g.It("writes the type holding each value and its count", func() {
+			io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "type BookAuthorIDCount struct {\nAuthorID int\nTotal int\n}")).Equal(true)
+			g.Assert(strings.Contains(output, "_re := _queryResult.Scan(&_row.AuthorID, &_row.Total)")).Equal(true)
+		})
+
+		g.It("generates valid golang when grouping fields named after the tally of the pairs", func() {
+			scaffold.fields["Count"] = url.Values{
+				"type":                       []string{"int"},
+				constants.ColumnConfigOption: []string{"count"},
+			}
+
+			scaffold.fields["Total"] = url.Values{
+				"type":                       []string{"int"},
+				constants.ColumnConfigOption: []string{"total"},
+			}
+
+			fmt.Fprintln(scaffold.buffer, "package marlowt")
+			_, e := io.Copy(scaffold.buffer, io.MultiReader(scaffold.g("Count"), scaffold.g("Total")))
+			g.Assert(e).Equal(nil)
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "type BookCountCount struct {\nCount int\nTotal int\n}")).Equal(true)
+			g.Assert(strings.Contains(output, "type BookTotalCount struct {\nTotal int\nCount int\n}")).Equal(true)
+			_, e = parser.ParseFile(token.NewFileSet(), "", scaffold.buffer, parser.AllErrors)
+			g.Assert(e).Equal(nil)
+		})
+
+		g.It("groups the records matched by the blueprint by the field's column", func() {
+			io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "bytes.NewBufferString(\"SELECT books.author_id, COUNT(*) FROM books\")")).Equal(true)
+			g.Assert(strings.Contains(output, "fmt.Fprintf(_queryString, \" %s\", _blueprint)")).Equal(true)
+			g.Assert(strings.Contains(output, "_queryString.WriteString(\" GROUP BY books.author_id ORDER BY books.author_id\")")).Equal(true)
+		})
+
+		g.It("applies the blueprint limit and offset to the groups", func() {
+			io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "fmt.Fprintf(_queryString, \" LIMIT %d OFFSET %d\", _limit, _offset)")).Equal(true)
+		})
+
+		g.It("does not generate a group counter for the primary key", func() {
+			scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "author_id")
+			io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			scaffold.close()
+			g.Assert(scaffold.buffer.Len()).Equal(0)
+			g.Assert(len(scaffold.registered)).Equal(0)
+		})
+
+		g.It("quotes the column and table for mysql records", func() {
+			scaffold.record.Set(constants.DialectConfigOption, "mysql")
+			io.Copy(scaffold.buffer, scaffold.g("AuthorID"))
+			expected := "\"SELECT `books`.`author_id`, COUNT(*) FROM `books`\""
+			g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+		})
+	})
+}
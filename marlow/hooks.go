@@ -0,0 +1,187 @@
+package marlow
+
+import "fmt"
+import "sort"
+import "go/ast"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+const (
+	// beforeCreateHook is called on every record given to the creation api before it is inserted.
+	beforeCreateHook = "BeforeCreate"
+
+	// afterCreateHook is called on every record given to the creation api once it has been inserted and the ids of the
+	// rows read. The error of a failing hook is returned by the creation api but the records remain inserted.
+	afterCreateHook = "AfterCreate"
+
+	// beforeUpdateHook is called on the record given to the whole-record save method before it is written, and on every
+	// record matched by the blueprint of a field updater (as held before the update) before any are updated.
+	beforeUpdateHook = "BeforeUpdate"
+
+	// afterFindHook is called on every record scanned by lookups.
+	afterFindHook = "AfterFind"
+
+	// beforeDeleteHook is called on every record matched by the blueprint of the deletion api before it is deleted.
+	beforeDeleteHook = "BeforeDelete"
+
+	// hookErrorSymbol is the symbol holding the error returned by a lifecycle hook.
+	hookErrorSymbol = "_he"
+)
+
+// lifecycleHooks holds the names of the methods that, when declared on a record type, are called by its store.
+var lifecycleHooks = map[string]bool{
+	beforeCreateHook: true,
+	afterCreateHook:  true,
+	beforeUpdateHook: true,
+	afterFindHook:    true,
+	beforeDeleteHook: true,
+}
+
+// registerHooks adds the lifecycle hook methods declared in the source to the records of the registry they belong to.
+// Each hook is flagged with the validity of its signature, which is checked once the record is compiled.
+func registerHooks(registry recordRegistry, source *ast.File) {
+	for _, d := range source.Decls {
+		method, ok := d.(*ast.FuncDecl)
+
+		if !ok || method.Recv == nil || len(method.Recv.List) != 1 || lifecycleHooks[method.Name.Name] != true {
+			continue
+		}
+
+		receiver := method.Recv.List[0].Type
+
+		if pointer, ok := receiver.(*ast.StarExpr); ok {
+			receiver = pointer.X
+		}
+
+		ident, ok := receiver.(*ast.Ident)
+		record, registered := registry[ident.String()]
+
+		if !ok || !registered {
+			continue
+		}
+
+		if record.hooks == nil {
+			record.hooks = make(map[string]bool)
+		}
+
+		record.hooks[method.Name.Name] = validHookSignature(method.Type)
+		registry[ident.String()] = record
+	}
+}
+
+// validHookSignature returns true if the hook receives no parameters and returns a single error.
+func validHookSignature(signature *ast.FuncType) bool {
+	if signature.Params.NumFields() != 0 || signature.Results.NumFields() != 1 {
+		return false
+	}
+
+	result, ok := signature.Results.List[0].Type.(*ast.Ident)
+	return ok && result.Name == "error"
+}
+
+// validateHooks returns an error if any of the lifecycle hooks declared on the record are unable to be called by its
+// store, either because of their signature or because the store is unable to look up the records they are called on.
+func validateHooks(record marlowRecord) error {
+	names := make([]string, 0, len(record.hooks))
+
+	for name := range record.hooks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if record.hooks[name] != true {
+			return fmt.Errorf("lifecycle hook %s.%s must have the signature func() error", record.name(), name)
+		}
+	}
+
+	// The records matched by the blueprints of deletions and field updates are looked up to call their hooks.
+	matched := map[string]string{
+		beforeDeleteHook: constants.DeleteableConfigOption,
+		beforeUpdateHook: constants.UpdateableConfigOption,
+	}
+
+	queryable := record.config.Get(constants.QueryableConfigOption) != "false"
+
+	for _, name := range names {
+		feature, ok := matched[name]
+
+		if ok && record.config.Get(feature) != "false" && !queryable {
+			return fmt.Errorf("lifecycle hook %s.%s requires the record to be queryable", record.name(), name)
+		}
+	}
+
+	return nil
+}
+
+// hasHook returns true if the record declares the lifecycle hook method with a valid signature.
+func (r *marlowRecord) hasHook(name string) bool {
+	return r.hooks[name]
+}
+
+// writeHookCall writes the call of a lifecycle hook on the target provided, handing the symbol of its error to the
+// failure block when the hook returns one. Nothing is written if the record does not declare the hook.
+func writeHookCall(gosrc writing.GoWriter, record marlowRecord, hook, target string, failure func(string) error) error {
+	if record.hasHook(hook) != true {
+		return nil
+	}
+
+	return gosrc.WithIf("%s := %s.%s(); %s != nil", func(url.Values) error {
+		return failure(hookErrorSymbol)
+	}, hookErrorSymbol, target, hook, hookErrorSymbol)
+}
+
+// writeHookLoop writes the call of a lifecycle hook on each of the records held by the slice provided, returning the
+// zero values along with the error of the first hook that fails.
+func writeHookLoop(gosrc writing.GoWriter, record marlowRecord, hook, slice string, zeros ...string) error {
+	if record.hasHook(hook) != true {
+		return nil
+	}
+
+	return gosrc.WithIter("_i := range %s", func(url.Values) error {
+		return writeHookCall(gosrc, record, hook, fmt.Sprintf("%s[_i]", slice), func(hookError string) error {
+			return gosrc.Returns(append(zeros, hookError)...)
+		})
+	}, slice)
+}
+
+// writeMatchedHooks writes the iteration over the records matched by the blueprint of a deletion or field update,
+// calling the hook on each of them before any are written. The statements ignore the Limit and Offset of blueprints,
+// so the records are iterated with a copy of the blueprint without them. Nothing is written without the hook.
+func writeMatchedHooks(gosrc writing.GoWriter, record marlowRecord, hook, receiver, blueprint string) error {
+	if record.hasHook(hook) != true {
+		return nil
+	}
+
+	matched, cursor, cursorError := "_hooked", "_cursor", "_ce"
+	iterator := fmt.Sprintf("Iterate%s%s", inflector.Pluralize(record.name()), contextMethodSuffix)
+
+	gosrc.Println("var %s %s", matched, record.blueprint())
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Println("%s = *%s", matched, blueprint)
+	}, blueprint)
+
+	gosrc.Println("%s.Limit, %s.Offset = 0, 0", matched, matched)
+	gosrc.Println("%s, %s := %s.%s(%s, &%s)", cursor, cursorError, receiver, iterator, contextSymbol, matched)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns("-1", cursorError)
+	}, cursorError)
+
+	gosrc.WithIter("%s.Next()", func(url.Values) error {
+		return writeHookCall(gosrc, record, hook, fmt.Sprintf("%s.Record()", cursor), func(hookError string) error {
+			gosrc.Println("%s.Close()", cursor)
+			return gosrc.Returns("-1", hookError)
+		})
+	}, cursor)
+
+	gosrc.Println("%s.Close()", cursor)
+
+	return gosrc.WithIf("%s := %s.Err(); %s != nil", func(url.Values) error {
+		return gosrc.Returns("-1", cursorError)
+	}, cursorError, cursor, cursorError)
+}
@@ -0,0 +1,321 @@
+package marlow
+
+import "io"
+import "fmt"
+import "strconv"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type cursorSymbols struct {
+	rows      string
+	statement string
+	current   string
+	err       string
+	rowItem   string
+	scanError string
+}
+
+// writeCursor writes the cursor type returned by the iterator, scanning a single row into a record at a time.
+func writeCursor(gosrc writing.GoWriter, record marlowRecord) error {
+	name := record.cursor()
+	symbols := cursorSymbols{"rows", "statement", "current", "err", "_row", "_se"}
+
+	gosrc.Comment("%s iterates over the %s matched by a blueprint, scanning one row at a time.", name, record.table())
+
+	e := gosrc.WithStruct(name, func(url.Values) error {
+		gosrc.Println("%s *sql.Rows", symbols.rows)
+		gosrc.Println("%s *sql.Stmt", symbols.statement)
+		gosrc.Println("%s *%s", symbols.current, record.name())
+		return gosrc.Println("%s error", symbols.err)
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("Next scans the next row into the cursor's record, returning false when exhausted or on error.")
+	e = gosrc.WithMethod("Next", name, nil, []string{"bool"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.WithIf("%s.%s != nil || %s.%s.Next() != true", func(url.Values) error {
+			return gosrc.Returns("false")
+		}, receiver, symbols.err, receiver, symbols.rows)
+
+		gosrc.Println("var %s %s", symbols.rowItem, record.name())
+
+		scans := scanReferences(record, symbols.rowItem)
+
+		gosrc.WithIf("%s := %s.%s.Scan(%s); %s != nil", func(url.Values) error {
+			gosrc.Println("%s.%s = %s", receiver, symbols.err, symbols.scanError)
+			return gosrc.Returns("false")
+		}, symbols.scanError, receiver, symbols.rows, scans, symbols.scanError)
+
+		writeHookCall(gosrc, record, afterFindHook, symbols.rowItem, func(hookError string) error {
+			gosrc.Println("%s.%s = %s", receiver, symbols.err, hookError)
+			return gosrc.Returns("false")
+		})
+
+		gosrc.Println("%s.%s = &%s", receiver, symbols.current, symbols.rowItem)
+		return gosrc.Returns("true")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("Record returns the %s scanned by the most recent call to Next.", record.name())
+	returns := []string{fmt.Sprintf("*%s", record.name())}
+	e = gosrc.WithMethod("Record", name, nil, returns, func(scope url.Values) error {
+		return gosrc.Returns(fmt.Sprintf("%s.%s", scope.Get("receiver"), symbols.current))
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("Err returns the error, if any, that was encountered during iteration.")
+	e = gosrc.WithMethod("Err", name, nil, []string{"error"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.WithIf("%s.%s != nil", func(url.Values) error {
+			return gosrc.Returns(fmt.Sprintf("%s.%s", receiver, symbols.err))
+		}, receiver, symbols.err)
+
+		return gosrc.Returns(fmt.Sprintf("%s.%s.Err()", receiver, symbols.rows))
+	})
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.Comment("Close releases the rows and prepared statement held by the cursor.")
+	return gosrc.WithMethod("Close", name, nil, []string{"error"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		gosrc.Println("defer %s.%s.Close()", receiver, symbols.statement)
+		return gosrc.Returns(fmt.Sprintf("%s.%s.Close()", receiver, symbols.rows))
+	})
+}
+
+// iterator builds a generator for the store method that returns a cursor over the records matched by a blueprint. Unlike
+// the finder, the record's defaultLimit is not applied; only an explicit blueprint Limit or Offset limits the rows.
+func iterator(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	methodName := fmt.Sprintf("Iterate%s", inflector.Pluralize(record.name()))
+
+	if len(record.fields) == 0 {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	symbols := finderSymbols{
+		blueprint:       "_blueprint",
+		statementResult: "_statement",
+		statementError:  "_se",
+		queryString:     "_queryString",
+		queryResult:     "_queryResult",
+		queryError:      "_qe",
+		limit:           "_limit",
+		offset:          "_offset",
+		orderClause:     "_orderClause",
+		orderError:      "_oe",
+	}
+
+	params := []writing.FuncParam{
+		{Symbol: symbols.blueprint, Type: fmt.Sprintf("*%s", record.blueprint())},
+	}
+
+	returns := []string{fmt.Sprintf("*%s", record.cursor()), "error"}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		if e := writeCursor(gosrc, record); e != nil {
+			pw.CloseWithError(e)
+			return
+		}
+
+		gosrc.Comment("[marlow feature]: iterator on table[%s]", record.table())
+
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
+
+			if e := writeLookupQuery(gosrc, record, symbols, writing.Nil); e != nil {
+				return e
+			}
+
+			// Without a limit, the offset is applied using the largest limit supported by the dialects.
+			gosrc.WithIf("%s != nil && (%s.Limit >= 1 || %s.%s() >= 1)", func(url.Values) error {
+				limits := "%s, %s := int64(math.MaxInt64), int64(%s.%s())"
+				gosrc.Println(limits, symbols.limit, symbols.offset, symbols.blueprint, blueprintOffsetMethod)
+
+				gosrc.WithIf("%s.Limit >= 1", func(url.Values) error {
+					return gosrc.Println("%s = int64(%s.Limit)", symbols.limit, symbols.blueprint)
+				}, symbols.blueprint)
+
+				return gosrc.Println(
+					"fmt.Fprintf(%s, %s, %s, %s)",
+					symbols.queryString,
+					strconv.Quote(record.dialect().LimitOffset()),
+					symbols.limit,
+					symbols.offset,
+				)
+			}, symbols.blueprint, symbols.blueprint, symbols.blueprint, blueprintOffsetMethod)
+
+			logwriter.AddLog(symbols.queryString, fmt.Sprintf("%s.Values()", symbols.blueprint))
+
+			gosrc.Println(
+				"%s, %s := %s.PrepareContext(%s, %s.String())",
+				symbols.statementResult,
+				symbols.statementError,
+				scope.Get("receiver"),
+				contextSymbol,
+				symbols.queryString,
+			)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				return gosrc.Returns(writing.Nil, symbols.statementError)
+			}, symbols.statementError)
+
+			gosrc.Println(
+				"%s, %s := %s.QueryContext(%s, %s.Values()...)",
+				symbols.queryResult,
+				symbols.queryError,
+				symbols.statementResult,
+				contextSymbol,
+				symbols.blueprint,
+			)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				gosrc.Println("%s.Close()", symbols.statementResult)
+				return gosrc.Returns(writing.Nil, symbols.queryError)
+			}, symbols.queryError)
+
+			cursor := "&%s{rows: %s, statement: %s}"
+			return gosrc.Returns(fmt.Sprintf(cursor, record.cursor(), symbols.queryResult, symbols.statementResult), writing.Nil)
+		})
+
+		if e == nil {
+			record.registerImports("fmt", "math", "bytes", "database/sql")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// walker builds a generator for the store method that calls a function with every record matched by a blueprint, in
+// primary key order. Records are loaded in batches by following the cursor returned with each one, so the table is
+// never held in memory and the function is free to use the store between batches. Records without a primary key do
+// not receive it.
+func walker(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	methodName := fmt.Sprintf("Each%s", inflector.Pluralize(record.name()))
+	if _, _, ok := record.primaryKeyField(); ok != true {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	params := []writing.FuncParam{
+		{Symbol: "_blueprint", Type: fmt.Sprintf("*%s", record.blueprint())},
+		{Symbol: "_batchSize", Type: "int"},
+		{Symbol: "_fn", Type: fmt.Sprintf("func(*%s) error", record.name())},
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+		gosrc.Comment("[marlow feature]: batched walk over table[%s] by %s", record.table(), record.primaryKeyColumn())
+
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: []string{"error"}}
+
+		e := writeContextMethods(gosrc, record, method, walkerBlock(gosrc, record))
+
+		if e == nil {
+			record.registerImports("fmt")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// walkerBlock returns the body of the batched walk, which loads each batch through the store's finder.
+func walkerBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	symbols := struct {
+		blueprint string
+		batchSize string
+		callback  string
+		batch     string
+		results   string
+		result    string
+		next      string
+		findError string
+		callError string
+	}{"_blueprint", "_batchSize", "_fn", "_batch", "_results", "_record", "_next", "_fe", "_ce"}
+
+	findMethod := fmt.Sprintf(
+		"%s%s%s",
+		record.config.Get(constants.StoreFindMethodPrefixConfigOption),
+		inflector.Pluralize(record.name()),
+		contextMethodSuffix,
+	)
+
+	return func(scope url.Values) error {
+		gosrc.WithIf("%s < 1", func(url.Values) error {
+			return gosrc.Returns(fmt.Sprintf("fmt.Errorf(\"invalid batch size %%d\", %s)", symbols.batchSize))
+		}, symbols.batchSize)
+
+		// The blueprint is copied so the batch limit, ordering and cursor do not leak into the caller's value.
+		gosrc.Println("%s := %s{}", symbols.batch, record.blueprint())
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Println("%s = *%s", symbols.batch, symbols.blueprint)
+		}, symbols.blueprint)
+
+		gosrc.Println(
+			"%s.Limit, %s.Offset, %s.OrderBy, %s.OrderDirection = %s, 0, %q, \"ASC\"",
+			symbols.batch,
+			symbols.batch,
+			symbols.batch,
+			symbols.batch,
+			symbols.batchSize,
+			record.primaryKeyColumn(),
+		)
+
+		// Batches are loaded until one comes back without a cursor for the next.
+		gosrc.Println("for {")
+
+		gosrc.Println(
+			"%s, %s, %s := %s.%s(%s, &%s)",
+			symbols.results,
+			symbols.next,
+			symbols.findError,
+			scope.Get("receiver"),
+			findMethod,
+			contextSymbol,
+			symbols.batch,
+		)
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Returns(symbols.findError)
+		}, symbols.findError)
+
+		gosrc.WithIter("_, %s := range %s", func(url.Values) error {
+			return gosrc.WithIf("%s := %s(%s); %s != nil", func(url.Values) error {
+				return gosrc.Returns(symbols.callError)
+			}, symbols.callError, symbols.callback, symbols.result, symbols.callError)
+		}, symbols.result, symbols.results)
+
+		gosrc.WithIf("%s == \"\"", func(url.Values) error {
+			return gosrc.Returns(writing.Nil)
+		}, symbols.next)
+
+		gosrc.Println("%s.%s = %s", symbols.batch, blueprintCursorField, symbols.next)
+		return gosrc.Println("}")
+	}
+}
@@ -0,0 +1,403 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "strings"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+// recordLink describes a join record relating a record to another record, along with the fields of the join record
+// referencing each of them.
+type recordLink struct {
+	join       marlowRecord
+	field      string
+	other      marlowRecord
+	otherField string
+}
+
+// filterField returns the name of the blueprint field matching records linked to records matching another blueprint.
+func (l *recordLink) filterField() string {
+	return fmt.Sprintf("Linked%s", inflector.Pluralize(l.other.name()))
+}
+
+// joinedRecords returns the names of the two records joined by a join record.
+func joinedRecords(join marlowRecord) []string {
+	joined := make([]string, 0, 2)
+
+	for _, name := range strings.Split(join.config.Get(constants.JoinsConfigOption), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			joined = append(joined, name)
+		}
+	}
+
+	return joined
+}
+
+// joinFields returns the fields of a join record referencing each of the records it joins, in the order the records
+// were listed in the join record's config.
+func joinFields(join marlowRecord) ([]string, error) {
+	joined := joinedRecords(join)
+
+	if len(joined) != 2 || joined[0] == joined[1] {
+		return nil, fmt.Errorf("join record %s must join two different records", join.name())
+	}
+
+	fields := make([]string, 0, len(joined))
+
+	for _, name := range joined {
+		matches := make([]string, 0, 1)
+
+		for _, f := range join.fieldList(nil) {
+			if join.fields[f.name].Get(constants.ReferencesConfigOption) == name {
+				matches = append(matches, f.name)
+			}
+		}
+
+		if len(matches) != 1 {
+			return nil, fmt.Errorf("join record %s requires a single field referencing %s", join.name(), name)
+		}
+
+		fields = append(fields, matches[0])
+	}
+
+	return fields, nil
+}
+
+// validateJoins ensures that a record configured as a join record references each of the records it joins.
+func validateJoins(record marlowRecord) error {
+	if record.config.Get(constants.JoinsConfigOption) == "" {
+		return nil
+	}
+
+	_, e := joinFields(record)
+	return e
+}
+
+// links returns the links to other records made by the join records of the record's package, ordered by the name of
+// the join record.
+func (r *marlowRecord) links() []recordLink {
+	names := make([]string, 0, len(r.registry))
+
+	for name := range r.registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	links := make([]recordLink, 0)
+
+	for _, name := range names {
+		join := r.registry[name]
+		fields, e := joinFields(join)
+
+		if e != nil {
+			continue
+		}
+
+		joined := joinedRecords(join)
+
+		// Each joined record is linked to the other one; i is the position of the record and j that of the other.
+		for i, j := range []int{1, 0} {
+			other, ok := r.registry[joined[j]]
+
+			if joined[i] != r.name() || !ok {
+				continue
+			}
+
+			links = append(links, recordLink{join, fields[i], other, fields[j]})
+		}
+	}
+
+	return links
+}
+
+// linkFilter returns a generator that writes the blueprint clause method limiting lookups to the records linked through
+// a join record to the records matched by the blueprint held in the link's filter field. Only the conditions of the
+// nested blueprint are used; its limit, offset and order do not apply.
+func linkFilter(record marlowRecord, link recordLink, methods chan<- string) io.Reader {
+	pr, pw := io.Pipe()
+	filter := link.filterField()
+	methodName := fmt.Sprintf("linked%sString", inflector.Pluralize(link.other.name()))
+
+	join, other := link.join, link.other
+	returns := []string{"string", "[]interface{}"}
+	params := []writing.FuncParam{{Type: "int", Symbol: "_count"}}
+
+	query := fmt.Sprintf(
+		"%s IN (SELECT %s FROM %s WHERE %s IN (SELECT %s FROM %s%%s))",
+		record.columnReference(record.primaryKeyColumn()),
+		join.columnReference(join.fields[link.field].Get(constants.ColumnConfigOption)),
+		join.quote(join.table()),
+		join.columnReference(join.fields[link.otherField].Get(constants.ColumnConfigOption)),
+		other.columnReference(other.primaryKeyColumn()),
+		other.quote(other.table()),
+	)
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+		gosrc.Comment("[marlow] linked %s clause through \"%s\"", other.table(), join.table())
+
+		e := gosrc.WithMethod(methodName, record.blueprint(), params, returns, func(scope url.Values) error {
+			fieldReference := fmt.Sprintf("%s.%s", scope.Get("receiver"), filter)
+
+			gosrc.WithIf("%s == nil", func(url.Values) error {
+				return gosrc.Returns(writing.EmptyString, writing.Nil)
+			}, fieldReference)
+
+			gosrc.Println("_clause, _values := %s.%s(_count)", fieldReference, blueprintClauseMethod)
+
+			gosrc.WithIf("_clause != \"\"", func(url.Values) error {
+				return gosrc.Println("_clause = \" WHERE \" + _clause")
+			})
+
+			return gosrc.Returns(fmt.Sprintf("fmt.Sprintf(%q, _clause)", query), "_values")
+		})
+
+		if e == nil {
+			methods <- methodName
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+type linkerSymbols struct {
+	query          string
+	values         string
+	statement      string
+	statementError string
+	result         string
+	execError      string
+	count          string
+	store          string
+	cursor         string
+	cursorError    string
+	results        string
+	iterateError   string
+}
+
+// joinParam returns the parameter holding the primary key of a record joined through the field of a join record,
+// along with the expression converting it into the type of the field.
+func joinParam(join marlowRecord, fieldName string) (writing.FuncParam, string) {
+	target := join.registry[join.fields[fieldName].Get(constants.ReferencesConfigOption)]
+	_, keyConfig, _ := target.primaryKeyField()
+	symbol := fmt.Sprintf("_%s", strings.ToLower(target.name()[0:1])+target.name()[1:])
+	value := referenceValue(join.fields[fieldName].Get("type"), keyConfig.Get("type"), symbol)
+	return writing.FuncParam{Symbol: symbol, Type: keyConfig.Get("type")}, value
+}
+
+// newJoinGenerator returns a generator that writes the store methods of a join record: Link and Unlink, adding and
+// removing the row joining a pair of records, and ListLinked for each joined record, loading the records linked to one
+// of the other.
+func newJoinGenerator(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	fields, e := joinFields(record)
+
+	if e != nil {
+		pw.CloseWithError(e)
+		return pr
+	}
+
+	symbols := linkerSymbols{
+		query:          "_query",
+		values:         "_values",
+		statement:      "_statement",
+		statementError: "_se",
+		result:         "_result",
+		execError:      "_ee",
+		count:          "_count",
+		store:          "_store",
+		cursor:         "_cursor",
+		cursorError:    "_ce",
+		results:        "_results",
+		iterateError:   "_ie",
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		if e := writeLinkMethods(gosrc, record, fields, symbols); e != nil {
+			pw.CloseWithError(e)
+			return
+		}
+
+		for i, j := range []int{1, 0} {
+			if e := writeListLinkedMethod(gosrc, record, fields[i], fields[j], symbols); e != nil {
+				pw.CloseWithError(e)
+				return
+			}
+		}
+
+		record.registerImports("fmt")
+		pw.Close()
+	}()
+
+	return pr
+}
+
+// writeLinkMethods writes the Link and Unlink store methods of a join record, inserting and deleting the rows of the
+// join table holding a pair of primary keys. Links are not deduplicated; a unique index on the join table's columns
+// prevents a pair of records from being linked twice.
+func writeLinkMethods(gosrc writing.GoWriter, record marlowRecord, fields []string, symbols linkerSymbols) error {
+	params, values, columns := make([]writing.FuncParam, 0, 2), make([]string, 0, 2), make([]string, 0, 2)
+
+	for _, name := range fields {
+		param, value := joinParam(record, name)
+		params, values = append(params, param), append(values, value)
+		columns = append(columns, record.quote(record.fields[name].Get(constants.ColumnConfigOption)))
+	}
+
+	table := record.quote(record.table())
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%%s, %%s);", table, strings.Join(columns, ", "))
+	remove := fmt.Sprintf("DELETE FROM %s WHERE %s = %%s AND %s = %%s;", table, columns[0], columns[1])
+
+	link := writing.FuncDecl{Name: fmt.Sprintf("Link%s", record.name()), Params: params, Returns: []string{"error"}}
+
+	gosrc.Comment("[marlow feature]: link on join table[%s]", record.table())
+
+	e := writeContextMethods(gosrc, record, link, func(scope url.Values) error {
+		if e := writeLinkExec(gosrc, record, scope.Get("receiver"), insert, "_", values, symbols); e != nil {
+			return e
+		}
+
+		return gosrc.Returns(writing.Nil)
+	})
+
+	if e != nil {
+		return e
+	}
+
+	returns := []string{"int64", "error"}
+	unlink := writing.FuncDecl{Name: fmt.Sprintf("Unlink%s", record.name()), Params: params, Returns: returns}
+
+	gosrc.Comment("[marlow feature]: unlink on join table[%s]", record.table())
+
+	return writeContextMethods(gosrc, record, unlink, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		if e := writeLinkExec(gosrc, record, receiver, remove, symbols.result, values, symbols, "-1"); e != nil {
+			return e
+		}
+
+		gosrc.Println("%s, %s := %s.RowsAffected()", symbols.count, symbols.execError, symbols.result)
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Returns("-1", symbols.execError)
+		}, symbols.execError)
+
+		return gosrc.Returns(symbols.count, writing.Nil)
+	})
+}
+
+// writeLinkExec writes the execution of a statement with the values provided into the result symbol, returning early
+// with the zero values provided followed by the error when it fails.
+func writeLinkExec(
+	gosrc writing.GoWriter,
+	record marlowRecord,
+	receiver, template, result string,
+	values []string,
+	symbols linkerSymbols,
+	zeros ...string,
+) error {
+	logwriter := logWriter{output: gosrc, receiver: receiver}
+
+	gosrc.Println("%s := %s", symbols.query, record.placeholders(template, "1", "2"))
+	gosrc.Println("%s := []interface{}{%s}", symbols.values, strings.Join(values, ", "))
+
+	logwriter.AddLog(symbols.query, symbols.values)
+
+	gosrc.Println(
+		"%s, %s := %s.PrepareContext(%s, %s)",
+		symbols.statement,
+		symbols.statementError,
+		receiver,
+		contextSymbol,
+		symbols.query,
+	)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(append(zeros, symbols.statementError)...)
+	}, symbols.statementError)
+
+	gosrc.Println("defer %s.Close()", symbols.statement)
+
+	gosrc.Println(
+		"%s, %s := %s.ExecContext(%s, %s...)",
+		result,
+		symbols.execError,
+		symbols.statement,
+		contextSymbol,
+		symbols.values,
+	)
+
+	return gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(append(zeros, symbols.execError)...)
+	}, symbols.execError)
+}
+
+// writeListLinkedMethod writes the store method loading the records referenced by one field of a join record that are
+// linked to the record referenced by the other.
+func writeListLinkedMethod(gosrc writing.GoWriter, record marlowRecord, field, keyField string, symbols linkerSymbols) error {
+	target := record.registry[record.fields[field].Get(constants.ReferencesConfigOption)]
+	keyed := record.registry[record.fields[keyField].Get(constants.ReferencesConfigOption)]
+	param, _ := joinParam(record, keyField)
+	keyName, _, _ := keyed.primaryKeyField()
+	pluralized := inflector.Pluralize(target.name())
+
+	method := writing.FuncDecl{
+		Name:    fmt.Sprintf("ListLinked%s", pluralized),
+		Params:  []writing.FuncParam{param},
+		Returns: []string{fmt.Sprintf("[]*%s", target.name()), "error"},
+	}
+
+	link := recordLink{other: keyed}
+	filter := fmt.Sprintf("&%s{%s: []%s{%s}}", keyed.blueprint(), keyName, param.Type, param.Symbol)
+	blueprint := fmt.Sprintf("&%s{%s: %s}", target.blueprint(), link.filterField(), filter)
+
+	gosrc.Comment("[marlow feature]: %s linked to %s through join table[%s]", target.table(), keyed.table(), record.table())
+
+	return writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.Println(
+			"%s := New%s(%s.%s, %s.%s)",
+			symbols.store,
+			target.external(),
+			receiver,
+			record.executor(),
+			receiver,
+			constants.StoreLoggerField,
+		)
+
+		gosrc.Println(
+			"%s, %s := %s.Iterate%sContext(%s, %s)",
+			symbols.cursor,
+			symbols.cursorError,
+			symbols.store,
+			pluralized,
+			contextSymbol,
+			blueprint,
+		)
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Returns(writing.Nil, symbols.cursorError)
+		}, symbols.cursorError)
+
+		gosrc.Println("defer %s.Close()", symbols.cursor)
+		gosrc.Println("%s := make(%s, 0)", symbols.results, method.Returns[0])
+
+		gosrc.WithIter("%s.Next()", func(url.Values) error {
+			return gosrc.Println("%s = append(%s, %s.Record())", symbols.results, symbols.results, symbols.cursor)
+		}, symbols.cursor)
+
+		gosrc.WithIf("%s := %s.Err(); %s != nil", func(url.Values) error {
+			return gosrc.Returns(writing.Nil, symbols.iterateError)
+		}, symbols.iterateError, symbols.cursor, symbols.iterateError)
+
+		return gosrc.Returns(symbols.results, writing.Nil)
+	})
+}
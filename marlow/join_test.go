@@ -0,0 +1,158 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sync"
+import "bytes"
+import "strings"
+import "testing"
+import "net/url"
+import "go/token"
+import "go/parser"
+import "github.com/franela/goblin"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+func Test_Join(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	var scaffold *relationTestScaffold
+
+	g.Describe("join record feature generator test suite", func() {
+		g.BeforeEach(func() {
+			scaffold = &relationTestScaffold{
+				buffer:     new(bytes.Buffer),
+				wg:         &sync.WaitGroup{},
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				registry:   make(recordRegistry),
+				received:   make(map[string]bool),
+				registered: make(map[string]bool),
+			}
+
+			scaffold.wg.Add(2)
+
+			go func() {
+				for method := range scaffold.methods {
+					scaffold.registered[method.Name] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			go func() {
+				for i := range scaffold.imports {
+					scaffold.received[i] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			scaffold.add("Author", "id", map[string]url.Values{
+				"ID": {"type": {"int"}, constants.ColumnConfigOption: {"id"}},
+			})
+
+			scaffold.add("Book", "id", map[string]url.Values{
+				"ID": {"type": {"int"}, constants.ColumnConfigOption: {"id"}},
+			})
+
+			scaffold.add("BookAuthor", "", map[string]url.Values{
+				"BookID": {
+					"type":                           {"int"},
+					constants.ColumnConfigOption:     {"book_id"},
+					constants.ReferencesConfigOption: {"Book"},
+				},
+				"AuthorID": {
+					"type":                           {"sql.NullInt64"},
+					constants.ColumnConfigOption:     {"author_id"},
+					constants.ReferencesConfigOption: {"Author"},
+				},
+			})
+
+			scaffold.registry["BookAuthor"].config.Set(constants.TableNameConfigOption, "book_authors")
+			scaffold.registry["BookAuthor"].config.Set(constants.JoinsConfigOption, "Book,Author")
+		})
+
+		g.AfterEach(func() {
+			scaffold.close()
+		})
+
+		g.Describe("validateJoins", func() {
+			g.It("succeeds when each joined record is referenced by a single field", func() {
+				g.Assert(validateJoins(scaffold.record("BookAuthor"))).Equal(nil)
+			})
+
+			g.It("fails unless two different records are joined", func() {
+				scaffold.registry["BookAuthor"].config.Set(constants.JoinsConfigOption, "Book,Book")
+				e := validateJoins(scaffold.record("BookAuthor"))
+				g.Assert(e.Error()).Equal("join record BookAuthor must join two different records")
+			})
+
+			g.It("fails when a joined record is not referenced by the join record", func() {
+				scaffold.registry["BookAuthor"].fields["AuthorID"].Del(constants.ReferencesConfigOption)
+				e := validateJoins(scaffold.record("BookAuthor"))
+				g.Assert(e.Error()).Equal("join record BookAuthor requires a single field referencing Author")
+			})
+		})
+
+		g.Describe("links", func() {
+			g.It("returns the link to the other record for each joined record", func() {
+				book, author := scaffold.record("Book"), scaffold.record("Author")
+				g.Assert(len(book.links())).Equal(1)
+				g.Assert(book.links()[0].filterField()).Equal("LinkedAuthors")
+				g.Assert(book.links()[0].field).Equal("BookID")
+				g.Assert(author.links()[0].filterField()).Equal("LinkedBooks")
+				g.Assert(author.links()[0].otherField).Equal("BookID")
+			})
+
+			g.It("writes the blueprint filter through the join table", func() {
+				book := scaffold.record("Book")
+				methods := make(chan string, 1)
+				io.Copy(scaffold.buffer, linkFilter(book, book.links()[0], methods))
+				g.Assert(<-methods).Equal("linkedAuthorsString")
+				expected := "books.id IN (SELECT book_authors.book_id FROM book_authors WHERE book_authors.author_id IN " +
+					"(SELECT authors.id FROM authors%s))"
+				g.Assert(strings.Contains(scaffold.buffer.String(), fmt.Sprintf("fmt.Sprintf(%q, _clause)", expected))).Equal(true)
+				g.Assert(strings.Contains(scaffold.buffer.String(), "_clause, _values := b.LinkedAuthors.clause(_count)")).Equal(true)
+			})
+		})
+
+		g.Describe("newJoinGenerator", func() {
+			g.It("generates valid golang", func() {
+				fmt.Fprintln(scaffold.buffer, "package marlowt")
+				_, e := io.Copy(scaffold.buffer, newJoinGenerator(scaffold.record("BookAuthor")))
+				g.Assert(e).Equal(nil)
+				_, e = parser.ParseFile(token.NewFileSet(), "", scaffold.buffer, parser.AllErrors)
+				g.Assert(e).Equal(nil)
+			})
+
+			g.It("registers the link, unlink and list methods with the store", func() {
+				io.Copy(scaffold.buffer, newJoinGenerator(scaffold.record("BookAuthor")))
+				scaffold.close()
+				g.Assert(scaffold.registered["LinkBookAuthor"]).Equal(true)
+				g.Assert(scaffold.registered["UnlinkBookAuthorContext"]).Equal(true)
+				g.Assert(scaffold.registered["ListLinkedBooks"]).Equal(true)
+				g.Assert(scaffold.registered["ListLinkedAuthors"]).Equal(true)
+			})
+
+			g.It("inserts and deletes the rows holding the primary keys of the joined records", func() {
+				io.Copy(scaffold.buffer, newJoinGenerator(scaffold.record("BookAuthor")))
+				output := scaffold.buffer.String()
+				g.Assert(strings.Contains(output, "\"INSERT INTO book_authors (book_id, author_id) VALUES (?, ?);\"")).Equal(true)
+				g.Assert(strings.Contains(output, "\"DELETE FROM book_authors WHERE book_id = ? AND author_id = ?;\"")).Equal(true)
+				g.Assert(strings.Contains(output, "[]interface{}{_book, sql.NullInt64{Int64: int64(_author), Valid: true}}")).Equal(true)
+			})
+
+			g.It("lists linked records using the blueprint filter of the other record", func() {
+				io.Copy(scaffold.buffer, newJoinGenerator(scaffold.record("BookAuthor")))
+				expected := "_store.IterateAuthorsContext(_ctx, &AuthorBlueprint{LinkedBooks: &BookBlueprint{ID: []int{_book}}})"
+				g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+			})
+
+			g.It("numbers the placeholders for postgres join records", func() {
+				scaffold.registry["BookAuthor"].config.Set(constants.DialectConfigOption, "postgres")
+				io.Copy(scaffold.buffer, newJoinGenerator(scaffold.record("BookAuthor")))
+				expected := "fmt.Sprintf(\"DELETE FROM book_authors WHERE book_id = $%d AND author_id = $%d;\", 1, 2)"
+				g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+			})
+		})
+	})
+}
@@ -0,0 +1,349 @@
+package marlow
+
+import "fmt"
+import "strings"
+import "net/url"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type keysetSymbols struct {
+	keys        string
+	keysError   string
+	values      string
+	key         string
+	index       string
+	token       string
+	decoded     string
+	decodeError string
+	value       string
+	valueError  string
+	after       string
+}
+
+var cursorTokenType = "struct {\nKeys []string `json:\"k\"`\nValues %s `json:\"v\"`\n}"
+
+// writeCursorMethods generates the blueprint methods used to page through lookups with the opaque token held in the
+// blueprint's Cursor field. The token holds the order keys of the lookup and the values of those columns on the last
+// record of a page; decoding it produces the clause limiting lookups to the records that come after that one. NULL
+// values of nullable columns are ordered before every other value, matching the order clause of lookups. The cursor
+// limits every use of the blueprint to those records, counts, aggregates, group counts, updates and deletions included,
+// and lookups continuing from a cursor ignore the blueprint's Offset. Records without a primary key do not receive
+// these methods.
+func writeCursorMethods(out writing.GoWriter, record marlowRecord) error {
+	if _, _, keyed := record.primaryKeyField(); keyed != true {
+		return nil
+	}
+
+	symbols := keysetSymbols{
+		keys:        "_keys",
+		keysError:   "_ke",
+		values:      "_values",
+		key:         "_key",
+		index:       "_i",
+		token:       "_token",
+		decoded:     "_decoded",
+		decodeError: "_de",
+		value:       "_value",
+		valueError:  "_ve",
+		after:       "_after",
+	}
+
+	record.registerImports("fmt", "strings", "encoding/json", "encoding/base64")
+
+	if len(nullableColumns(record)) > 0 {
+		record.registerImports("database/sql/driver")
+	}
+
+	if e := writeCursorKeysMethod(out, record, symbols); e != nil {
+		return e
+	}
+
+	if e := writeNextCursorMethod(out, record, symbols); e != nil {
+		return e
+	}
+
+	return writeKeysetMethod(out, record, symbols)
+}
+
+// writeCursorKeysMethod generates the blueprint method that decodes the cursor token, returning the order keys it was
+// created with and the typed column values that lookups must come after. Tokens created with a different order than
+// the blueprint's are rejected.
+func writeCursorKeysMethod(out writing.GoWriter, record marlowRecord, symbols keysetSymbols) error {
+	returns := []string{"[]string", "[]interface{}", "error"}
+
+	out.Comment("[marlow] cursor keys for \"%s\"", record.table())
+
+	return out.WithMethod(blueprintCursorKeysMethod, record.blueprint(), nil, returns, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		out.WithIf("%s == nil", func(url.Values) error {
+			return out.Returns(writing.Nil, writing.Nil, writing.Nil)
+		}, receiver)
+
+		// The clauses of linked record filters hold their own cursor, which is rejected along with the blueprint's.
+		for _, link := range record.links() {
+			out.WithIf("_, _, %s := %s.%s.%s(); %s != nil", func(url.Values) error {
+				return out.Returns(writing.Nil, writing.Nil, symbols.keysError)
+			}, symbols.keysError, receiver, link.filterField(), blueprintCursorKeysMethod, symbols.keysError)
+		}
+
+		out.WithIf("%s.%s == \"\"", func(url.Values) error {
+			return out.Returns(writing.Nil, writing.Nil, writing.Nil)
+		}, receiver, blueprintCursorField)
+
+		out.Println("%s, %s := %s.%s()", symbols.keys, symbols.keysError, receiver, blueprintOrderKeysMethod)
+
+		out.WithIf("%s != nil", func(url.Values) error {
+			return out.Returns(writing.Nil, writing.Nil, symbols.keysError)
+		}, symbols.keysError)
+
+		out.Println(
+			"%s, %s := base64.RawURLEncoding.DecodeString(%s.%s)",
+			symbols.decoded,
+			symbols.decodeError,
+			receiver,
+			blueprintCursorField,
+		)
+
+		out.Println("var %s %s", symbols.token, fmt.Sprintf(cursorTokenType, "[]json.RawMessage"))
+
+		out.WithIf("%s != nil || json.Unmarshal(%s, &%s) != nil", func(url.Values) error {
+			return out.Returns(writing.Nil, writing.Nil, "fmt.Errorf(\"invalid cursor\")")
+		}, symbols.decodeError, symbols.decoded, symbols.token)
+
+		out.WithIf("len(%s.Keys) != len(%s) || len(%s.Values) != len(%s)", func(url.Values) error {
+			return out.Returns(writing.Nil, writing.Nil, "fmt.Errorf(\"cursor does not match the lookup order\")")
+		}, symbols.token, symbols.keys, symbols.token, symbols.keys)
+
+		out.Println("%s := make([]interface{}, 0, len(%s))", symbols.values, symbols.keys)
+
+		e := out.WithIter("%s, %s := range %s", func(url.Values) error {
+			out.WithIf("%s.Keys[%s] != %s", func(url.Values) error {
+				return out.Returns(writing.Nil, writing.Nil, "fmt.Errorf(\"cursor does not match the lookup order\")")
+			}, symbols.token, symbols.index, symbols.key)
+
+			// Each value is decoded into the type of its field so that it is compared against the column correctly.
+			out.Println("switch strings.Fields(%s)[0] {", symbols.key)
+
+			for _, f := range record.fieldList(nil) {
+				out.Println("case %q:", f.column)
+				out.Println("var %s %s", symbols.value, record.fields[f.name].Get("type"))
+
+				out.WithIf("%s := json.Unmarshal(%s.Values[%s], &%s); %s != nil", func(url.Values) error {
+					return out.Returns(writing.Nil, writing.Nil, fmt.Sprintf(
+						"fmt.Errorf(\"invalid cursor value for %s: %%v\", %s)",
+						record.fields[f.name].Get(constants.ColumnConfigOption),
+						symbols.valueError,
+					))
+				}, symbols.valueError, symbols.token, symbols.index, symbols.value, symbols.valueError)
+
+				out.Println("%s = append(%s, %s)", symbols.values, symbols.values, symbols.value)
+			}
+
+			return out.Println("}")
+		}, symbols.index, symbols.key, symbols.keys)
+
+		if e != nil {
+			return e
+		}
+
+		return out.Returns(symbols.keys, symbols.values, writing.Nil)
+	})
+}
+
+// writeNextCursorMethod generates the blueprint method that encodes the cursor token of the lookup page ending with the
+// record provided.
+func writeNextCursorMethod(out writing.GoWriter, record marlowRecord, symbols keysetSymbols) error {
+	params := []writing.FuncParam{
+		{Symbol: "_record", Type: fmt.Sprintf("*%s", record.name())},
+	}
+
+	returns := []string{"string", "error"}
+
+	out.Comment("[marlow] next cursor for \"%s\"", record.table())
+
+	return out.WithMethod(blueprintNextCursorMethod, record.blueprint(), params, returns, func(scope url.Values) error {
+		out.Println("%s, %s := %s.%s()", symbols.keys, symbols.keysError, scope.Get("receiver"), blueprintOrderKeysMethod)
+
+		out.WithIf("%s != nil", func(url.Values) error {
+			return out.Returns(writing.EmptyString, symbols.keysError)
+		}, symbols.keysError)
+
+		out.Println("%s := make([]interface{}, 0, len(%s))", symbols.values, symbols.keys)
+
+		e := out.WithIter("_, %s := range %s", func(url.Values) error {
+			out.Println("switch strings.Fields(%s)[0] {", symbols.key)
+
+			for _, f := range record.fieldList(nil) {
+				out.Println("case %q:", f.column)
+				out.Println("%s = append(%s, _record.%s)", symbols.values, symbols.values, f.name)
+			}
+
+			return out.Println("}")
+		}, symbols.key, symbols.keys)
+
+		if e != nil {
+			return e
+		}
+
+		tokenType := fmt.Sprintf(cursorTokenType, "[]interface{}")
+		out.Println("%s := %s{%s, %s}", symbols.token, tokenType, symbols.keys, symbols.values)
+		out.Println("%s, %s := json.Marshal(%s)", symbols.decoded, symbols.decodeError, symbols.token)
+
+		out.WithIf("%s != nil", func(url.Values) error {
+			return out.Returns(writing.EmptyString, symbols.decodeError)
+		}, symbols.decodeError)
+
+		return out.Returns(fmt.Sprintf("base64.RawURLEncoding.EncodeToString(%s)", symbols.decoded), writing.Nil)
+	})
+}
+
+// writeKeysetMethod generates the blueprint method that limits lookups to the records coming after the one the cursor
+// token was created from. For order keys (a, b, c) this is the lexicographic comparison
+// "(a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)", using "<" for keys in descending order. Invalid tokens
+// produce no clause; lookups are expected to have rejected them using the cursor keys method beforehand.
+func writeKeysetMethod(out writing.GoWriter, record marlowRecord, symbols keysetSymbols) error {
+	returns := []string{"string", "[]interface{}"}
+	params := []writing.FuncParam{
+		{Type: "int", Symbol: "_count"},
+	}
+
+	nullable := nullableColumns(record)
+
+	out.Comment("[marlow] keyset clause for \"%s\"", record.table())
+
+	return out.WithMethod(blueprintKeysetMethod, record.blueprint(), params, returns, func(scope url.Values) error {
+		out.Println(
+			"%s, %s, %s := %s.%s()",
+			symbols.keys,
+			symbols.after,
+			symbols.keysError,
+			scope.Get("receiver"),
+			blueprintCursorKeysMethod,
+		)
+
+		out.WithIf("%s != nil || len(%s) == 0", func(url.Values) error {
+			return out.Returns(writing.EmptyString, writing.Nil)
+		}, symbols.keysError, symbols.keys)
+
+		out.Println("_clauses, %s := make([]string, 0, len(%s)), make([]interface{}, 0)", symbols.values, symbols.keys)
+
+		if len(nullable) > 0 {
+			writeKeysetNulls(out, nullable)
+		}
+
+		e := out.WithIter("%s, %s := range %s", func(url.Values) error {
+			out.Println("_parts, _operator := strings.Fields(%s), \">\"", symbols.key)
+
+			out.WithIf("_parts[1] == \"DESC\"", func(url.Values) error {
+				return out.Println("_operator = \"<\"")
+			})
+
+			// No record follows the NULL values of a column ordered in descending order while the earlier keys are equal.
+			if len(nullable) > 0 {
+				out.WithIf("_operator == \"<\" && _null(%s[%s])", func(url.Values) error {
+					return out.Println("continue")
+				}, symbols.after, symbols.index)
+			}
+
+			out.Println("_terms := make([]string, 0, %s+1)", symbols.index)
+
+			// Every key before the current one is required to be equal to the value of the cursor record.
+			out.WithIter("_j := 0; _j < %s; _j++", func(url.Values) error {
+				out.Println("_column := strings.Fields(%s[_j])[0]", symbols.keys)
+
+				if len(nullable) > 0 {
+					out.WithIf("_null(%s[_j])", func(url.Values) error {
+						out.Println("_terms = append(_terms, _column+\" IS NULL\")")
+						return out.Println("continue")
+					}, symbols.after)
+				}
+
+				out.Println("_placeholder := %s", record.placeholders("%s", fmt.Sprintf("_count+len(%s)", symbols.values)))
+				out.Println("_terms = append(_terms, fmt.Sprintf(\"%%s = %%s\", _column, _placeholder))")
+				return out.Println("%s = append(%s, %s[_j])", symbols.values, symbols.values, symbols.after)
+			}, symbols.index)
+
+			if len(nullable) > 0 {
+				writeKeysetNullTerm(out, record, symbols)
+			}
+
+			out.Println("_placeholder := %s", record.placeholders("%s", fmt.Sprintf("_count+len(%s)", symbols.values)))
+			out.Println("_terms = append(_terms, fmt.Sprintf(\"%%s %%s %%s\", _parts[0], _operator, _placeholder))")
+			out.Println("%s = append(%s, %s[%s])", symbols.values, symbols.values, symbols.after, symbols.index)
+			return out.Println("_clauses = append(_clauses, \"(\"+strings.Join(_terms, \" AND \")+\")\")")
+		}, symbols.index, symbols.key, symbols.keys)
+
+		if e != nil {
+			return e
+		}
+
+		return out.Returns("\"(\"+strings.Join(_clauses, \" OR \")+\")\"", symbols.values)
+	})
+}
+
+// writeKeysetNulls writes the lookups used by the keyset clause of records with nullable fields: the set of nullable
+// column references and the check for cursor values holding NULL. Only the sql.Null* types implement driver.Valuer.
+func writeKeysetNulls(out writing.GoWriter, nullable []string) {
+	out.Println("_nullable := %s", nullableLiteral(nullable))
+
+	out.Println("_null := func(_value interface{}) bool {")
+	out.Println("_valuer, _ok := _value.(driver.Valuer)")
+
+	out.WithIf("_ok == false", func(url.Values) error {
+		return out.Returns("false")
+	})
+
+	out.Println("_held, _ := _valuer.Value()")
+	out.Returns("_held == nil")
+	out.Println("}")
+}
+
+// writeKeysetNullTerm writes the comparison of the current order key when its column is nullable. NULL values are
+// ordered before every other value: records with a value follow a NULL cursor value in ascending order, while NULL
+// records follow any cursor value in descending order.
+func writeKeysetNullTerm(out writing.GoWriter, record marlowRecord, symbols keysetSymbols) {
+	clause := "_clauses = append(_clauses, \"(\"+strings.Join(_terms, \" AND \")+\")\")"
+
+	out.WithIf("_null(%s[%s])", func(url.Values) error {
+		out.Println("_terms = append(_terms, _parts[0]+\" IS NOT NULL\")")
+		out.Println("%s", clause)
+		return out.Println("continue")
+	}, symbols.after, symbols.index)
+
+	out.WithIf("_operator == \"<\" && _nullable[_parts[0]]", func(url.Values) error {
+		out.Println("_placeholder := %s", record.placeholders("%s", fmt.Sprintf("_count+len(%s)", symbols.values)))
+		out.Println("_term := fmt.Sprintf(\"(%%s < %%s OR %%s IS NULL)\", _parts[0], _placeholder, _parts[0])")
+		out.Println("_terms = append(_terms, _term)")
+		out.Println("%s = append(%s, %s[%s])", symbols.values, symbols.values, symbols.after, symbols.index)
+		out.Println("%s", clause)
+		return out.Println("continue")
+	})
+}
+
+// nullableColumns returns the references of the columns held by the record's nullable fields.
+func nullableColumns(record marlowRecord) []string {
+	references := make([]string, 0, len(record.fields))
+
+	nullable := record.fieldList(func(config url.Values) bool {
+		_, ok := lookupNullableType(config.Get("type"))
+		return ok
+	})
+
+	for _, f := range nullable {
+		references = append(references, f.column)
+	}
+
+	return references
+}
+
+// nullableLiteral returns the map literal holding the column references provided, used to look up nullable columns.
+func nullableLiteral(references []string) string {
+	entries := make([]string, 0, len(references))
+
+	for _, reference := range references {
+		entries = append(entries, fmt.Sprintf("%q: true", reference))
+	}
+
+	return fmt.Sprintf("map[string]bool{%s}", strings.Join(entries, ", "))
+}
@@ -0,0 +1,190 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+const (
+	// savepointRegistry is the package-level variable holding the savepoint depth of each transaction stores are bound
+	// to; the depth is kept per transaction so that every store bound to the same transaction names savepoints alike.
+	savepointRegistry = "marlowSavepoints"
+
+	// savepointEnter is the package-level function incrementing (and returning) the savepoint depth of a transaction.
+	savepointEnter = "marlowEnterSavepoint"
+
+	// savepointLeave is the package-level function decrementing the savepoint depth of a transaction.
+	savepointLeave = "marlowLeaveSavepoint"
+)
+
+// packageRecords returns the records of the registry that have stores generated for them, sorted by name.
+func packageRecords(registry recordRegistry) []marlowRecord {
+	names := make([]string, 0, len(registry))
+
+	for name, record := range registry {
+		if record.featured() && record.external() != "" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	records := make([]marlowRecord, 0, len(names))
+
+	for _, name := range names {
+		records = append(records, registry[name])
+	}
+
+	return records
+}
+
+// hostsPackage returns true if the record is the first of its package's records to have a store; the source generated
+// for it holds the declarations shared by every store of the package.
+func (r *marlowRecord) hostsPackage() bool {
+	records := packageRecords(r.registry)
+	return len(records) > 0 && records[0].name() == r.name()
+}
+
+// writePackageStores writes the declarations shared by the stores of the record's package: the savepoint depth of the
+// transactions they are bound to and the struct grouping the stores, which binds all of them to a single transaction.
+func writePackageStores(destination io.Writer, record marlowRecord) error {
+	out := writing.NewGoWriter(destination)
+
+	if e := writeSavepointRegistry(out); e != nil {
+		return e
+	}
+
+	records := packageRecords(record.registry)
+
+	out.Comment(
+		"%s groups the stores generated for the records of the package, allowing them to share a transaction.",
+		constants.PackageStoresName,
+	)
+
+	e := out.WithStruct(constants.PackageStoresName, func(url.Values) error {
+		for _, r := range records {
+			out.Println("%s %s", inflector.Pluralize(r.name()), r.external())
+		}
+
+		return nil
+	})
+
+	if e != nil {
+		return e
+	}
+
+	name := constants.PackageStoresName
+
+	// Executors of every store share their methods, the executor of the first record is used to bind all of them.
+	binder := writing.FuncDecl{
+		Name:    "WithExecutor",
+		Params:  []writing.FuncParam{{Type: records[0].executor(), Symbol: "_executor"}},
+		Returns: []string{fmt.Sprintf("*%s", name)},
+	}
+
+	out.Comment("[marlow] executor binding for %s", name)
+
+	e = out.WithMethod(binder.Name, name, binder.Params, binder.Returns, func(scope url.Values) error {
+		out.Println("_bound := *%s", scope.Get("receiver"))
+
+		for _, r := range records {
+			field := inflector.Pluralize(r.name())
+
+			out.WithIf("_bound.%s != nil", func(url.Values) error {
+				return out.Println("_bound.%s = _bound.%s.WithExecutor(_executor)", field, field)
+			}, field)
+		}
+
+		return out.Returns("&_bound")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	transaction := writing.FuncDecl{
+		Name:    "Transaction",
+		Params:  []writing.FuncParam{{Type: fmt.Sprintf("func(*%s) error", name), Symbol: "_fn"}},
+		Returns: []string{"error"},
+	}
+
+	contextual := writing.FuncDecl{
+		Name:    fmt.Sprintf("%s%s", transaction.Name, contextMethodSuffix),
+		Params:  append([]writing.FuncParam{{Type: "context.Context", Symbol: contextSymbol}}, transaction.Params...),
+		Returns: transaction.Returns,
+	}
+
+	out.Comment("[marlow] transaction helper for %s", name)
+
+	// The transaction is started by the first store present, the callback receives a copy of the stores where every
+	// store present is bound to it. The stores are expected to share a database.
+	e = out.WithMethod(contextual.Name, name, contextual.Params, contextual.Returns, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		for _, r := range records {
+			field := inflector.Pluralize(r.name())
+
+			out.WithIf("%s.%s != nil", func(url.Values) error {
+				out.Println(
+					"return %s.%s.%s(%s, func(_ %s, _tx %s) error {",
+					receiver,
+					field,
+					contextual.Name,
+					contextSymbol,
+					r.external(),
+					r.executor(),
+				)
+				out.Println("return _fn(%s.%s(_tx))", receiver, binder.Name)
+				return out.Println("})")
+			}, receiver, field)
+		}
+
+		return out.Returns(fmt.Sprintf("fmt.Errorf(\"%s\")", constants.EmptyStoresTransaction))
+	})
+
+	if e != nil {
+		return e
+	}
+
+	e = out.WithMethod(transaction.Name, name, transaction.Params, transaction.Returns, func(scope url.Values) error {
+		return out.Returns(fmt.Sprintf("%s.%s(context.Background(), _fn)", scope.Get("receiver"), contextual.Name))
+	})
+
+	record.registerImports("context", "database/sql", "fmt", "sync")
+	return e
+}
+
+// writeSavepointRegistry writes the package-level savepoint depth of transactions along with the functions entering and
+// leaving savepoints; the depth of a transaction is dropped once its last savepoint has been left.
+func writeSavepointRegistry(out writing.GoWriter) error {
+	out.Comment("%s holds the savepoint depth of the transactions the stores of the package are bound to.", savepointRegistry)
+	out.Println("var %s = struct {", savepointRegistry)
+	out.Println("sync.Mutex")
+	out.Println("depths map[*sql.Tx]int")
+	out.Println("}{depths: make(map[*sql.Tx]int)}")
+
+	params := []writing.FuncParam{{Type: "*sql.Tx", Symbol: "_tx"}}
+
+	e := out.WithFunc(savepointEnter, params, []string{"int"}, func(url.Values) error {
+		out.Println("%s.Lock()", savepointRegistry)
+		out.Println("defer %s.Unlock()", savepointRegistry)
+		out.Println("%s.depths[_tx]++", savepointRegistry)
+		return out.Returns(fmt.Sprintf("%s.depths[_tx]", savepointRegistry))
+	})
+
+	if e != nil {
+		return e
+	}
+
+	return out.WithFunc(savepointLeave, params, nil, func(url.Values) error {
+		out.Println("%s.Lock()", savepointRegistry)
+		out.Println("defer %s.Unlock()", savepointRegistry)
+		out.Println("%s.depths[_tx]--", savepointRegistry)
+
+		return out.WithIf("%s.depths[_tx] <= 0", func(url.Values) error {
+			return out.Println("delete(%s.depths, _tx)", savepointRegistry)
+		}, savepointRegistry)
+	})
+}
@@ -3,6 +3,7 @@ package marlow
 import "io"
 import "fmt"
 import "strings"
+import "strconv"
 import "net/url"
 import "github.com/gedex/inflector"
 import "github.com/dadleyy/marlow/marlow/writing"
@@ -20,6 +21,10 @@ type finderSymbols struct {
 	recordSlice     string
 	limit           string
 	offset          string
+	orderClause     string
+	orderError      string
+	nextCursor      string
+	cursorError     string
 }
 
 // finter builds a generator that is responsible for creating the FindRecord methods for a given record store.
@@ -50,6 +55,10 @@ func finder(record marlowRecord) io.Reader {
 		queryError:      "_qe",
 		limit:           "_limit",
 		offset:          "_offset",
+		orderClause:     "_orderClause",
+		orderError:      "_oe",
+		nextCursor:      "_next",
+		cursorError:     "_ce",
 		recordSlice:     fmt.Sprintf("[]*%s", record.name()),
 	}
 
@@ -61,9 +70,8 @@ func finder(record marlowRecord) io.Reader {
 			{Symbol: symbols.blueprint, Type: fmt.Sprintf("*%s", blueprintName)},
 		}
 
-		returns := []string{symbols.recordSlice, "error"}
+		returns := []string{symbols.recordSlice, "string", "error"}
 
-		fieldList := record.fieldList(nil)
 		defaultLimit := record.config.Get(constants.DefaultLimitConfigOption)
 
 		if defaultLimit == "" {
@@ -71,37 +79,23 @@ func finder(record marlowRecord) io.Reader {
 			return
 		}
 
-		e := gosrc.WithMethod(methodName, record.store(), params, returns, func(scope url.Values) error {
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
 			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
 
 			// Prepare the array that will be returned.
 			gosrc.Println("%s := make(%s, 0)\n", symbols.results, symbols.recordSlice)
-			defer gosrc.Returns(symbols.results, writing.Nil)
-
-			columns := make([]string, len(fieldList))
 
-			for i, n := range fieldList {
-				columns[i] = n.column
+			if e := writeLookupQuery(gosrc, record, symbols, writing.Nil, writing.EmptyString); e != nil {
+				return e
 			}
 
-			// Prepare the sql statement that will be sent to the DB.
-			gosrc.Println(
-				"%s := bytes.NewBufferString(\"SELECT %s FROM %s\")",
-				symbols.queryString,
-				strings.Join(columns, ","),
-				record.table(),
-			)
-
-			// Write our where clauses
-			e := gosrc.WithIf("%s != nil", func(url.Values) error {
-				return gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", symbols.queryString, symbols.blueprint)
-			}, symbols.blueprint)
-
 			// Write the limit determining code.
 			limitCondition := fmt.Sprintf("%s != nil && %s.Limit >= 1", symbols.blueprint, symbols.blueprint)
 			gosrc.Println("%s := %s", symbols.limit, defaultLimit)
 
-			e = gosrc.WithIf(limitCondition, func(url.Values) error {
+			e := gosrc.WithIf(limitCondition, func(url.Values) error {
 				return gosrc.Println("%s = %s.Limit", symbols.limit, symbols.blueprint)
 			})
 
@@ -110,21 +104,13 @@ func finder(record marlowRecord) io.Reader {
 			}
 
 			// Write the offset determining code.
-			offsetCondition := fmt.Sprintf("%s != nil && %s.Offset >= 1", symbols.blueprint, symbols.blueprint)
-			gosrc.Println("%s := 0", symbols.offset)
-
-			e = gosrc.WithIf(offsetCondition, func(url.Values) error {
-				return gosrc.Println("%s = %s.Offset", symbols.offset, symbols.blueprint)
-			})
-
-			if e != nil {
-				return e
-			}
+			gosrc.Println("%s := %s.%s()", symbols.offset, symbols.blueprint, blueprintOffsetMethod)
 
 			// Write out the limit & offset query write.
 			gosrc.Println(
-				"fmt.Fprintf(%s, \" LIMIT %%d OFFSET %%d\", %s, %s)",
+				"fmt.Fprintf(%s, %s, %s, %s)",
 				symbols.queryString,
+				strconv.Quote(record.dialect().LimitOffset()),
 				symbols.limit,
 				symbols.offset,
 			)
@@ -133,55 +119,58 @@ func finder(record marlowRecord) io.Reader {
 
 			// Write the query execution statement.
 			gosrc.Println(
-				"%s, %s := %s.Prepare(%s.String())",
+				"%s, %s := %s.PrepareContext(%s, %s.String())",
 				symbols.statementResult,
 				symbols.statementError,
 				scope.Get("receiver"),
+				contextSymbol,
 				symbols.queryString,
 			)
 
 			// Query has been executed, write out error handler
 			gosrc.WithIf("%s != nil", func(url.Values) error {
-				return gosrc.Returns(writing.Nil, symbols.statementError)
+				return gosrc.Returns(writing.Nil, writing.EmptyString, symbols.statementError)
 			}, symbols.statementError)
 
 			// Write out result close deferred statement.
 			gosrc.Println("defer %s.Close()", symbols.statementResult)
 
 			gosrc.Println(
-				"%s, %s := %s.Query(%s.Values()...)",
+				"%s, %s := %s.QueryContext(%s, %s.Values()...)",
 				symbols.queryResult,
 				symbols.queryError,
 				symbols.statementResult,
+				contextSymbol,
 				symbols.blueprint,
 			)
 
 			// Check to see if the two results had an error
 			gosrc.WithIf("%s != nil ", func(url.Values) error {
-				return gosrc.Returns(writing.Nil, symbols.queryError)
+				return gosrc.Returns(writing.Nil, writing.EmptyString, symbols.queryError)
 			}, symbols.queryError)
 
 			// Build the iteration that will loop over the row results, scanning them into real records.
-			return gosrc.WithIter("%s.Next()", func(url.Values) error {
+			e = gosrc.WithIter("%s.Next()", func(url.Values) error {
 				gosrc.Println("var %s %s", symbols.rowItem, record.name())
-				references := make([]string, 0, len(record.fields))
-
-				for _, f := range fieldList {
-					references = append(references, fmt.Sprintf("&%s.%s", symbols.rowItem, f.name))
-				}
-
-				scans := strings.Join(references, ",")
 
 				// Write the scan attempt and check for errors.
-				condition := fmt.Sprintf("e := %s.Scan(%s); e != nil", symbols.queryResult, scans)
+				condition := fmt.Sprintf("e := %s.Scan(%s); e != nil", symbols.queryResult, scanReferences(record, symbols.rowItem))
 				gosrc.WithIf(condition, func(url.Values) error {
-					gosrc.Println("return nil, e")
-					return nil
+					return gosrc.Returns(writing.Nil, writing.EmptyString, "e")
 				})
 
-				gosrc.Println("%s = append(%s, &%s)", symbols.results, symbols.results, symbols.rowItem)
-				return nil
+				writeHookCall(gosrc, record, afterFindHook, symbols.rowItem, func(hookError string) error {
+					return gosrc.Returns(writing.Nil, writing.EmptyString, hookError)
+				})
+
+				return gosrc.Println("%s = append(%s, &%s)", symbols.results, symbols.results, symbols.rowItem)
 			}, symbols.queryResult)
+
+			if e != nil {
+				return e
+			}
+
+			return writeNextCursor(gosrc, record, symbols)
 		})
 
 		if e != nil {
@@ -189,11 +178,6 @@ func finder(record marlowRecord) io.Reader {
 			return
 		}
 
-		record.registerStoreMethod(writing.FuncDecl{
-			Name:    methodName,
-			Params:  params,
-			Returns: returns,
-		})
 		record.registerImports("fmt", "bytes", "strings")
 
 		pw.Close()
@@ -202,6 +186,110 @@ func finder(record marlowRecord) io.Reader {
 	return pr
 }
 
+// writeLookupQuery writes the construction of the query buffer used by record lookups, selecting every field column and
+// applying the blueprint's where and order clauses.
+func writeLookupQuery(gosrc writing.GoWriter, record marlowRecord, symbols finderSymbols, zeros ...string) error {
+	writeDeletedDefault(gosrc, record, symbols.blueprint)
+
+	if e := writeCursorCheck(gosrc, record, symbols.blueprint, zeros...); e != nil {
+		return e
+	}
+
+	fieldList := record.fieldList(nil)
+	columns := make([]string, len(fieldList))
+
+	for i, n := range fieldList {
+		columns[i] = n.column
+	}
+
+	// Prepare the sql statement that will be sent to the DB.
+	gosrc.Println(
+		"%s := bytes.NewBufferString(\"SELECT %s FROM %s\")",
+		symbols.queryString,
+		strings.Join(columns, ","),
+		record.quote(record.table()),
+	)
+
+	// Write our where clauses
+	e := gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", symbols.queryString, symbols.blueprint)
+	}, symbols.blueprint)
+
+	if e != nil {
+		return e
+	}
+
+	// Write the order clause, falling back to the record's default order when the blueprint has none.
+	clause, clauseError := symbols.orderClause, symbols.orderError
+	return writeOrderClause(gosrc, symbols.queryString, symbols.blueprint, clause, clauseError, zeros...)
+}
+
+// writeNextCursor writes the return of the finder's results along with the cursor token of the next page, which is only
+// produced when the page was full. Records without a primary key always return an empty token.
+func writeNextCursor(gosrc writing.GoWriter, record marlowRecord, symbols finderSymbols) error {
+	if _, _, keyed := record.primaryKeyField(); keyed != true {
+		return gosrc.Returns(symbols.results, writing.EmptyString, writing.Nil)
+	}
+
+	gosrc.WithIf("len(%s) == 0 || len(%s) < %s", func(url.Values) error {
+		return gosrc.Returns(symbols.results, writing.EmptyString, writing.Nil)
+	}, symbols.results, symbols.results, symbols.limit)
+
+	gosrc.Println(
+		"%s, %s := %s.%s(%s[len(%s)-1])",
+		symbols.nextCursor,
+		symbols.cursorError,
+		symbols.blueprint,
+		blueprintNextCursorMethod,
+		symbols.results,
+		symbols.results,
+	)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, writing.EmptyString, symbols.cursorError)
+	}, symbols.cursorError)
+
+	return gosrc.Returns(symbols.results, symbols.nextCursor, writing.Nil)
+}
+
+// writeCursorCheck writes the rejection of invalid blueprint cursor tokens, returning the zero values provided along
+// with the error. Records without a primary key have no cursor to check.
+func writeCursorCheck(gosrc writing.GoWriter, record marlowRecord, blueprint string, zeros ...string) error {
+	if _, _, keyed := record.primaryKeyField(); keyed != true {
+		return nil
+	}
+
+	return gosrc.WithIf("_, _, _ce := %s.%s(); _ce != nil", func(url.Values) error {
+		return gosrc.Returns(append(zeros, "_ce")...)
+	}, blueprint, blueprintCursorKeysMethod)
+}
+
+// scanReferences returns the comma separated list of field references on the row item that the columns selected by
+// lookups are scanned into.
+func scanReferences(record marlowRecord, rowItem string) string {
+	fieldList := record.fieldList(nil)
+	references := make([]string, 0, len(fieldList))
+
+	for _, f := range fieldList {
+		references = append(references, fmt.Sprintf("&%s.%s", rowItem, f.name))
+	}
+
+	return strings.Join(references, ",")
+}
+
+// writeOrderClause writes the code responsible for appending the blueprint's ORDER BY clause to a lookup query buffer.
+func writeOrderClause(gosrc writing.GoWriter, queryString, blueprint, clause, clauseError string, zeros ...string) error {
+	gosrc.Println("%s, %s := %s.%s()", clause, clauseError, blueprint, blueprintOrderMethod)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(append(zeros, clauseError)...)
+	}, clauseError)
+
+	return gosrc.WithIf("%s != \"\"", func(url.Values) error {
+		return gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", queryString, clause)
+	}, clause)
+}
+
 type counterSymbols struct {
 	countMethodName string
 	blueprint       string
@@ -249,7 +337,9 @@ func counter(record marlowRecord) io.Reader {
 			"error",
 		}
 
-		e := gosrc.WithMethod(symbols.countMethodName, record.store(), params, returns, func(scope url.Values) error {
+		method := writing.FuncDecl{Name: symbols.countMethodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
 			receiver := scope.Get("receiver")
 			logwriter := logWriter{output: gosrc, receiver: receiver}
 
@@ -257,20 +347,23 @@ func counter(record marlowRecord) io.Reader {
 				return gosrc.Println("%s = &%s{}", params[0].Symbol, record.blueprint())
 			}, symbols.blueprint)
 
+			writeCursorCheck(gosrc, record, symbols.blueprint, "-1")
+
 			gosrc.Println(
 				"%s := fmt.Sprintf(\"SELECT COUNT(*) FROM %s %%s;\", %s)",
 				symbols.StatementQuery,
-				record.table(),
+				record.quote(record.table()),
 				symbols.blueprint,
 			)
 
 			logwriter.AddLog(symbols.StatementQuery, fmt.Sprintf("%s.Values()", symbols.blueprint))
 
 			gosrc.Println(
-				"%s, %s := %s.Prepare(%s)",
+				"%s, %s := %s.PrepareContext(%s, %s)",
 				symbols.statementResult,
 				symbols.statementError,
 				receiver,
+				contextSymbol,
 				symbols.StatementQuery,
 			)
 
@@ -282,10 +375,11 @@ func counter(record marlowRecord) io.Reader {
 
 			// Write the query execution, using the blueprint Values().
 			gosrc.Println(
-				"%s, %s := %s.Query(%s.Values()...)",
+				"%s, %s := %s.QueryContext(%s, %s.Values()...)",
 				symbols.queryResult,
 				symbols.queryError,
 				symbols.statementResult,
+				contextSymbol,
 				symbols.blueprint,
 			)
 
@@ -312,11 +406,6 @@ func counter(record marlowRecord) io.Reader {
 
 		if e == nil {
 			record.registerImports("fmt")
-			record.registerStoreMethod(writing.FuncDecl{
-				Name:    symbols.countMethodName,
-				Params:  params,
-				Returns: returns,
-			})
 		}
 
 		pw.CloseWithError(e)
@@ -337,6 +426,8 @@ type selectorSymbols struct {
 	scanError       string
 	limit           string
 	offset          string
+	orderClause     string
+	orderError      string
 }
 
 // selector will return a generator that will product a single field selection method for a given record store.
@@ -373,59 +464,84 @@ func selector(record marlowRecord, fieldName string, fieldConfig url.Values) io.
 		rowItem:         "_row",
 		limit:           "_limit",
 		offset:          "_offset",
+		orderClause:     "_orderClause",
+		orderError:      "_oe",
 	}
 
 	params := []writing.FuncParam{
 		{Type: fmt.Sprintf("*%s", record.blueprint()), Symbol: symbols.blueprint},
 	}
 
-	columnReference := fmt.Sprintf("%s.%s", record.table(), columnName)
+	columnReference := record.columnReference(columnName)
 
 	go func() {
 		gosrc := writing.NewGoWriter(pw)
 
 		gosrc.Comment("[marlow] field selector for %s (%s) [print: %s]", fieldName, methodName, record.blueprint())
 
-		e := gosrc.WithMethod(methodName, record.store(), params, returns, func(scope url.Values) error {
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
 			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
 			gosrc.Println("%s := make(%s, 0)", symbols.returnSlice, returnArrayType)
 
+			writeDeletedDefault(gosrc, record, symbols.blueprint)
+
 			gosrc.Println(
 				"%s := bytes.NewBufferString(\"SELECT %s FROM %s\")",
 				symbols.queryString,
 				columnReference,
-				record.table(),
+				record.quote(record.table()),
 			)
 
 			// Write our where clauses
-			gosrc.WithIf("%s != nil", func(url.Values) error {
+			e := gosrc.WithIf("%s != nil", func(url.Values) error {
 				return gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", symbols.queryString, symbols.blueprint)
 			}, symbols.blueprint)
 
+			if e != nil {
+				return e
+			}
+
+			if e := writeCursorCheck(gosrc, record, symbols.blueprint, writing.Nil); e != nil {
+				return e
+			}
+
+			// Write the order clause, falling back to the record's default order when the blueprint has none.
+			clause, clauseError := symbols.orderClause, symbols.orderError
+
+			if e := writeOrderClause(gosrc, symbols.queryString, symbols.blueprint, clause, clauseError, writing.Nil); e != nil {
+				return e
+			}
+
 			// Apply the limits and offsets to the query
 
 			defaultLimit := record.config.Get(constants.DefaultLimitConfigOption)
-			gosrc.Println("%s, %s := %s, 0", symbols.limit, symbols.offset, defaultLimit)
-
-			gosrc.WithIf("%s != nil && %s.Offset > 0", func(url.Values) error {
-				return gosrc.Println("%s = %s.Offset", symbols.offset, symbols.blueprint)
-			}, symbols.blueprint, symbols.blueprint)
+			gosrc.Println(
+				"%s, %s := %s, %s.%s()",
+				symbols.limit,
+				symbols.offset,
+				defaultLimit,
+				symbols.blueprint,
+				blueprintOffsetMethod,
+			)
 
 			gosrc.WithIf("%s != nil && %s.Limit > 0", func(url.Values) error {
 				return gosrc.Println("%s = %s.Limit", symbols.limit, symbols.blueprint)
 			}, symbols.blueprint, symbols.blueprint)
 
-			rangeString := "\" LIMIT %d OFFSET %d\""
+			rangeString := strconv.Quote(record.dialect().LimitOffset())
 
 			// Write the write statement for adding limit and offset into the query string.
 			gosrc.Println("fmt.Fprintf(%s, %s, %s, %s)", symbols.queryString, rangeString, symbols.limit, symbols.offset)
 
 			// Write the query execution statement.
 			gosrc.Println(
-				"%s, %s := %s.Prepare(%s.String())",
+				"%s, %s := %s.PrepareContext(%s, %s.String())",
 				symbols.statementResult,
 				symbols.statementError,
 				scope.Get("receiver"),
+				contextSymbol,
 				symbols.queryString,
 			)
 
@@ -440,10 +556,11 @@ func selector(record marlowRecord, fieldName string, fieldConfig url.Values) io.
 
 			// Write the execution statement using the bluepring values.
 			gosrc.Println(
-				"%s, %s := %s.Query(%s.Values()...)",
+				"%s, %s := %s.QueryContext(%s, %s.Values()...)",
 				symbols.queryResult,
 				symbols.queryError,
 				symbols.statementResult,
+				contextSymbol,
 				symbols.blueprint,
 			)
 
@@ -454,7 +571,7 @@ func selector(record marlowRecord, fieldName string, fieldConfig url.Values) io.
 			// Write out result close deferred statement.
 			gosrc.Println("defer %s.Close()", symbols.queryResult)
 
-			e := gosrc.WithIter("%s.Next()", func(url.Values) error {
+			e = gosrc.WithIter("%s.Next()", func(url.Values) error {
 				gosrc.Println("var %s %s", symbols.rowItem, returnItemType)
 				condition := fmt.Sprintf(
 					"%s := %s.Scan(&%s); %s != nil",
@@ -475,21 +592,118 @@ func selector(record marlowRecord, fieldName string, fieldConfig url.Values) io.
 				return e
 			}
 
-			record.registerStoreMethod(writing.FuncDecl{
-				Name:    methodName,
-				Params:  params,
-				Returns: returns,
-			})
-			gosrc.Println("return %s, nil", symbols.returnSlice)
-			return nil
+			return gosrc.Returns(symbols.returnSlice, writing.Nil)
+		})
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// getter builds a generator that is responsible for the primary key lookup method and its not-found error type.
+func getter(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	methodName := fmt.Sprintf("Get%s", record.name())
+	fieldName, fieldConfig, ok := record.primaryKeyField()
+
+	if ok != true {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	fieldType := fieldConfig.Get("type")
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		notFoundComment := "%s is returned by %s when no %s has the requested primary key."
+		gosrc.Comment(notFoundComment, record.notFoundError(), methodName, record.name())
+		e := gosrc.WithStruct(record.notFoundError(), func(url.Values) error {
+			return gosrc.Println("%s %s", fieldName, fieldType)
+		})
+
+		if e != nil {
+			pw.CloseWithError(e)
+			return
+		}
+
+		e = gosrc.WithMethod("Error", record.notFoundError(), nil, []string{"string"}, func(scope url.Values) error {
+			message := fmt.Sprintf("no %s found with primary key %%v", strings.ToLower(record.name()))
+			return gosrc.Returns(fmt.Sprintf("fmt.Sprintf(%q, %s.%s)", message, scope.Get("receiver"), fieldName))
 		})
 
+		if e != nil {
+			pw.CloseWithError(e)
+			return
+		}
+
+		gosrc.Comment("[marlow feature]: primary key lookup on table[%s]", record.table())
+
+		params := []writing.FuncParam{
+			{Symbol: "_id", Type: fieldType},
+		}
+
+		returns := []string{fmt.Sprintf("*%s", record.name()), "error"}
+
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e = writeContextMethods(gosrc, record, method, getterBlock(gosrc, record))
+
+		if e == nil {
+			record.registerImports("fmt")
+		}
+
 		pw.CloseWithError(e)
 	}()
 
 	return pr
 }
 
+// getterBlock returns the body of the primary key lookup, which loads the record through the store's finder.
+func getterBlock(gosrc writing.GoWriter, record marlowRecord) writing.Block {
+	symbols := struct {
+		identifier string
+		results    string
+		findError  string
+	}{"_id", "_results", "_fe"}
+
+	fieldName, fieldConfig, _ := record.primaryKeyField()
+	fieldType := fieldConfig.Get("type")
+
+	findMethod := fmt.Sprintf(
+		"%s%s%s",
+		record.config.Get(constants.StoreFindMethodPrefixConfigOption),
+		inflector.Pluralize(record.name()),
+		contextMethodSuffix,
+	)
+
+	return func(scope url.Values) error {
+		blueprint := fmt.Sprintf("&%s{%s: []%s{%s}, Limit: 1}", record.blueprint(), fieldName, fieldType, symbols.identifier)
+
+		gosrc.Println(
+			"%s, _, %s := %s.%s(%s, %s)",
+			symbols.results,
+			symbols.findError,
+			scope.Get("receiver"),
+			findMethod,
+			contextSymbol,
+			blueprint,
+		)
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Returns(writing.Nil, symbols.findError)
+		}, symbols.findError)
+
+		gosrc.WithIf("len(%s) == 0", func(url.Values) error {
+			notFound := fmt.Sprintf("&%s{%s: %s}", record.notFoundError(), fieldName, symbols.identifier)
+			return gosrc.Returns(writing.Nil, notFound)
+		}, symbols.results)
+
+		return gosrc.Returns(fmt.Sprintf("%s[0]", symbols.results), writing.Nil)
+	}
+}
+
 // newQueryableGenerator is responsible for returning a reader that will generate lookup functions for a given record.
 func newQueryableGenerator(record marlowRecord) io.Reader {
 	pr, pw := io.Pipe()
@@ -502,11 +716,16 @@ func newQueryableGenerator(record marlowRecord) io.Reader {
 	features := []io.Reader{
 		finder(record),
 		counter(record),
+		getter(record),
+		iterator(record),
+		walker(record),
+		reversePreloaders(record),
 	}
 
 	for name, config := range record.fields {
 		s := selector(record, name, config)
-		features = append(features, s)
+		features = append(features, s, aggregator(record, name, config), groupCounter(record, name, config))
+		features = append(features, preloader(record, name, config))
 	}
 
 	go func() {
@@ -4,6 +4,7 @@ import "fmt"
 import "io"
 import "sync"
 import "bytes"
+import "strings"
 import "net/url"
 import "testing"
 import "go/ast"
@@ -21,9 +22,10 @@ type queryableTestScaffold struct {
 	record  url.Values
 	fields  map[string]url.Values
 
-	received map[string]bool
-	closed   bool
-	wg       *sync.WaitGroup
+	received   map[string]bool
+	registered map[string]bool
+	closed     bool
+	wg         *sync.WaitGroup
 }
 
 func (s *queryableTestScaffold) g() io.Reader {
@@ -60,20 +62,22 @@ func Test_QueryableGenerator(t *testing.T) {
 
 		g.BeforeEach(func() {
 			scaffold = &queryableTestScaffold{
-				output:   new(bytes.Buffer),
-				imports:  make(chan string),
-				methods:  make(chan writing.FuncDecl),
-				record:   make(url.Values),
-				fields:   make(map[string]url.Values),
-				received: make(map[string]bool),
-				closed:   false,
-				wg:       &sync.WaitGroup{},
+				output:     new(bytes.Buffer),
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				record:     make(url.Values),
+				fields:     make(map[string]url.Values),
+				received:   make(map[string]bool),
+				registered: make(map[string]bool),
+				closed:     false,
+				wg:         &sync.WaitGroup{},
 			}
 
 			scaffold.wg.Add(2)
 
 			go func() {
-				for range scaffold.methods {
+				for m := range scaffold.methods {
+					scaffold.registered[m.Name] = true
 				}
 				scaffold.wg.Done()
 			}()
@@ -165,6 +169,124 @@ func Test_QueryableGenerator(t *testing.T) {
 				g.Assert(scaffold.received["strings"]).Equal(true)
 				g.Assert(scaffold.received["bytes"]).Equal(true)
 			})
+
+			g.It("quotes identifiers with backticks for mysql records", func() {
+				scaffold.record.Set("dialect", "mysql")
+				io.Copy(scaffold.output, scaffold.g())
+				output := scaffold.output.String()
+				g.Assert(strings.Contains(output, "\"SELECT `books`.`title` FROM `books`\"")).Equal(true)
+				g.Assert(strings.Contains(output, "\"SELECT COUNT(*) FROM `books` %s;\"")).Equal(true)
+			})
+
+			g.It("registers context-aware variants of the finder, counter and selector methods", func() {
+				scaffold.record.Set("storeFindMethodPrefix", "Find")
+				scaffold.record.Set("storeCountMethodPrefix", "Count")
+				scaffold.record.Set("storeSelectMethodPrefix", "Select")
+				io.Copy(scaffold.output, scaffold.g())
+				scaffold.close()
+
+				for _, name := range []string{"FindBooks", "CountBooks", "SelectBookTitles"} {
+					g.Assert(scaffold.registered[name]).Equal(true)
+					g.Assert(scaffold.registered[name+"Context"]).Equal(true)
+				}
+
+				g.Assert(scaffold.received["context"]).Equal(true)
+			})
+
+			g.It("does not register a primary key lookup for records without a primary key", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				scaffold.close()
+				g.Assert(scaffold.registered["GetBook"]).Equal(false)
+			})
+
+			g.It("registers the iterator and writes its cursor type", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				scaffold.close()
+				g.Assert(scaffold.registered["IterateBooks"]).Equal(true)
+				g.Assert(scaffold.registered["IterateBooksContext"]).Equal(true)
+				g.Assert(strings.Contains(scaffold.output.String(), "type BookCursor struct {")).Equal(true)
+			})
+
+			g.It("does not apply the default limit to iterators", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				output := scaffold.output.String()
+				g.Assert(strings.Contains(output, "_limit, _offset := int64(math.MaxInt64), int64(_blueprint.Offset)")).Equal(true)
+				g.Assert(strings.Contains(output, "return &BookCursor{rows: _queryResult, statement: _statement},nil")).Equal(true)
+			})
+
+			g.It("does not register the batched walk for records without a primary key", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				scaffold.close()
+				g.Assert(scaffold.registered["EachBooks"]).Equal(false)
+			})
+
+			g.Describe("with a primary key defined", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set("primaryKey", "id")
+					scaffold.record.Set("storeFindMethodPrefix", "Find")
+					scaffold.fields["ID"] = url.Values{
+						"type":   []string{"uint"},
+						"column": []string{"id"},
+					}
+				})
+
+				g.It("produces valid golang code", func() {
+					fmt.Fprintln(scaffold.output, "package marlowt")
+					io.Copy(scaffold.output, scaffold.g())
+					_, e := scaffold.parsed()
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("registers the primary key lookup and its context-aware variant", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					scaffold.close()
+					g.Assert(scaffold.registered["GetBook"]).Equal(true)
+					g.Assert(scaffold.registered["GetBookContext"]).Equal(true)
+				})
+
+				g.It("looks the record up with a blueprint on the primary key field", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					expected := "_results, _, _fe := b.FindBooksContext(_ctx, &BookBlueprint{ID: []uint{_id}, Limit: 1})"
+					g.Assert(strings.Contains(scaffold.output.String(), expected)).Equal(true)
+				})
+
+				g.It("returns a typed error holding the primary key when no record is found", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					output := scaffold.output.String()
+					g.Assert(strings.Contains(output, "type BookNotFoundError struct {")).Equal(true)
+					g.Assert(strings.Contains(output, "return nil,&BookNotFoundError{ID: _id}")).Equal(true)
+				})
+
+				g.It("registers the batched walk and its context-aware variant", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					scaffold.close()
+					g.Assert(scaffold.registered["EachBooks"]).Equal(true)
+					g.Assert(scaffold.registered["EachBooksContext"]).Equal(true)
+				})
+
+				g.It("walks the records in primary key order, following the cursor of each batch", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					output := scaffold.output.String()
+					expected := "_batch.Limit, _batch.Offset, _batch.OrderBy, _batch.OrderDirection = _batchSize, 0, \"id\", \"ASC\""
+					g.Assert(strings.Contains(output, expected)).Equal(true)
+					g.Assert(strings.Contains(output, "_results, _next, _fe := b.FindBooksContext(_ctx, &_batch)")).Equal(true)
+					g.Assert(strings.Contains(output, "_batch.Cursor = _next")).Equal(true)
+				})
+
+				g.It("rejects invalid cursors before querying", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					output := scaffold.output.String()
+					g.Assert(strings.Contains(output, "if _, _, _ce := _blueprint.cursorKeys(); _ce != nil {\nreturn nil,\"\",_ce")).Equal(true)
+					g.Assert(strings.Contains(output, "if _, _, _ce := _blueprint.cursorKeys(); _ce != nil {\nreturn -1,_ce")).Equal(true)
+				})
+
+				g.It("returns the cursor of the next page from the finder when the page is full", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					output := scaffold.output.String()
+					g.Assert(strings.Contains(output, "_next, _ce := _blueprint.nextCursor(_results[len(_results)-1])")).Equal(true)
+					g.Assert(strings.Contains(output, "return _results,_next,nil")).Equal(true)
+				})
+			})
 		})
 
 	})
@@ -6,7 +6,9 @@ import "fmt"
 import "sync"
 import "bytes"
 import "strings"
+import "io/ioutil"
 import "path/filepath"
+import "go/ast"
 import "go/token"
 import "go/parser"
 import "go/format"
@@ -14,8 +16,24 @@ import "go/format"
 import "github.com/dadleyy/marlow/marlow/writing"
 import "github.com/dadleyy/marlow/marlow/constants"
 
-// Compile is responsible for reading from a source and writing the generated marlow code into a destination.
-func Compile(destination io.Writer, reader io.Reader) error {
+// recordReaderFactory returns the reader generating the code of a struct declaration, or false if the declaration does
+// not produce any.
+type recordReaderFactory func(ast.Decl, chan<- string, recordRegistry) (io.Reader, bool)
+
+// Compile is responsible for reading from a source and writing the generated marlow code into a destination. The
+// package sources are the other files of the source's package; the records they declare may be referenced by fields of
+// the records being compiled.
+func Compile(destination io.Writer, reader io.Reader, packageSources ...io.Reader) error {
+	return compile(destination, reader, newRecordReader, packageSources...)
+}
+
+// CompileFakes reads from a source and writes the in-memory fakes of the stores generated for its records into the
+// destination. The fakes belong to the source's package and are compiled alongside the code written by Compile.
+func CompileFakes(destination io.Writer, reader io.Reader, packageSources ...io.Reader) error {
+	return compile(destination, reader, newFakeReader, packageSources...)
+}
+
+func compile(destination io.Writer, reader io.Reader, factory recordReaderFactory, packageSources ...io.Reader) error {
 	fs := token.NewFileSet()
 	packageAst, e := parser.ParseFile(fs, "", reader, parser.AllErrors|parser.ParseComments)
 
@@ -24,14 +42,12 @@ func Compile(destination io.Writer, reader io.Reader) error {
 	}
 
 	// Check to see if we are ignoring this source via the comments.
-	for _, c := range packageAst.Comments {
-		ignored := strings.Contains(c.Text(), constants.IgnoreSourceDirective)
-
-		if ignored {
-			return nil
-		}
+	if hasDirective(packageAst, constants.IgnoreSourceDirective) {
+		return nil
 	}
 
+	registry := newPackageRegistry(fs, packageAst, packageSources...)
+
 	buffered, packageName := new(bytes.Buffer), packageAst.Name.String()
 	importChannel, recordReaders := make(chan string), make([]io.Reader, 0, len(packageAst.Decls))
 
@@ -49,7 +65,7 @@ func Compile(destination io.Writer, reader io.Reader) error {
 
 	// Iterate over the declarations and construct the record store from the loaded ast.
 	for _, d := range packageAst.Decls {
-		reader, ok := newRecordReader(d, importChannel)
+		reader, ok := factory(d, importChannel, registry)
 
 		// Only deal with struct type declarations.
 		if !ok {
@@ -128,21 +144,98 @@ func Compile(destination io.Writer, reader io.Reader) error {
 	return e
 }
 
-// NewReaderFromFile opens the requested filename and returns an io.Reader that represents the compiled source.
+// NewReaderFromFile opens the requested filename and returns an io.Reader that represents the compiled source. The other
+// go files in the same directory are used as the package sources of the compilation.
 func NewReaderFromFile(filename string) (io.Reader, error) {
+	return newFileReader(filename, Compile)
+}
+
+// NewFakeReaderFromFile opens the requested filename and returns an io.Reader that represents the in-memory fakes of the
+// stores generated for the source, see CompileFakes.
+func NewFakeReaderFromFile(filename string) (io.Reader, error) {
+	return newFileReader(filename, CompileFakes)
+}
+
+func newFileReader(filename string, compiler func(io.Writer, io.Reader, ...io.Reader) error) (io.Reader, error) {
 	source, e := os.Open(filename)
 
 	if e != nil {
 		return nil, e
 	}
 
+	siblings, e := filepath.Glob(filepath.Join(filepath.Dir(filename), "*.go"))
+
+	if e != nil {
+		source.Close()
+		return nil, e
+	}
+
+	packageSources := make([]io.Reader, 0, len(siblings))
+
+	for _, name := range siblings {
+		if name == filepath.Clean(filename) || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		contents, e := ioutil.ReadFile(name)
+
+		if e != nil {
+			source.Close()
+			return nil, e
+		}
+
+		packageSources = append(packageSources, bytes.NewReader(contents))
+	}
+
 	pr, pw := io.Pipe()
 
 	go func() {
 		defer source.Close()
-		e := Compile(pw, source)
+		e := compiler(pw, source, packageSources...)
 		pw.CloseWithError(e)
 	}()
 
 	return pr, nil
 }
+
+// newPackageRegistry returns the registry of the records declared by a source and the other sources of its package.
+func newPackageRegistry(fs *token.FileSet, source *ast.File, packageSources ...io.Reader) recordRegistry {
+	registry, sources := make(recordRegistry), make([]*ast.File, 0, len(packageSources)+1)
+
+	for _, packageSource := range packageSources {
+		sourceAst, e := parser.ParseFile(fs, "", packageSource, parser.AllErrors|parser.ParseComments)
+
+		// Invalid package sources are reported when they are compiled themselves; generated sources declare no records.
+		if e != nil || hasDirective(sourceAst, constants.IgnoreSourceDirective, constants.CompilerHeader) {
+			continue
+		}
+
+		sources = append(sources, sourceAst)
+	}
+
+	sources = append(sources, source)
+
+	for _, sourceAst := range sources {
+		registerRecords(registry, sourceAst)
+	}
+
+	// Lifecycle hooks may be declared in any source of the package, regardless of where their record is declared.
+	for _, sourceAst := range sources {
+		registerHooks(registry, sourceAst)
+	}
+
+	return registry
+}
+
+// hasDirective returns true if any of the comments in the parsed source contain one of the directives provided.
+func hasDirective(source *ast.File, directives ...string) bool {
+	for _, c := range source.Comments {
+		for _, directive := range directives {
+			if strings.Contains(c.Text(), directive) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -104,6 +104,45 @@ func Test_Reader(t *testing.T) {
 			g.Assert(e).Equal(nil)
 		})
 
+		g.Describe("with records declared by other sources of the package", func() {
+			author := `
+			package marlowt
+
+			type Author struct {
+				table string ` + "`marlow:\"tableName=authors\"`" + `
+				ID int ` + "`marlow:\"column=id\"`" + `
+			}
+			`
+
+			book := `
+			package marlowt
+
+			type Book struct {
+				table string ` + "`marlow:\"tableName=books\"`" + `
+				ID int ` + "`marlow:\"column=id\"`" + `
+			}
+			`
+
+			g.It("writes the stores of the package into the source of the first record", func() {
+				e := Compile(output, strings.NewReader(author), strings.NewReader(book))
+				g.Assert(e).Equal(nil)
+				generated := output.String()
+				g.Assert(strings.Contains(generated, "type Stores struct {\n\tAuthors AuthorStore\n\tBooks   BookStore\n}")).Equal(true)
+				g.Assert(strings.Contains(generated, "func (s *Stores) WithExecutor(_executor AuthorStoreExecutor) *Stores")).Equal(true)
+				g.Assert(strings.Contains(generated, "return _fn(s.WithExecutor(_tx))")).Equal(true)
+				g.Assert(strings.Contains(generated, "var marlowSavepoints = struct")).Equal(true)
+			})
+
+			g.It("does not write the stores of the package into the source of other records", func() {
+				e := Compile(output, strings.NewReader(book), strings.NewReader(author))
+				g.Assert(e).Equal(nil)
+				generated := output.String()
+				g.Assert(strings.Contains(generated, "type Stores struct")).Equal(false)
+				g.Assert(strings.Contains(generated, "var marlowSavepoints")).Equal(false)
+				g.Assert(strings.Contains(generated, "marlowEnterSavepoint(_executor)")).Equal(true)
+			})
+		})
+
 		g.It("returns an error if a field is mis-configured", func() {
 			source := strings.NewReader(`
 			package marlowt
@@ -117,5 +156,306 @@ func Test_Reader(t *testing.T) {
 			g.Assert(e.Error()).Equal("invalid-table")
 		})
 
+		g.It("returns an error if the unique key names a column that is not held by a field", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs&uniqueKey=name,missing\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("invalid unique key column for Construct: missing")
+		})
+
+		g.It("returns an error if the soft delete column is invalid", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs&softDelete=deleted-at\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("invalid soft delete column for Construct: deleted-at")
+		})
+
+		g.It("returns an error if a timestamp field is not a time.Time", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				CreatedAt int64 ` + "`marlow:\"column=created_at&autoCreateTime=true\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("timestamp field Construct.CreatedAt must be a time.Time, found int64")
+		})
+
+		g.It("returns an error if a version field is not an integer", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Version string ` + "`marlow:\"column=version&version=true\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("version field Construct.Version must be an integer, found string")
+		})
+
+		g.It("returns an error if multiple fields are flagged as the version", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Version int ` + "`marlow:\"column=version&version=true\"`" + `
+				Revision int ` + "`marlow:\"column=revision&version=true\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("record Construct has multiple version fields: Revision & Version")
+		})
+
+		g.It("generates the Validate method of records with validation rules", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Name string ` + "`marlow:\"column=name&validate=required,max=255\"`" + `
+				Size int ` + "`marlow:\"column=size&validate=min=0\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "func (c *Construct) Validate() []ConstructFieldError {")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "ConstructFieldError{Field: \"Size\", Rule: \"min=0\"}")).Equal(true)
+		})
+
+		g.It("returns an error if a validation rule is unknown", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Name string ` + "`marlow:\"column=name&validate=required,email\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("unknown validation rule \"email\" for Construct.Name")
+		})
+
+		g.It("returns an error if a validation rule is not supported by the type of the field", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			import "time"
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				CreatedAt time.Time ` + "`marlow:\"column=created_at&validate=max=10\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("validation rule max is not supported by Construct.CreatedAt of type time.Time")
+		})
+
+		g.It("returns an error if the bound of a validation rule is invalid", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Size int ` + "`marlow:\"column=size&validate=min=1.5\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("invalid min bound for Construct.Size: 1.5")
+		})
+
+		g.It("returns an error if a field references a record that is not declared in the package", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				AuthorID int ` + "`marlow:\"column=author&references=Author\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("unknown record \"Author\" referenced by Book.AuthorID")
+		})
+
+		g.It("resolves referenced records from the package sources", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				ID int ` + "`marlow:\"column=id\"`" + `
+				AuthorID int ` + "`marlow:\"column=author&references=Author\"`" + `
+			}
+			`)
+			author := strings.NewReader(`
+			package marlowt
+
+			type Author struct {
+				table string ` + "`marlow:\"primaryKey=id\"`" + `
+				ID int ` + "`marlow:\"column=id\"`" + `
+			}
+			`)
+			e := Compile(output, source, author)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "PreloadAuthors(_records []*Book) (map[int]*Author, error)")).Equal(true)
+		})
+
+		g.It("returns an error if the referenced record has no primary key", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Author struct {
+				ID int ` + "`marlow:\"column=id\"`" + `
+			}
+
+			type Book struct {
+				AuthorID int ` + "`marlow:\"column=author&references=Author\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("record Author referenced by Book.AuthorID has no primary key")
+		})
+
+		g.It("calls the lifecycle hooks declared on the record from its store", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+			`)
+			hooks := strings.NewReader(`
+			package marlowt
+
+			func (c *Construct) BeforeCreate() error { return nil }
+
+			func (c Construct) AfterFind() error { return nil }
+			`)
+			e := Compile(output, source, hooks)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "_records[_i].BeforeCreate()")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "_row.AfterFind()")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "BeforeUpdate()")).Equal(false)
+		})
+
+		g.It("returns an error if a lifecycle hook has an invalid signature", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+
+			func (c *Construct) BeforeDelete(force bool) error { return nil }
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("lifecycle hook Construct.BeforeDelete must have the signature func() error")
+		})
+
+		g.It("returns an error if a deletion hook is declared on a record that is not queryable", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs&queryable=false\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+
+			func (c *Construct) BeforeDelete() error { return nil }
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("lifecycle hook Construct.BeforeDelete requires the record to be queryable")
+		})
+
+		g.It("returns an error if an update hook is declared on an updateable record that is not queryable", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs&queryable=false\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+
+			func (c *Construct) BeforeUpdate() error { return nil }
+			`)
+			e := Compile(output, source)
+			g.Assert(e.Error()).Equal("lifecycle hook Construct.BeforeUpdate requires the record to be queryable")
+		})
+
+	})
+
+	g.Describe("CompileFakes", func() {
+		var output *bytes.Buffer
+
+		g.BeforeEach(func() {
+			output = new(bytes.Buffer)
+		})
+
+		g.It("fails if the provided source is invalid golang source", func() {
+			e := CompileFakes(output, strings.NewReader("}{"))
+			g.Assert(e == nil).Equal(false)
+		})
+
+		g.It("skips the source if the ignore directive comment is seen", func() {
+			source := strings.NewReader(`
+			package marlowt
+			// marlow:ignore
+			type Construct struct {
+				Name string
+			}
+			`)
+			e := CompileFakes(output, source)
+			g.Assert(e).Equal(nil)
+			g.Assert(output.Len()).Equal(0)
+		})
+
+		g.It("generates valid golang source holding the in-memory store of the record", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs&primaryKey=id\"`" + `
+				ID uint ` + "`marlow:\"column=id&autoIncrement=true\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+			`)
+			e := CompileFakes(output, source)
+			g.Assert(e).Equal(nil)
+			ts := token.NewFileSet()
+			_, e = parser.ParseFile(ts, "", output, parser.AllErrors)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "func NewFakeConstructStore(")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "type ConstructStore interface")).Equal(false)
+		})
+
+		g.It("generates methods returning errors for the apis the in-memory store does not support", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table string ` + "`marlow:\"tableName=constructs&primaryKey=id\"`" + `
+				ID uint ` + "`marlow:\"column=id\"`" + `
+			}
+			`)
+			e := CompileFakes(output, source)
+			g.Assert(e).Equal(nil)
+			expected := "IterateConstructs is not supported by the in-memory ConstructStore"
+			g.Assert(strings.Contains(output.String(), expected)).Equal(true)
+		})
 	})
 }
@@ -3,6 +3,7 @@ package marlow
 import "fmt"
 import "sort"
 import "strings"
+import "strconv"
 import "net/url"
 import "github.com/dadleyy/marlow/marlow/writing"
 import "github.com/dadleyy/marlow/marlow/constants"
@@ -16,19 +17,28 @@ type marlowRecord struct {
 	importRegistry map[string]bool
 
 	storeChannel chan writing.FuncDecl
+
+	registry recordRegistry
+
+	// hooks holds the lifecycle hook methods declared on the record type along with the validity of their signature.
+	hooks map[string]bool
 }
 
+// recordRegistry holds the records declared throughout a package by name, used to resolve the records that fields
+// reference.
+type recordRegistry map[string]marlowRecord
+
 func (r *marlowRecord) fieldList(filter func(url.Values) bool) fieldList {
 	list := make(fieldList, 0, len(r.fields))
 
 	for name, c := range r.fields {
-		column := fmt.Sprintf("%s.%s", r.table(), c.Get(constants.ColumnConfigOption))
+		bare := c.Get(constants.ColumnConfigOption)
 
 		if filter != nil && filter(c) != true {
 			continue
 		}
 
-		list = append(list, field{name: name, column: column})
+		list = append(list, field{name: name, column: r.columnReference(bare), bare: bare})
 	}
 
 	sort.Sort(list)
@@ -78,16 +88,77 @@ func (r *marlowRecord) primaryKeyColumn() string {
 	return ""
 }
 
+// softDeleteColumn returns the column set when records are soft deleted, empty when records are deleted outright.
+func (r *marlowRecord) softDeleteColumn() string {
+	return r.config.Get(constants.SoftDeleteConfigOption)
+}
+
+// uniqueKey returns the columns covered together by the record's composite unique constraint, in the order configured.
+func (r *marlowRecord) uniqueKey() []string {
+	key := r.config.Get(constants.UniqueKeyConfigOption)
+
+	if key == "" {
+		return nil
+	}
+
+	return strings.Split(key, ",")
+}
+
+// uniqueColumns returns the sorted columns flagged unique, each covered by a unique constraint of its own.
+func (r *marlowRecord) uniqueColumns() []string {
+	columns := make([]string, 0, len(r.fields))
+
+	for _, config := range r.fields {
+		if v, unique := config[constants.ColumnUniqueFlag]; unique && (len(v) == 0 || v[0] != "false") {
+			columns = append(columns, config.Get(constants.ColumnConfigOption))
+		}
+	}
+
+	sort.Strings(columns)
+	return columns
+}
+
+// primaryKeyField returns the name and config of the field holding the record's primary key column.
+func (r *marlowRecord) primaryKeyField() (string, url.Values, bool) {
+	primaryKey := r.primaryKeyColumn()
+
+	for name, config := range r.fields {
+		if primaryKey != "" && config.Get(constants.ColumnConfigOption) == primaryKey {
+			return name, config, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (r *marlowRecord) cursor() string {
+	return fmt.Sprintf("%s%s", r.name(), constants.CursorNameSuffix)
+}
+
+func (r *marlowRecord) notFoundError() string {
+	return fmt.Sprintf("%s%s", r.name(), constants.NotFoundErrorSuffix)
+}
+
 func (r *marlowRecord) external() string {
 	return r.config.Get(constants.StoreNameConfigOption)
 }
 
+func (r *marlowRecord) executor() string {
+	return fmt.Sprintf("%sExecutor", r.external())
+}
+
 func (r *marlowRecord) name() string {
 	return r.config.Get(constants.RecordNameConfigOption)
 }
 
-func (r *marlowRecord) dialect() string {
-	return r.config.Get(constants.DialectConfigOption)
+// dialect returns the registered dialect named by the record's config, falling back to the default dialect.
+func (r *marlowRecord) dialect() Dialect {
+	if dialect, ok := lookupDialect(r.config.Get(constants.DialectConfigOption)); ok {
+		return dialect
+	}
+
+	dialect, _ := lookupDialect(DefaultDialect)
+	return dialect
 }
 
 func (r *marlowRecord) store() string {
@@ -104,6 +175,46 @@ func (r *marlowRecord) table() string {
 	return r.config.Get(constants.TableNameConfigOption)
 }
 
+// quote wraps the identifier (table or column name) with the quoting characters used by the record's dialect.
+func (r *marlowRecord) quote(identifier string) string {
+	return r.dialect().QuoteIdentifier(identifier)
+}
+
+// columnReference returns the table-qualified reference to a column, quoted for the record's dialect.
+func (r *marlowRecord) columnReference(column string) string {
+	return fmt.Sprintf("%s.%s", r.quote(r.table()), r.quote(column))
+}
+
+// numberedPlaceholders returns true when the placeholders of the record's dialect depend on the value position, in which
+// case the order of the values sent alongside the query does not need to match the order they appear in.
+func (r *marlowRecord) numberedPlaceholders() bool {
+	_, argument := r.dialect().Placeholder("0")
+	return argument != ""
+}
+
+// placeholders returns a golang expression evaluating to the sql template with each "%s" verb replaced by the
+// placeholder of the record's dialect for the value at the corresponding position expression.
+func (r *marlowRecord) placeholders(template string, positions ...string) string {
+	verbs, arguments := make([]interface{}, 0, len(positions)), make([]string, 0, len(positions))
+
+	for _, position := range positions {
+		verb, argument := r.dialect().Placeholder(position)
+		verbs = append(verbs, verb)
+
+		if argument != "" {
+			arguments = append(arguments, argument)
+		}
+	}
+
+	query := strconv.Quote(fmt.Sprintf(template, verbs...))
+
+	if len(arguments) == 0 {
+		return query
+	}
+
+	return fmt.Sprintf("fmt.Sprintf(%s, %s)", query, strings.Join(arguments, ", "))
+}
+
 func (r *marlowRecord) blueprint() string {
 	return r.config.Get(constants.BlueprintNameConfigOption)
 }
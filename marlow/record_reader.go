@@ -33,6 +33,10 @@ func newRecordConfig(typeName string) url.Values {
 	config.Set(constants.BlueprintNameConfigOption, blueprintName)
 	config.Set(constants.BlueprintRangeFieldSuffixConfigOption, "Range")
 	config.Set(constants.BlueprintLikeFieldSuffixConfigOption, "Like")
+	config.Set(constants.BlueprintNotInFieldSuffixConfigOption, "NotIn")
+	config.Set(constants.BlueprintNotRangeFieldSuffixConfigOption, "NotRange")
+	config.Set(constants.BlueprintNotLikeFieldSuffixConfigOption, "NotLike")
+	config.Set(constants.BlueprintNullFieldSuffixConfigOption, "IsNull")
 
 	config.Set(constants.StoreFindMethodPrefixConfigOption, "Find")
 	config.Set(constants.StoreCountMethodPrefixConfigOption, "Count")
@@ -113,19 +117,13 @@ func parseStruct(d ast.Decl) (*ast.StructType, string, bool) {
 	return structType, typeName, true
 }
 
-func newRecordReader(root ast.Decl, imports chan<- string) (io.Reader, bool) {
-	structType, typeName, ok := parseStruct(root)
-
-	if !ok {
-		return nil, false
-	}
-
+// parseRecord reads the record-level and field-level configuration of a struct declaration, validating the column and
+// table names it produces. The record returned has no import or store method channels.
+func parseRecord(structType *ast.StructType, typeName string) (marlowRecord, error) {
 	recordConfig, recordFields := newRecordConfig(typeName), make(map[string]url.Values)
 
 	columnMap := make(map[string]string)
 
-	pr, pw := io.Pipe()
-
 	for _, f := range structType.Fields.List {
 		name, fieldConfig, ok := parseField(f)
 
@@ -156,37 +154,102 @@ func newRecordReader(root ast.Decl, imports chan<- string) (io.Reader, bool) {
 		}
 
 		if otherField, dupe := columnMap[columnName]; dupe == true {
-			pw.CloseWithError(fmt.Errorf("duplicate column \"%s\" for fields: %s & %s", columnName, otherField, name))
-			return pr, true
+			return marlowRecord{}, fmt.Errorf("duplicate column \"%s\" for fields: %s & %s", columnName, otherField, name)
 		}
 
 		columnMap[columnName] = name
 
 		if nameValidationRegex.MatchString(columnName) != true {
-			pw.CloseWithError(fmt.Errorf("invalid column name for %s: %s", name, columnName))
-			return pr, true
+			return marlowRecord{}, fmt.Errorf("invalid column name for %s: %s", name, columnName)
 		}
 
 		if e := parseFieldType(&fieldConfig, f); e != nil {
-			pw.CloseWithError(e)
-			return pr, true
+			return marlowRecord{}, e
 		}
 
 		recordFields[name] = fieldConfig
 	}
 
+	if e := validateRecordConfig(typeName, recordConfig, columnMap); e != nil {
+		return marlowRecord{}, e
+	}
+
+	if e := validateTimestamps(typeName, recordFields); e != nil {
+		return marlowRecord{}, e
+	}
+
+	if e := validateVersion(typeName, recordFields); e != nil {
+		return marlowRecord{}, e
+	}
+
+	if e := validateValidations(typeName, recordFields); e != nil {
+		return marlowRecord{}, e
+	}
+
+	return marlowRecord{config: recordConfig, fields: recordFields}, nil
+}
+
+// validateRecordConfig validates the record level configuration options read from the 'table' field of a record, along
+// with the columns held by its fields (keyed by column) that the options may refer to.
+func validateRecordConfig(typeName string, recordConfig url.Values, columns map[string]string) error {
 	if nameValidationRegex.MatchString(recordConfig.Get(constants.TableNameConfigOption)) != true {
-		pw.CloseWithError(fmt.Errorf("invalid-table"))
+		return fmt.Errorf("invalid-table")
+	}
+
+	if column := recordConfig.Get(constants.SoftDeleteConfigOption); column != "" && !nameValidationRegex.MatchString(column) {
+		return fmt.Errorf("invalid soft delete column for %s: %s", typeName, column)
+	}
+
+	if dialect := recordConfig.Get(constants.DialectConfigOption); dialect != "" {
+		if _, ok := lookupDialect(dialect); ok != true {
+			return fmt.Errorf("unknown dialect \"%s\" for record %s", dialect, typeName)
+		}
+	}
+
+	return validateUniqueKey(typeName, recordConfig, columns)
+}
+
+// validateUniqueKey returns an error if the composite unique key of the record names a column that is not held by any of
+// its fields, or names a column more than once.
+func validateUniqueKey(typeName string, recordConfig url.Values, columns map[string]string) error {
+	key := recordConfig.Get(constants.UniqueKeyConfigOption)
+
+	if key == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+
+	for _, column := range strings.Split(key, ",") {
+		if _, ok := columns[column]; !ok || seen[column] {
+			return fmt.Errorf("invalid unique key column for %s: %s", typeName, column)
+		}
+
+		seen[column] = true
+	}
+
+	return nil
+}
+
+// newRecordReader returns a reader that generates the marlow api for a struct declaration. The registry holds the
+// records of the declaration's package that fields may reference.
+func newRecordReader(root ast.Decl, imports chan<- string, registry recordRegistry) (io.Reader, bool) {
+	record, ok, e := loadRecord(root, registry)
+
+	if !ok {
+		return nil, false
+	}
+
+	pr, pw := io.Pipe()
+
+	if e != nil {
+		pw.CloseWithError(e)
 		return pr, true
 	}
 
 	go func() {
-		record := marlowRecord{
-			config:        recordConfig,
-			fields:        recordFields,
-			importChannel: imports,
-			storeChannel:  make(chan writing.FuncDecl),
-		}
+		record.importChannel = imports
+		record.storeChannel = make(chan writing.FuncDecl)
 
 		e := readRecord(pw, record)
 		pw.CloseWithError(e)
@@ -195,19 +258,75 @@ func newRecordReader(root ast.Decl, imports chan<- string) (io.Reader, bool) {
 	return pr, true
 }
 
+// loadRecord parses and validates the record of a struct declaration, returning false if the declaration is not a
+// struct. The registry holds the records of the declaration's package that fields may reference.
+func loadRecord(root ast.Decl, registry recordRegistry) (marlowRecord, bool, error) {
+	structType, typeName, ok := parseStruct(root)
+
+	if !ok {
+		return marlowRecord{}, false, nil
+	}
+
+	record, e := parseRecord(structType, typeName)
+
+	if e == nil {
+		record.registry, record.hooks = registry, registry[typeName].hooks
+		e = validateReferences(record)
+	}
+
+	if e == nil {
+		e = validateHooks(record)
+	}
+
+	if e == nil {
+		e = validateJoins(record)
+	}
+
+	return record, true, e
+}
+
 func readRecord(writer io.Writer, record marlowRecord) error {
+	readers := recordFeatures(record)
+
+	if len(readers) == 0 {
+		comment := strings.NewReader(
+			fmt.Sprintf("/* [marlow no-features]: %s */\n\n", record.config.Get(constants.RecordNameConfigOption)),
+		)
+
+		_, e := io.Copy(writer, comment)
+		return e
+	}
+
 	buffer := new(bytes.Buffer)
+	methods, e := collectFeatures(buffer, record, readers)
 
-	readers := make([]io.Reader, 0, 4)
+	if e != nil {
+		return e
+	}
 
-	features := map[string]func(marlowRecord) io.Reader{
-		constants.CreateableConfigOption: newCreateableGenerator,
-		constants.UpdateableConfigOption: newUpdateableGenerator,
-		constants.DeleteableConfigOption: newDeleteableGenerator,
-		constants.QueryableConfigOption:  newQueryableGenerator,
+	store := newStoreGenerator(record, methods)
+
+	if _, e := io.Copy(writer, io.MultiReader(buffer, store)); e != nil || record.hostsPackage() != true {
+		return e
 	}
 
-	for flag, generator := range features {
+	return writePackageStores(writer, record)
+}
+
+// featureGenerators holds the generators of the store apis that are enabled unless their config option is "false".
+var featureGenerators = map[string]func(marlowRecord) io.Reader{
+	constants.CreateableConfigOption: newCreateableGenerator,
+	constants.UpdateableConfigOption: newUpdateableGenerator,
+	constants.DeleteableConfigOption: newDeleteableGenerator,
+	constants.QueryableConfigOption:  newQueryableGenerator,
+}
+
+// recordFeatures returns the generators of the features enabled on the record along with the blueprint api they share,
+// or nothing if every feature was disabled.
+func recordFeatures(record marlowRecord) []io.Reader {
+	readers := make([]io.Reader, 0, 4)
+
+	for flag, generator := range featureGenerators {
 		v := record.config.Get(flag)
 
 		if v == "false" {
@@ -218,18 +337,32 @@ func readRecord(writer io.Writer, record marlowRecord) error {
 		readers = append(readers, g)
 	}
 
-	if len(readers) == 0 {
-		comment := strings.NewReader(
-			fmt.Sprintf("/* [marlow no-features]: %s */\n\n", record.config.Get(constants.RecordNameConfigOption)),
-		)
+	if record.config.Get(constants.JoinsConfigOption) != "" {
+		readers = append(readers, newJoinGenerator(record))
+	}
 
-		_, e := io.Copy(writer, comment)
-		return e
+	if len(readers) == 0 {
+		return nil
 	}
 
 	// If we had any features enabled, we need to also generate the blue print API.
-	readers = append(readers, newBlueprintGenerator(record))
+	return append(readers, newBlueprintGenerator(record), newValidationGenerator(record), newVerificationGenerator(record))
+}
+
+// featured returns true if any of the record's features are enabled, which is when its store is generated.
+func (r *marlowRecord) featured() bool {
+	for flag := range featureGenerators {
+		if r.config.Get(flag) != "false" {
+			return true
+		}
+	}
 
+	return r.config.Get(constants.JoinsConfigOption) != ""
+}
+
+// collectFeatures copies the code generated by the feature readers into the writer, returning the store methods that
+// were registered by them along the way.
+func collectFeatures(writer io.Writer, record marlowRecord, readers []io.Reader) (map[string]writing.FuncDecl, error) {
 	methods := make(map[string]writing.FuncDecl)
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
@@ -245,15 +378,29 @@ func readRecord(writer io.Writer, record marlowRecord) error {
 		wg.Done()
 	}()
 
-	// Iterate over all our collected features, copying them into the buffer
-	if _, e := io.Copy(buffer, io.MultiReader(readers...)); e != nil {
-		return e
+	// Iterate over all our collected features, copying them into the writer.
+	if _, e := io.Copy(writer, io.MultiReader(readers...)); e != nil {
+		return nil, e
 	}
 
 	close(record.storeChannel)
 	wg.Wait()
 
-	store := newStoreGenerator(record, methods)
-	_, e := io.Copy(writer, io.MultiReader(buffer, store))
-	return e
+	return methods, nil
+}
+
+// registerRecords adds the records declared by a parsed source to the registry. Declarations that do not produce a
+// valid record are left out; they are reported when the source declaring them is compiled.
+func registerRecords(registry recordRegistry, source *ast.File) {
+	for _, d := range source.Decls {
+		structType, typeName, ok := parseStruct(d)
+
+		if !ok {
+			continue
+		}
+
+		if record, e := parseRecord(structType, typeName); e == nil {
+			registry[typeName] = record
+		}
+	}
 }
@@ -42,7 +42,7 @@ func (s *recordReaderTestScaffold) close() {
 }
 
 func (s *recordReaderTestScaffold) error() error {
-	reader, _ := newRecordReader(s.root(), s.imports)
+	reader, _ := newRecordReader(s.root(), s.imports, nil)
 	_, e := io.Copy(s.output, reader)
 	return e
 }
@@ -91,7 +91,7 @@ func Test_RecordReader(t *testing.T) {
 				type Author struct {
 					Title string
 				}`)
-			reader, ok := newRecordReader(scaffold.root(), scaffold.imports)
+			reader, ok := newRecordReader(scaffold.root(), scaffold.imports, nil)
 			g.Assert(ok).Equal(true)
 			_, e := io.Copy(scaffold.output, reader)
 			scaffold.close()
@@ -104,7 +104,7 @@ func Test_RecordReader(t *testing.T) {
 					type Author struct {
 						Title string ` + "`marlow:\"column=title\"`" + `
 					}`)
-			reader, ok := newRecordReader(scaffold.root(), scaffold.imports)
+			reader, ok := newRecordReader(scaffold.root(), scaffold.imports, nil)
 			g.Assert(ok).Equal(true)
 			_, e := io.Copy(scaffold.output, reader)
 			scaffold.close()
@@ -118,7 +118,7 @@ func Test_RecordReader(t *testing.T) {
 						table string ` + "`marlow:\"tableName=authors\"`" + `
 						Title string
 					}`)
-			reader, ok := newRecordReader(scaffold.root(), scaffold.imports)
+			reader, ok := newRecordReader(scaffold.root(), scaffold.imports, nil)
 			g.Assert(ok).Equal(true)
 			_, e := io.Copy(scaffold.output, reader)
 			scaffold.close()
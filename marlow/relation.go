@@ -0,0 +1,395 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "strings"
+import "net/url"
+import "go/types"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type preloadSymbols struct {
+	records      string
+	record       string
+	preloaded    string
+	lookup       string
+	seen         string
+	key          string
+	store        string
+	cursor       string
+	cursorError  string
+	loaded       string
+	iterateError string
+}
+
+// preload describes a generated method that receives a slice of records and loads the records related to them using a
+// single lookup on the related record's store, keyed by the primary key of the referenced record.
+type preload struct {
+	name    string
+	comment string
+	source  marlowRecord
+	target  marlowRecord
+	keyType string
+	many    bool
+
+	// lookupField is the target blueprint field that the keys are looked up with, holding values of lookupType.
+	lookupField string
+	lookupType  string
+
+	// sourceKey and targetKey produce the key of a received and a loaded record; lookupValue converts a key into the
+	// lookup field's type. Received records are skipped when sourceSkip produces a true condition.
+	sourceKey   func(string) string
+	sourceSkip  func(string) string
+	targetKey   func(string) string
+	lookupValue func(string) string
+}
+
+// referenceKey returns the expression converting the value of a field (the reference) holding a primary key of the
+// provided type into that type. Nullable fields are expected to be checked for validity beforehand.
+func referenceKey(fieldType, keyType, reference string) (string, bool) {
+	if fieldType == keyType {
+		return reference, true
+	}
+
+	if getTypeInfo(keyType)&types.IsInteger == 0 {
+		return "", false
+	}
+
+	if fieldType == "sql.NullInt64" {
+		return fmt.Sprintf("%s(%s.Int64)", keyType, reference), true
+	}
+
+	if getTypeInfo(fieldType)&types.IsInteger == 0 {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s(%s)", keyType, reference), true
+}
+
+// referenceValue returns the expression converting a primary key into the type of a field referencing it.
+func referenceValue(fieldType, keyType, key string) string {
+	switch fieldType {
+	case keyType:
+		return key
+	case "sql.NullInt64":
+		return fmt.Sprintf("sql.NullInt64{Int64: int64(%s), Valid: true}", key)
+	}
+
+	return fmt.Sprintf("%s(%s)", fieldType, key)
+}
+
+// validateReferences ensures that every record referenced by the fields of a record is declared in the record's package,
+// can be queried and has a primary key that the referencing field is able to hold.
+func validateReferences(record marlowRecord) error {
+	for name, config := range record.fields {
+		reference := config.Get(constants.ReferencesConfigOption)
+
+		if reference == "" {
+			continue
+		}
+
+		target, ok := record.registry[reference]
+
+		if !ok {
+			return fmt.Errorf("unknown record \"%s\" referenced by %s.%s", reference, record.name(), name)
+		}
+
+		_, keyConfig, keyed := target.primaryKeyField()
+
+		if !keyed {
+			return fmt.Errorf("record %s referenced by %s.%s has no primary key", reference, record.name(), name)
+		}
+
+		if target.config.Get(constants.QueryableConfigOption) == "false" {
+			return fmt.Errorf("record %s referenced by %s.%s is not queryable", reference, record.name(), name)
+		}
+
+		if _, ok := referenceKey(config.Get("type"), keyConfig.Get("type"), name); !ok {
+			return fmt.Errorf(
+				"%s.%s (%s) is unable to hold the primary key of %s (%s)",
+				record.name(),
+				name,
+				config.Get("type"),
+				reference,
+				keyConfig.Get("type"),
+			)
+		}
+	}
+
+	return nil
+}
+
+// referenceName returns the name used for the records referenced by a field: the field name without its "ID" suffix,
+// falling back to the name of the referenced record.
+func referenceName(fieldName, reference string) string {
+	if name := strings.TrimSuffix(fieldName, "ID"); name != "" {
+		return name
+	}
+
+	return reference
+}
+
+// preloader returns a generator that writes the store method loading the records referenced by a field for a slice of
+// records, e.g. the authors of a slice of books. The referenced records are returned keyed by their primary key.
+func preloader(record marlowRecord, fieldName string, fieldConfig url.Values) io.Reader {
+	pr, pw := io.Pipe()
+	reference := fieldConfig.Get(constants.ReferencesConfigOption)
+	target, ok := record.registry[reference]
+
+	if reference == "" || !ok {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	keyField, keyConfig, _ := target.primaryKeyField()
+	fieldType, keyType := fieldConfig.Get("type"), keyConfig.Get("type")
+
+	spec := preload{
+		name:        fmt.Sprintf("Preload%s", inflector.Pluralize(referenceName(fieldName, reference))),
+		source:      record,
+		target:      target,
+		keyType:     keyType,
+		lookupField: keyField,
+		lookupType:  keyType,
+		sourceKey: func(r string) string {
+			key, _ := referenceKey(fieldType, keyType, fmt.Sprintf("%s.%s", r, fieldName))
+			return key
+		},
+		sourceSkip: func(r string) string {
+			if fieldType == "sql.NullInt64" {
+				return fmt.Sprintf(" || %s.%s.Valid == false", r, fieldName)
+			}
+
+			return ""
+		},
+		targetKey: func(r string) string {
+			return fmt.Sprintf("%s.%s", r, keyField)
+		},
+		lookupValue: func(key string) string {
+			return key
+		},
+	}
+
+	spec.comment = fmt.Sprintf(
+		"[marlow feature]: preload of the %s referenced by %s.%s",
+		inflector.Pluralize(reference),
+		record.name(),
+		fieldName,
+	)
+
+	go func() {
+		e := writePreload(writing.NewGoWriter(pw), spec)
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// reversePreloaders returns a generator that writes, for every field in the package referencing the record, the store
+// method loading the records holding a reference to each of a slice of records, e.g. the books of a slice of authors.
+// The referencing records are grouped by the primary key they reference.
+func reversePreloaders(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	keyField, keyConfig, keyed := record.primaryKeyField()
+	specs := make([]preload, 0)
+
+	names := make([]string, 0, len(record.registry))
+
+	for name := range record.registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		source := record.registry[name]
+
+		if !keyed || source.config.Get(constants.QueryableConfigOption) == "false" {
+			continue
+		}
+
+		for _, f := range source.fieldList(nil) {
+			if source.fields[f.name].Get(constants.ReferencesConfigOption) != record.name() {
+				continue
+			}
+
+			specs = append(specs, reversePreload(record, source, keyField, keyConfig.Get("type"), f.name))
+		}
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		for _, spec := range specs {
+			if e := writePreload(gosrc, spec); e != nil {
+				pw.CloseWithError(e)
+				return
+			}
+		}
+
+		pw.Close()
+	}()
+
+	return pr
+}
+
+// reversePreload describes the method loading the source records whose field references each of a slice of records.
+func reversePreload(record, source marlowRecord, keyField, keyType, fieldName string) preload {
+	fieldConfig := source.fields[fieldName]
+	fieldType := fieldConfig.Get("type")
+	pluralized := inflector.Pluralize(source.name())
+
+	return preload{
+		name:        fmt.Sprintf("Preload%sBy%s", pluralized, referenceName(fieldName, record.name())),
+		comment:     fmt.Sprintf("[marlow feature]: preload of the %s referencing %s by %s", pluralized, record.name(), fieldName),
+		source:      record,
+		target:      source,
+		keyType:     keyType,
+		many:        true,
+		lookupField: fieldName,
+		lookupType:  fieldType,
+		sourceKey: func(r string) string {
+			return fmt.Sprintf("%s.%s", r, keyField)
+		},
+		sourceSkip: func(string) string {
+			return ""
+		},
+		targetKey: func(r string) string {
+			key, _ := referenceKey(fieldType, keyType, fmt.Sprintf("%s.%s", r, fieldName))
+			return key
+		},
+		lookupValue: func(key string) string {
+			return referenceValue(fieldType, keyType, key)
+		},
+	}
+}
+
+// writePreload writes the store methods described by the preload provided.
+func writePreload(gosrc writing.GoWriter, spec preload) error {
+	symbols := preloadSymbols{
+		records:      "_records",
+		record:       "_record",
+		preloaded:    "_preloaded",
+		lookup:       "_lookup",
+		seen:         "_seen",
+		key:          "_key",
+		store:        "_store",
+		cursor:       "_cursor",
+		cursorError:  "_ce",
+		loaded:       "_loaded",
+		iterateError: "_ie",
+	}
+
+	record, target := spec.source, spec.target
+	resultType := fmt.Sprintf("*%s", target.name())
+
+	if spec.many {
+		resultType = fmt.Sprintf("[]*%s", target.name())
+	}
+
+	mapType := fmt.Sprintf("map[%s]%s", spec.keyType, resultType)
+
+	params := []writing.FuncParam{
+		{Symbol: symbols.records, Type: fmt.Sprintf("[]*%s", record.name())},
+	}
+
+	method := writing.FuncDecl{Name: spec.name, Params: params, Returns: []string{mapType, "error"}}
+
+	gosrc.Comment("%s", spec.comment)
+
+	e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+
+		gosrc.Println("%s, %s := make(%s), make(map[%s]bool)", symbols.preloaded, symbols.seen, mapType, spec.keyType)
+		gosrc.Println("%s := make([]%s, 0, len(%s))", symbols.lookup, spec.lookupType, symbols.records)
+
+		// Every key is only looked up once, regardless of the number of records holding it.
+		e := gosrc.WithIter("_, %s := range %s", func(url.Values) error {
+			gosrc.WithIf("%s == nil%s", func(url.Values) error {
+				return gosrc.Println("continue")
+			}, symbols.record, spec.sourceSkip(symbols.record))
+
+			gosrc.Println("%s := %s", symbols.key, spec.sourceKey(symbols.record))
+
+			gosrc.WithIf("%s[%s]", func(url.Values) error {
+				return gosrc.Println("continue")
+			}, symbols.seen, symbols.key)
+
+			gosrc.Println("%s[%s] = true", symbols.seen, symbols.key)
+			value := spec.lookupValue(symbols.key)
+			return gosrc.Println("%s = append(%s, %s)", symbols.lookup, symbols.lookup, value)
+		}, symbols.record, symbols.records)
+
+		if e != nil {
+			return e
+		}
+
+		gosrc.WithIf("len(%s) == 0", func(url.Values) error {
+			return gosrc.Returns(symbols.preloaded, writing.Nil)
+		}, symbols.lookup)
+
+		gosrc.Println(
+			"%s := New%s(%s.%s, %s.%s)",
+			symbols.store,
+			target.external(),
+			receiver,
+			record.executor(),
+			receiver,
+			constants.StoreLoggerField,
+		)
+
+		return writePreloadIteration(gosrc, spec, symbols)
+	})
+
+	if e == nil && spec.lookupType == "sql.NullInt64" {
+		record.registerImports("database/sql")
+	}
+
+	return e
+}
+
+// writePreloadIteration writes the portion of a preload method that iterates over the related records matching the
+// lookup, adding each one to the preloaded map.
+func writePreloadIteration(gosrc writing.GoWriter, spec preload, symbols preloadSymbols) error {
+	gosrc.Println(
+		"%s, %s := %s.Iterate%sContext(%s, &%s{%s: %s})",
+		symbols.cursor,
+		symbols.cursorError,
+		symbols.store,
+		inflector.Pluralize(spec.target.name()),
+		contextSymbol,
+		spec.target.blueprint(),
+		spec.lookupField,
+		symbols.lookup,
+	)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, symbols.cursorError)
+	}, symbols.cursorError)
+
+	gosrc.Println("defer %s.Close()", symbols.cursor)
+
+	e := gosrc.WithIter("%s.Next()", func(url.Values) error {
+		gosrc.Println("%s := %s.Record()", symbols.loaded, symbols.cursor)
+		gosrc.Println("%s := %s", symbols.key, spec.targetKey(symbols.loaded))
+
+		if spec.many {
+			item := fmt.Sprintf("%s[%s]", symbols.preloaded, symbols.key)
+			return gosrc.Println("%s = append(%s, %s)", item, item, symbols.loaded)
+		}
+
+		return gosrc.Println("%s[%s] = %s", symbols.preloaded, symbols.key, symbols.loaded)
+	}, symbols.cursor)
+
+	if e != nil {
+		return e
+	}
+
+	gosrc.WithIf("%s := %s.Err(); %s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, symbols.iterateError)
+	}, symbols.iterateError, symbols.cursor, symbols.iterateError)
+
+	return gosrc.Returns(symbols.preloaded, writing.Nil)
+}
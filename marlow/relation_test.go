@@ -0,0 +1,205 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sync"
+import "bytes"
+import "strings"
+import "testing"
+import "net/url"
+import "go/token"
+import "go/parser"
+import "github.com/franela/goblin"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type relationTestScaffold struct {
+	buffer *bytes.Buffer
+
+	imports chan string
+	methods chan writing.FuncDecl
+
+	registry recordRegistry
+
+	received   map[string]bool
+	registered map[string]bool
+	closed     bool
+	wg         *sync.WaitGroup
+}
+
+func (s *relationTestScaffold) close() {
+	if s == nil || s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.imports)
+	close(s.methods)
+	s.wg.Wait()
+}
+
+func (s *relationTestScaffold) record(name string) marlowRecord {
+	record := s.registry[name]
+	record.registry = s.registry
+	record.importChannel = s.imports
+	record.storeChannel = s.methods
+	return record
+}
+
+func (s *relationTestScaffold) add(name, primaryKey string, fields map[string]url.Values) {
+	config := newRecordConfig(name)
+	config.Set(constants.PrimaryKeyColumnConfigOption, primaryKey)
+	s.registry[name] = marlowRecord{config: config, fields: fields}
+}
+
+func Test_Relation(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	var scaffold *relationTestScaffold
+
+	g.Describe("relationship feature generator test suite", func() {
+		g.BeforeEach(func() {
+			scaffold = &relationTestScaffold{
+				buffer:     new(bytes.Buffer),
+				wg:         &sync.WaitGroup{},
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				registry:   make(recordRegistry),
+				received:   make(map[string]bool),
+				registered: make(map[string]bool),
+			}
+
+			scaffold.wg.Add(2)
+
+			go func() {
+				for method := range scaffold.methods {
+					scaffold.registered[method.Name] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			go func() {
+				for i := range scaffold.imports {
+					scaffold.received[i] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			scaffold.add("Author", "id", map[string]url.Values{
+				"ID": {"type": {"int"}, constants.ColumnConfigOption: {"id"}},
+			})
+
+			scaffold.add("Book", "id", map[string]url.Values{
+				"ID": {"type": {"int"}, constants.ColumnConfigOption: {"id"}},
+				"AuthorID": {
+					"type":                           {"int"},
+					constants.ColumnConfigOption:     {"author_id"},
+					constants.ReferencesConfigOption: {"Author"},
+				},
+				"EditorID": {
+					"type":                           {"sql.NullInt64"},
+					constants.ColumnConfigOption:     {"editor_id"},
+					constants.ReferencesConfigOption: {"Author"},
+				},
+			})
+		})
+
+		g.AfterEach(func() {
+			scaffold.close()
+		})
+
+		g.Describe("preloader", func() {
+			g.It("generates valid golang", func() {
+				fmt.Fprintln(scaffold.buffer, "package marlowt")
+				book := scaffold.record("Book")
+				_, e := io.Copy(scaffold.buffer, preloader(book, "AuthorID", book.fields["AuthorID"]))
+				g.Assert(e).Equal(nil)
+				_, e = parser.ParseFile(token.NewFileSet(), "", scaffold.buffer, parser.AllErrors)
+				g.Assert(e).Equal(nil)
+			})
+
+			g.It("writes nothing for fields without a reference", func() {
+				book := scaffold.record("Book")
+				_, e := io.Copy(scaffold.buffer, preloader(book, "ID", book.fields["ID"]))
+				g.Assert(e).Equal(nil)
+				g.Assert(scaffold.buffer.Len()).Equal(0)
+			})
+
+			g.It("registers the preload named after the field with the store", func() {
+				book := scaffold.record("Book")
+				io.Copy(scaffold.buffer, preloader(book, "AuthorID", book.fields["AuthorID"]))
+				io.Copy(scaffold.buffer, preloader(book, "EditorID", book.fields["EditorID"]))
+				scaffold.close()
+				g.Assert(scaffold.registered["PreloadAuthors"]).Equal(true)
+				g.Assert(scaffold.registered["PreloadEditorsContext"]).Equal(true)
+			})
+
+			g.It("looks up the referenced records by primary key using their store's iterator", func() {
+				book := scaffold.record("Book")
+				io.Copy(scaffold.buffer, preloader(book, "AuthorID", book.fields["AuthorID"]))
+				output := scaffold.buffer.String()
+				g.Assert(strings.Contains(output, "_store := NewAuthorStore(b.BookStoreExecutor, b.logger)")).Equal(true)
+				g.Assert(strings.Contains(output, "_store.IterateAuthorsContext(_ctx, &AuthorBlueprint{ID: _lookup})")).Equal(true)
+				g.Assert(strings.Contains(output, "_preloaded[_key] = _loaded")).Equal(true)
+			})
+
+			g.It("skips NULL references held by nullable fields", func() {
+				book := scaffold.record("Book")
+				io.Copy(scaffold.buffer, preloader(book, "EditorID", book.fields["EditorID"]))
+				output := scaffold.buffer.String()
+				g.Assert(strings.Contains(output, "if _record == nil || _record.EditorID.Valid == false {")).Equal(true)
+				g.Assert(strings.Contains(output, "_key := int(_record.EditorID.Int64)")).Equal(true)
+			})
+		})
+
+		g.Describe("reversePreloaders", func() {
+			g.It("generates valid golang", func() {
+				fmt.Fprintln(scaffold.buffer, "package marlowt")
+				_, e := io.Copy(scaffold.buffer, reversePreloaders(scaffold.record("Author")))
+				g.Assert(e).Equal(nil)
+				_, e = parser.ParseFile(token.NewFileSet(), "", scaffold.buffer, parser.AllErrors)
+				g.Assert(e).Equal(nil)
+			})
+
+			g.It("registers a preload for every field referencing the record", func() {
+				io.Copy(scaffold.buffer, reversePreloaders(scaffold.record("Author")))
+				scaffold.close()
+				g.Assert(scaffold.registered["PreloadBooksByAuthor"]).Equal(true)
+				g.Assert(scaffold.registered["PreloadBooksByEditor"]).Equal(true)
+				g.Assert(scaffold.received["database/sql"]).Equal(true)
+			})
+
+			g.It("groups the referencing records by the primary key they reference", func() {
+				io.Copy(scaffold.buffer, reversePreloaders(scaffold.record("Author")))
+				output := scaffold.buffer.String()
+				g.Assert(strings.Contains(output, "(map[int][]*Book,error)")).Equal(true)
+				g.Assert(strings.Contains(output, "_lookup = append(_lookup, sql.NullInt64{Int64: int64(_key), Valid: true})")).Equal(true)
+				g.Assert(strings.Contains(output, "_preloaded[_key] = append(_preloaded[_key], _loaded)")).Equal(true)
+			})
+
+			g.It("writes nothing for records that are not referenced", func() {
+				_, e := io.Copy(scaffold.buffer, reversePreloaders(scaffold.record("Book")))
+				g.Assert(e).Equal(nil)
+				g.Assert(scaffold.buffer.Len()).Equal(0)
+			})
+		})
+
+		g.Describe("validateReferences", func() {
+			g.It("succeeds when the referenced record is registered", func() {
+				g.Assert(validateReferences(scaffold.record("Book"))).Equal(nil)
+			})
+
+			g.It("fails when the referencing field is unable to hold the primary key", func() {
+				scaffold.registry["Book"].fields["AuthorID"].Set("type", "string")
+				e := validateReferences(scaffold.record("Book"))
+				g.Assert(e.Error()).Equal("Book.AuthorID (string) is unable to hold the primary key of Author (int)")
+			})
+
+			g.It("fails when the referenced record is not queryable", func() {
+				scaffold.registry["Author"].config.Set(constants.QueryableConfigOption, "false")
+				e := validateReferences(scaffold.record("Book"))
+				g.Assert(e == nil).Equal(false)
+			})
+		})
+	})
+}
@@ -0,0 +1,201 @@
+package marlow
+
+import "io"
+import "fmt"
+import "bytes"
+import "strings"
+import "go/ast"
+import "go/token"
+import "go/parser"
+import "go/types"
+import "net/url"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+// CompileSchema reads from a source and writes the CREATE TABLE statements of the records it declares into the
+// destination, each in the dialect of its record. When a dialect name is provided, only the records using it are
+// written. The package sources are the other files of the source's package, as they are for Compile.
+func CompileSchema(destination io.Writer, dialect string, reader io.Reader, packageSources ...io.Reader) error {
+	fs := token.NewFileSet()
+	packageAst, e := parser.ParseFile(fs, "", reader, parser.AllErrors|parser.ParseComments)
+
+	if e != nil {
+		return e
+	}
+
+	// Check to see if we are ignoring this source via the comments.
+	if hasDirective(packageAst, constants.IgnoreSourceDirective) {
+		return nil
+	}
+
+	registry, statements := newPackageRegistry(fs, packageAst, packageSources...), new(bytes.Buffer)
+
+	for _, d := range packageAst.Decls {
+		record, ok, e := loadRecord(d, registry)
+
+		if e != nil {
+			return e
+		}
+
+		// Only deal with struct declarations holding marlow fields.
+		if !ok || len(record.fields) == 0 {
+			continue
+		}
+
+		recordDialect := record.config.Get(constants.DialectConfigOption)
+
+		if recordDialect == "" {
+			recordDialect = DefaultDialect
+		}
+
+		if dialect != "" && recordDialect != dialect {
+			continue
+		}
+
+		structType, _, _ := parseStruct(d)
+
+		if e := writeSchema(statements, record, schemaColumns(structType, record)); e != nil {
+			return e
+		}
+	}
+
+	_, e = io.Copy(destination, statements)
+	return e
+}
+
+// NewSchemaReaderFromFile opens the requested filename and returns an io.Reader that represents the CREATE TABLE
+// statements of the records it declares, see CompileSchema.
+func NewSchemaReaderFromFile(filename string, dialect string) (io.Reader, error) {
+	return newFileReader(filename, func(w io.Writer, r io.Reader, packageSources ...io.Reader) error {
+		return CompileSchema(w, dialect, r, packageSources...)
+	})
+}
+
+// schemaColumns returns the names of the record's fields in the order they are declared by the struct.
+func schemaColumns(structType *ast.StructType, record marlowRecord) []string {
+	names := make([]string, 0, len(record.fields))
+
+	for _, f := range structType.Fields.List {
+		if len(f.Names) != 1 {
+			continue
+		}
+
+		if _, ok := record.fields[f.Names[0].String()]; ok {
+			names = append(names, f.Names[0].String())
+		}
+	}
+
+	return names
+}
+
+// writeSchema writes the CREATE TABLE statement of the record followed by the CREATE INDEX statements of the columns
+// flagged with an index. Columns flagged unique are covered by a constraint each, the record's unique key by another.
+func writeSchema(w io.Writer, record marlowRecord, names []string) error {
+	definitions, indexed := make([]string, 0, len(names)+2), make([]string, 0)
+	softDelete := record.softDeleteColumn()
+
+	for _, name := range names {
+		config := record.fields[name]
+		column := config.Get(constants.ColumnConfigOption)
+		definition, e := columnDefinition(record, config)
+
+		if e != nil {
+			return fmt.Errorf("unable to define the column of %s.%s: %v", record.name(), name, e)
+		}
+
+		if column == softDelete {
+			softDelete = ""
+		}
+
+		if config.Get(constants.ColumnIndexFlag) != "" {
+			indexed = append(indexed, column)
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	// Soft deleted records are marked by a timestamp column that is not held by any of their fields.
+	if softDelete != "" {
+		columnType, e := record.columnType("time.Time", false)
+
+		if e != nil {
+			return fmt.Errorf("unable to define the soft delete column of %s: %v", record.name(), e)
+		}
+
+		definitions = append(definitions, fmt.Sprintf("%s %s", record.quote(softDelete), columnType))
+	}
+
+	definitions = append(definitions, uniqueConstraints(record)...)
+
+	table := record.quote(record.table())
+	fmt.Fprintf(w, "CREATE TABLE %s (\n  %s\n);\n\n", table, strings.Join(definitions, ",\n  "))
+
+	for _, column := range indexed {
+		index := record.quote(fmt.Sprintf("%s_%s_index", record.table(), column))
+		fmt.Fprintf(w, "CREATE INDEX %s ON %s (%s);\n\n", index, table, record.quote(column))
+	}
+
+	return nil
+}
+
+// uniqueConstraints returns the UNIQUE constraints of the record's table: one for each column flagged unique followed by
+// the one of the record's unique key, unless the key is a single column already flagged.
+func uniqueConstraints(record marlowRecord) []string {
+	flagged, constraints := make(map[string]bool), make([]string, 0)
+
+	for _, column := range record.uniqueColumns() {
+		flagged[column] = true
+		constraints = append(constraints, fmt.Sprintf("UNIQUE (%s)", record.quote(column)))
+	}
+
+	if key := record.uniqueKey(); len(key) == 0 || (len(key) == 1 && flagged[key[0]]) {
+		return constraints
+	}
+
+	key := record.uniqueKey()
+	quoted := make([]string, 0, len(key))
+
+	for _, column := range key {
+		quoted = append(quoted, record.quote(column))
+	}
+
+	return append(constraints, fmt.Sprintf("UNIQUE (%s)", strings.Join(quoted, ", ")))
+}
+
+// columnDefinition returns the definition of the field's column within the CREATE TABLE statement of the record. Fields
+// are NOT NULL unless they hold one of the sql.Null* types.
+func columnDefinition(record marlowRecord, config url.Values) (string, error) {
+	column, fieldType := config.Get(constants.ColumnConfigOption), config.Get("type")
+	integer := fieldType != "time.Time" && getTypeInfo(fieldType)&types.IsInteger != 0
+	serial := config.Get(constants.ColumnAutoIncrementFlag) != "" && integer
+	columnType, e := record.columnType(fieldType, serial)
+
+	if e != nil {
+		return "", e
+	}
+
+	parts := []string{record.quote(column), columnType}
+
+	switch {
+	case column == record.primaryKeyColumn():
+		parts = append(parts, "PRIMARY KEY")
+	case strings.HasPrefix(fieldType, "sql.Null") != true:
+		parts = append(parts, "NOT NULL")
+	}
+
+	if value := config.Get(constants.ColumnDefaultOption); value != "" {
+		parts = append(parts, "DEFAULT", value)
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// columnType returns the type of the columns holding the field type provided, as defined by the record's dialect.
+func (r *marlowRecord) columnType(fieldType string, serial bool) (string, error) {
+	dialect, ok := r.dialect().(SchemaDialect)
+
+	if !ok {
+		return "", fmt.Errorf("the dialect of %s does not define column types", r.name())
+	}
+
+	return dialect.ColumnType(fieldType, serial)
+}
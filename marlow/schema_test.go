@@ -0,0 +1,180 @@
+package marlow
+
+import "bytes"
+import "strings"
+import "testing"
+import "github.com/franela/goblin"
+
+func Test_Schema(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	g.Describe("CompileSchema", func() {
+		var output *bytes.Buffer
+
+		g.BeforeEach(func() {
+			output = new(bytes.Buffer)
+		})
+
+		g.It("fails if the provided source is invalid golang source", func() {
+			e := CompileSchema(output, "", strings.NewReader("}{"))
+			g.Assert(e == nil).Equal(false)
+		})
+
+		g.It("writes nothing for structs without marlow fields", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				Name string
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			g.Assert(output.Len()).Equal(0)
+		})
+
+		g.It("writes the columns of the record in the order they are declared", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				table    bool          ` + "`marlow:\"tableName=books&primaryKey=id\"`" + `
+				ID       int           ` + "`marlow:\"column=id&autoIncrement=true\"`" + `
+				Title    string        ` + "`marlow:\"column=title&default='untitled'\"`" + `
+				SeriesID sql.NullInt64 ` + "`marlow:\"column=series_id\"`" + `
+				Rating   float64       ` + "`marlow:\"column=rating\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			expected := strings.Join([]string{
+				"CREATE TABLE books (",
+				"  id INTEGER PRIMARY KEY,",
+				"  title TEXT NOT NULL DEFAULT 'untitled',",
+				"  series_id INTEGER,",
+				"  rating REAL NOT NULL",
+				");",
+				"",
+				"",
+			}, "\n")
+			g.Assert(output.String()).Equal(expected)
+		})
+
+		g.It("uses the serial and quoting of the record's dialect", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				table bool   ` + "`marlow:\"tableName=books&primaryKey=id&dialect=mysql\"`" + `
+				ID    uint   ` + "`marlow:\"column=id&autoIncrement=true\"`" + `
+				Title string ` + "`marlow:\"column=title\"`" + `
+			}
+
+			type Genre struct {
+				table bool   ` + "`marlow:\"tableName=genres&primaryKey=id&dialect=postgres\"`" + `
+				ID    int32  ` + "`marlow:\"column=id&autoIncrement=true\"`" + `
+				Name  string ` + "`marlow:\"column=name&autoIncrement=true&default='fiction'\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "`id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "id SERIAL PRIMARY KEY")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "name TEXT NOT NULL DEFAULT 'fiction'")).Equal(true)
+		})
+
+		g.It("only writes the records of the dialect provided", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				table bool   ` + "`marlow:\"tableName=books\"`" + `
+				Title string ` + "`marlow:\"column=title\"`" + `
+			}
+
+			type Genre struct {
+				table bool   ` + "`marlow:\"tableName=genres&dialect=postgres\"`" + `
+				Name  string ` + "`marlow:\"column=name\"`" + `
+			}
+			`)
+			e := CompileSchema(output, DefaultDialect, source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "CREATE TABLE books")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "CREATE TABLE genres")).Equal(false)
+		})
+
+		g.It("covers each unique column with a constraint and writes the indexes of indexed columns", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Author struct {
+				table bool   ` + "`marlow:\"tableName=authors\"`" + `
+				Email string ` + "`marlow:\"column=email&unique=true&index=true\"`" + `
+				Login string ` + "`marlow:\"column=login&unique=true\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "  UNIQUE (email),\n  UNIQUE (login)\n);")).Equal(true)
+			expected := "CREATE INDEX authors_email_index ON authors (email);"
+			g.Assert(strings.Contains(output.String(), expected)).Equal(true)
+		})
+
+		g.It("covers the columns of the unique key with a single constraint", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type BookAuthor struct {
+				table    bool ` + "`marlow:\"tableName=book_authors&uniqueKey=book_id,author_id\"`" + `
+				BookID   int  ` + "`marlow:\"column=book_id\"`" + `
+				AuthorID int  ` + "`marlow:\"column=author_id\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "  UNIQUE (book_id, author_id)\n);")).Equal(true)
+			g.Assert(strings.Count(output.String(), "UNIQUE")).Equal(1)
+		})
+
+		g.It("does not repeat the constraint of a unique column named by the unique key", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Author struct {
+				table bool   ` + "`marlow:\"tableName=authors&uniqueKey=email\"`" + `
+				Email string ` + "`marlow:\"column=email&unique=true\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Count(output.String(), "UNIQUE (email)")).Equal(1)
+		})
+
+		g.It("adds the soft delete column of records that do not hold it in a field", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Series struct {
+				table bool   ` + "`marlow:\"tableName=series&softDelete=deleted_at\"`" + `
+				Title string ` + "`marlow:\"column=title\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "  deleted_at DATETIME\n);")).Equal(true)
+		})
+
+		g.It("returns an error if the type of a field has no column type in the record's dialect", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Book struct {
+				table   bool       ` + "`marlow:\"tableName=books\"`" + `
+				Payload complex128 ` + "`marlow:\"column=payload\"`" + `
+			}
+			`)
+			e := CompileSchema(output, "", source)
+			g.Assert(e.Error()).Equal("unable to define the column of Book.Payload: no column type for complex128")
+		})
+	})
+}
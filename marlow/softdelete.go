@@ -0,0 +1,80 @@
+package marlow
+
+import "fmt"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+
+// writeSoftDeleteMethods writes the Restore and Purge methods of records configured with soft deletes. Both operate
+// on the soft deleted records matched by the blueprint provided and, like the deletion api, return an error when the
+// blueprint is nil or generates no limiting clauses.
+func writeSoftDeleteMethods(gosrc writing.GoWriter, record marlowRecord, symbols deleteableSymbols) error {
+	plural := inflector.Pluralize(record.name())
+	table, column := record.quote(record.table()), record.quote(record.softDeleteColumn())
+
+	commands := []struct {
+		name    string
+		command string
+	}{
+		{fmt.Sprintf("Restore%s", plural), fmt.Sprintf("UPDATE %s SET %s = NULL", table, column)},
+		{fmt.Sprintf("Purge%s", plural), fmt.Sprintf("DELETE FROM %s", table)},
+	}
+
+	params := []writing.FuncParam{
+		{Type: fmt.Sprintf("*%s", record.blueprint()), Symbol: symbols.blueprint},
+	}
+
+	for _, c := range commands {
+		command := c.command
+		gosrc.Comment("[marlow] soft delete %s", c.name)
+		method := writing.FuncDecl{Name: c.name, Params: params, Returns: []string{"int64", "error"}}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+			receiver := scope.Get("receiver")
+			logwriter := logWriter{receiver: receiver, output: gosrc}
+
+			writeDeletionGuard(gosrc, record, symbols)
+			writeCursorCheck(gosrc, record, symbols.blueprint, "-1")
+			writeDeletedScope(gosrc, record, symbols)
+
+			return writeDeletionExec(gosrc, logwriter, receiver, command, symbols)
+		})
+
+		if e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// writeDeletedScope replaces the blueprint symbol with a copy of the blueprint limited to soft deleted records.
+func writeDeletedScope(gosrc writing.GoWriter, record marlowRecord, symbols deleteableSymbols) {
+	gosrc.Println("%s := %s{}", symbols.scoped, record.blueprint())
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Println("%s = *%s", symbols.scoped, symbols.blueprint)
+	}, symbols.blueprint)
+
+	gosrc.Println(
+		"%s.%s, %s.%s = false, true",
+		symbols.scoped,
+		blueprintIncludeDeletedField,
+		symbols.scoped,
+		blueprintOnlyDeletedField,
+	)
+
+	gosrc.Println("%s = &%s", symbols.blueprint, symbols.scoped)
+}
+
+// writeDeletedDefault writes the replacement of nil blueprints with empty ones for records configured with soft deletes
+// so that lookups without a blueprint still exclude the soft deleted records.
+func writeDeletedDefault(gosrc writing.GoWriter, record marlowRecord, blueprint string) {
+	if record.softDeleteColumn() == "" {
+		return
+	}
+
+	gosrc.WithIf("%s == nil", func(url.Values) error {
+		return gosrc.Println("%s = &%s{}", blueprint, record.blueprint())
+	}, blueprint)
+}
@@ -10,9 +10,30 @@ import "github.com/dadleyy/marlow/marlow/constants"
 func writeStore(destination io.Writer, record marlowRecord, storeMethods map[string]writing.FuncDecl) error {
 	out := writing.NewGoWriter(destination)
 
-	e := out.WithStruct(record.store(), func(url.Values) error {
-		out.Println("*sql.DB")
+	if len(record.external()) == 0 {
+		return fmt.Errorf("invalid store name for record %s", record.name())
+	}
+
+	out.Comment("%s is satisfied by both *sql.DB and *sql.Tx, allowing stores to operate inside transactions.", record.executor())
+
+	e := out.WithInterface(record.executor(), func(url.Values) error {
+		out.Println("PrepareContext(context.Context, string) (*sql.Stmt, error)")
+		out.Println("ExecContext(context.Context, string, ...interface{}) (sql.Result, error)")
+		return nil
+	})
+
+	if e != nil {
+		return e
+	}
+
+	e = out.WithStruct(record.store(), func(url.Values) error {
+		out.Println("%s", record.executor())
 		out.Println("%s io.Writer", constants.StoreLoggerField)
+
+		if record.stamped() {
+			out.Println("%s func() time.Time", constants.StoreClockField)
+		}
+
 		return nil
 	})
 
@@ -23,10 +44,16 @@ func writeStore(destination io.Writer, record marlowRecord, storeMethods map[str
 	symbols := struct {
 		dbParam     string
 		queryLogger string
-	}{"_db", "_logger"}
+		executor    string
+		callback    string
+		transaction string
+		beginError  string
+		execError   string
+		copied      string
+	}{"_db", "_logger", "_executor", "_fn", "_tx", "_be", "_se", "_copy"}
 
 	params := []writing.FuncParam{
-		{Type: "*sql.DB", Symbol: symbols.dbParam},
+		{Type: record.executor(), Symbol: symbols.dbParam},
 		{Type: "io.Writer", Symbol: symbols.queryLogger},
 	}
 
@@ -37,9 +64,22 @@ func writeStore(destination io.Writer, record marlowRecord, storeMethods map[str
 			return out.Println("%s, _ = os.Open(os.DevNull)", symbols.queryLogger)
 		}, symbols.queryLogger)
 
+		if record.stamped() {
+			return out.Println(
+				"return &%s{%s: %s, %s: %s, %s: time.Now}",
+				record.store(),
+				record.executor(),
+				symbols.dbParam,
+				constants.StoreLoggerField,
+				symbols.queryLogger,
+				constants.StoreClockField,
+			)
+		}
+
 		return out.Println(
-			"return &%s{DB: %s, %s: %s}",
+			"return &%s{%s: %s, %s: %s}",
 			record.store(),
+			record.executor(),
 			symbols.dbParam,
 			constants.StoreLoggerField,
 			symbols.queryLogger,
@@ -50,6 +90,86 @@ func writeStore(destination io.Writer, record marlowRecord, storeMethods map[str
 		return e
 	}
 
+	// Stores are able to produce copies of themselves bound to a different executor (typically a *sql.Tx).
+	binder := writing.FuncDecl{
+		Name:    "WithExecutor",
+		Params:  []writing.FuncParam{{Type: record.executor(), Symbol: symbols.executor}},
+		Returns: []string{record.external()},
+	}
+
+	out.Comment("[marlow] executor binding for %s", record.store())
+
+	e = out.WithMethod(binder.Name, record.store(), binder.Params, binder.Returns, func(scope url.Values) error {
+		out.Println("%s := *%s", symbols.copied, scope.Get("receiver"))
+		out.Println("%s.%s = %s", symbols.copied, record.executor(), symbols.executor)
+		return out.Returns(fmt.Sprintf("&%s", symbols.copied))
+	})
+
+	if e != nil {
+		return e
+	}
+
+	storeMethods[binder.Name] = binder
+
+	if e := writeClockBinder(out, record, storeMethods); e != nil {
+		return e
+	}
+
+	transaction := writing.FuncDecl{
+		Name: "Transaction",
+		Params: []writing.FuncParam{
+			{Type: fmt.Sprintf("func(%s, %s) error", record.external(), record.executor()), Symbol: symbols.callback},
+		},
+		Returns: []string{"error"},
+	}
+
+	out.Comment("[marlow] transaction helper for %s", record.store())
+
+	// The transaction helper will begin a new transaction when the store is backed by a *sql.DB, or create a savepoint
+	// when the store is already bound to a *sql.Tx, handing the callback a store bound to the transaction.
+	contextual, e := writeContextVariants(out, record, transaction, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		executorReference := fmt.Sprintf("%s.%s", receiver, record.executor())
+
+		out.Println("switch %s := %s.(type) {", symbols.executor, executorReference)
+		out.Println("case *sql.DB:")
+		out.Println("%s, %s := %s.BeginTx(%s, nil)", symbols.transaction, symbols.beginError, symbols.executor, contextSymbol)
+
+		out.WithIf("%s != nil", func(url.Values) error {
+			return out.Returns(symbols.beginError)
+		}, symbols.beginError)
+
+		// Rolling back a committed transaction is a no-op, this covers both callback errors and panics.
+		out.Println("defer %s.Rollback()", symbols.transaction)
+
+		out.WithIf("%s := %s(%s.%s(%s), %s); %s != nil", func(url.Values) error {
+			return out.Returns(symbols.execError)
+		}, symbols.execError, symbols.callback, receiver, binder.Name, symbols.transaction, symbols.transaction, symbols.execError)
+
+		out.Returns(fmt.Sprintf("%s.Commit()", symbols.transaction))
+
+		out.Println("case *sql.Tx:")
+
+		if e := writeSavepointTransaction(out, receiver, symbols.executor, symbols.callback); e != nil {
+			return e
+		}
+
+		out.Println("}")
+
+		return out.Returns(fmt.Sprintf(
+			"fmt.Errorf(\"%s\", %s)",
+			constants.UnsupportedTransactionExecutor,
+			executorReference,
+		))
+	})
+
+	if e != nil {
+		return e
+	}
+
+	storeMethods[transaction.Name] = transaction
+	storeMethods[contextual.Name] = contextual
+
 	e = out.WithInterface(record.external(), func(url.Values) error {
 		for _, method := range storeMethods {
 			params := make([]string, 0, len(method.Params))
@@ -63,16 +183,111 @@ func writeStore(destination io.Writer, record marlowRecord, storeMethods map[str
 				returns = fmt.Sprintf("(%s)", returns)
 			}
 
-			definition := fmt.Sprintf("%s(%s) %s", method.Name, strings.Join(params, ","), returns)
-			out.Println(definition)
+			out.Println("%s(%s) %s", method.Name, strings.Join(params, ","), returns)
 		}
 		return nil
 	})
 
-	record.registerImports("database/sql", "io", "os")
+	record.registerImports("context", "database/sql", "fmt", "io", "os")
 	return e
 }
 
+// writeSavepointTransaction writes the portion of the transaction helper used when the store is already bound to a
+// *sql.Tx, wrapping the callback in a savepoint named after the depth of the nesting. The depth is kept per transaction
+// by the package (see writeSavepointRegistry) so that every store bound to the transaction uses savepoints of its own.
+func writeSavepointTransaction(out writing.GoWriter, receiver, executor, callback string) error {
+	symbols := struct {
+		savepoint string
+		execError string
+		rollError string
+		released  string
+	}{"_savepoint", "_se", "_re", "_released"}
+
+	statement := func(command string) string {
+		return fmt.Sprintf("%s.ExecContext(%s, \"%s \"+%s)", executor, contextSymbol, command, symbols.savepoint)
+	}
+
+	out.Println("%s := fmt.Sprintf(\"%s_%%d\", %s(%s))", symbols.savepoint, transactionSavepoint, savepointEnter, executor)
+	out.Println("defer %s(%s)", savepointLeave, executor)
+
+	out.WithIf("_, %s := %s; %s != nil", func(url.Values) error {
+		return out.Returns(symbols.execError)
+	}, symbols.execError, statement("SAVEPOINT"), symbols.execError)
+
+	out.Println("%s := false", symbols.released)
+
+	// Callbacks that panic leave the savepoint rolled back and released before the panic continues up the stack.
+	out.Println("defer func() {")
+	out.WithIf("%s", func(url.Values) error {
+		return out.Returns()
+	}, symbols.released)
+	out.Println("%s", statement("ROLLBACK TO SAVEPOINT"))
+	out.Println("%s", statement("RELEASE SAVEPOINT"))
+	out.Println("}()")
+
+	out.Println("%s := %s(%s, %s)", symbols.execError, callback, receiver, executor)
+	out.Println("%s = true", symbols.released)
+
+	e := out.WithIf("%s != nil", func(url.Values) error {
+		out.WithIf("_, %s := %s; %s != nil", func(url.Values) error {
+			return out.Returns(fmt.Sprintf(
+				"fmt.Errorf(\"%s\", %s, %s)",
+				constants.SavepointRollbackError,
+				symbols.execError,
+				symbols.rollError,
+			))
+		}, symbols.rollError, statement("ROLLBACK TO SAVEPOINT"), symbols.rollError)
+
+		out.WithIf("_, %s := %s; %s != nil", func(url.Values) error {
+			return out.Returns(fmt.Sprintf(
+				"fmt.Errorf(\"%s\", %s, %s)",
+				constants.SavepointRollbackError,
+				symbols.execError,
+				symbols.rollError,
+			))
+		}, symbols.rollError, statement("RELEASE SAVEPOINT"), symbols.rollError)
+
+		return out.Returns(symbols.execError)
+	}, symbols.execError)
+
+	if e != nil {
+		return e
+	}
+
+	out.Println("_, %s = %s", symbols.execError, statement("RELEASE SAVEPOINT"))
+	return out.Returns(symbols.execError)
+}
+
+// writeClockBinder writes the store method producing a copy of the store that stamps the timestamp fields of records
+// with the time read from the clock provided, allowing the stamps to be controlled by tests.
+func writeClockBinder(out writing.GoWriter, record marlowRecord, storeMethods map[string]writing.FuncDecl) error {
+	if record.stamped() != true {
+		return nil
+	}
+
+	binder := writing.FuncDecl{
+		Name:    "WithClock",
+		Params:  []writing.FuncParam{{Type: "func() time.Time", Symbol: "_clock"}},
+		Returns: []string{record.external()},
+	}
+
+	out.Comment("[marlow] clock binding for %s", record.store())
+
+	e := out.WithMethod(binder.Name, record.store(), binder.Params, binder.Returns, func(scope url.Values) error {
+		out.Println("_copy := *%s", scope.Get("receiver"))
+		out.Println("_copy.%s = _clock", constants.StoreClockField)
+		return out.Returns("&_copy")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	storeMethods[binder.Name] = binder
+	record.registerImports("time")
+	return nil
+}
+
 // newStoreGenerator returns a reader that will generate the centralized record store for a given record.
 func newStoreGenerator(record marlowRecord, methods map[string]writing.FuncDecl) io.Reader {
 	pr, pw := io.Pipe()
@@ -84,3 +299,62 @@ func newStoreGenerator(record marlowRecord, methods map[string]writing.FuncDecl)
 
 	return pr
 }
+
+const (
+	// contextMethodSuffix is appended to the name of store methods to produce their context-aware counterpart.
+	contextMethodSuffix = "Context"
+
+	// contextSymbol is the parameter name used for the context.Context received by context-aware store methods.
+	contextSymbol = "_ctx"
+
+	// transactionSavepoint is the prefix of the savepoint names used when transactions are nested within one another.
+	transactionSavepoint = "marlow_savepoint"
+)
+
+// writeContextMethods writes the context-aware variant of a store method using the provided block, followed by the
+// original method which delegates to it using context.Background(). Both methods are registered on the store.
+func writeContextMethods(gosrc writing.GoWriter, record marlowRecord, method writing.FuncDecl, block writing.Block) error {
+	contextual, e := writeContextVariants(gosrc, record, method, block)
+
+	if e != nil {
+		return e
+	}
+
+	record.registerImports("context")
+	record.registerStoreMethod(contextual)
+	record.registerStoreMethod(method)
+	return nil
+}
+
+// writeContextVariants writes both variants of a store method, returning the declaration of the context-aware one.
+func writeContextVariants(gosrc writing.GoWriter, record marlowRecord, method writing.FuncDecl, block writing.Block) (
+	writing.FuncDecl, error,
+) {
+	contextual := writing.FuncDecl{
+		Name:    fmt.Sprintf("%s%s", method.Name, contextMethodSuffix),
+		Params:  append([]writing.FuncParam{{Type: "context.Context", Symbol: contextSymbol}}, method.Params...),
+		Returns: method.Returns,
+	}
+
+	if e := gosrc.WithMethod(contextual.Name, record.store(), contextual.Params, contextual.Returns, block); e != nil {
+		return contextual, e
+	}
+
+	arguments := []string{"context.Background()"}
+
+	for _, p := range method.Params {
+		// Variadic parameters need to be expanded when handed off to the context-aware method.
+		if strings.HasPrefix(p.Type, "...") {
+			arguments = append(arguments, fmt.Sprintf("%s...", p.Symbol))
+			continue
+		}
+
+		arguments = append(arguments, p.Symbol)
+	}
+
+	e := gosrc.WithMethod(method.Name, record.store(), method.Params, method.Returns, func(scope url.Values) error {
+		return gosrc.Returns(fmt.Sprintf("%s.%s(%s)", scope.Get("receiver"), contextual.Name, strings.Join(arguments, ",")))
+	})
+
+	return contextual, e
+}
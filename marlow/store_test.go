@@ -4,6 +4,7 @@ import "fmt"
 import "io"
 import "sync"
 import "bytes"
+import "strings"
 import "net/url"
 import "testing"
 import "go/ast"
@@ -11,12 +12,14 @@ import "go/token"
 import "go/parser"
 import "github.com/franela/goblin"
 import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
 
 type storeTestScaffold struct {
 	output   *bytes.Buffer
 	imports  chan string
 	methods  map[string]writing.FuncDecl
 	record   url.Values
+	fields   map[string]url.Values
 	received map[string]bool
 	closed   bool
 	wg       *sync.WaitGroup
@@ -26,6 +29,7 @@ func (s *storeTestScaffold) g() io.Reader {
 	record := marlowRecord{
 		importChannel: s.imports,
 		config:        s.record,
+		fields:        s.fields,
 	}
 
 	return newStoreGenerator(record, s.methods)
@@ -53,6 +57,7 @@ func Test_StoreGenerator(t *testing.T) {
 				output:   new(bytes.Buffer),
 				imports:  make(chan string),
 				record:   make(url.Values),
+				fields:   make(map[string]url.Values),
 				methods:  make(map[string]writing.FuncDecl),
 				received: make(map[string]bool),
 				closed:   false,
@@ -96,10 +101,35 @@ func Test_StoreGenerator(t *testing.T) {
 			g.It("injects fmt and sql packages into import stream", func() {
 				io.Copy(scaffold.output, scaffold.g())
 				scaffold.close()
+				g.Assert(scaffold.received["context"]).Equal(true)
 				g.Assert(scaffold.received["database/sql"]).Equal(true)
+				g.Assert(scaffold.received["fmt"]).Equal(true)
 				g.Assert(scaffold.received["io"]).Equal(true)
 				g.Assert(scaffold.received["os"]).Equal(true)
-				g.Assert(len(scaffold.received)).Equal(3)
+				g.Assert(len(scaffold.received)).Equal(5)
+			})
+
+			g.It("backs the store with an executor interface rather than *sql.DB", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				g.Assert(strings.Contains(scaffold.output.String(), "type BookStoreExecutor interface")).Equal(true)
+				g.Assert(strings.Contains(scaffold.output.String(), "*sql.DB\n")).Equal(false)
+			})
+
+			g.It("adds the executor binding and transaction methods to the store interface", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				scaffold.close()
+				g.Assert(scaffold.methods["WithExecutor"].Name).Equal("WithExecutor")
+				g.Assert(scaffold.methods["Transaction"].Name).Equal("Transaction")
+				g.Assert(scaffold.methods["TransactionContext"].Name).Equal("TransactionContext")
+			})
+
+			g.It("names the savepoints of nested transactions after the depth of their transaction", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				output := scaffold.output.String()
+				g.Assert(strings.Contains(output, "savepointDepth")).Equal(false)
+				g.Assert(strings.Contains(output, "fmt.Sprintf(\"marlow_savepoint_%d\", marlowEnterSavepoint(_executor))")).Equal(true)
+				g.Assert(strings.Contains(output, "defer marlowLeaveSavepoint(_executor)")).Equal(true)
+				g.Assert(strings.Contains(output, "unable to roll back savepoint")).Equal(true)
 			})
 
 			g.It("writes valid golang code if store name is present", func() {
@@ -107,6 +137,48 @@ func Test_StoreGenerator(t *testing.T) {
 				_, e := scaffold.parsed()
 				g.Assert(e).Equal(nil)
 			})
+
+			g.It("does not add a clock to stores of records without timestamp fields", func() {
+				io.Copy(scaffold.output, scaffold.g())
+				scaffold.close()
+				g.Assert(strings.Contains(scaffold.output.String(), "clock func() time.Time")).Equal(false)
+				g.Assert(scaffold.methods["WithClock"].Name).Equal("")
+			})
+
+			g.Describe("with a timestamp field", func() {
+				g.BeforeEach(func() {
+					scaffold.fields["CreatedAt"] = url.Values{
+						"type":                             []string{"time.Time"},
+						constants.ColumnAutoCreateTimeFlag: []string{"true"},
+					}
+				})
+
+				g.It("writes valid golang code", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					_, e := scaffold.parsed()
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("backs the store with a clock defaulting to the current time", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					scaffold.close()
+					output := scaffold.output.String()
+					g.Assert(strings.Contains(output, "clock func() time.Time")).Equal(true)
+					g.Assert(strings.Contains(output, "clock: time.Now}")).Equal(true)
+					g.Assert(scaffold.received["time"]).Equal(true)
+				})
+
+				g.It("adds the clock binding method to the store interface", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					scaffold.close()
+					g.Assert(scaffold.methods["WithClock"].Name).Equal("WithClock")
+				})
+
+				g.It("keeps the clock of stores bound to another executor", func() {
+					io.Copy(scaffold.output, scaffold.g())
+					g.Assert(strings.Contains(scaffold.output.String(), "_copy.BookStoreExecutor = _executor")).Equal(true)
+				})
+			})
 		})
 	})
 }
@@ -0,0 +1,50 @@
+package marlow
+
+import "fmt"
+import "net/url"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+// timestampType is the type required of the fields stamped by the store's clock.
+const timestampType = "time.Time"
+
+// timestampFields returns the fields of the record flagged as stamped by the store's clock, using any of the flags.
+func (r *marlowRecord) timestampFields(flags ...string) fieldList {
+	return r.fieldList(func(config url.Values) bool {
+		for _, flag := range flags {
+			if config.Get(flag) != "" {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// stamped returns true if any of the record's fields are stamped by the store's clock, or if its soft deleted rows are
+// marked with the time read from it, requiring the store to hold one.
+func (r *marlowRecord) stamped() bool {
+	if r.softDeleteColumn() != "" {
+		return true
+	}
+
+	return len(r.timestampFields(constants.ColumnAutoCreateTimeFlag, constants.ColumnAutoUpdateTimeFlag)) > 0
+}
+
+// validateTimestamps returns an error if a field stamped by the store's clock is unable to hold the time it reads.
+func validateTimestamps(typeName string, fields map[string]url.Values) error {
+	for name, config := range fields {
+		create, update := config.Get(constants.ColumnAutoCreateTimeFlag), config.Get(constants.ColumnAutoUpdateTimeFlag)
+
+		if (create != "" || update != "") && config.Get("type") != timestampType {
+			return fmt.Errorf("timestamp field %s.%s must be a %s, found %s", typeName, name, timestampType, config.Get("type"))
+		}
+	}
+
+	return nil
+}
+
+// writeClockRead writes the assignment of the store clock's current time to the symbol provided.
+func writeClockRead(gosrc writing.GoWriter, receiver, symbol string) error {
+	return gosrc.Println("%s := %s.%s()", symbol, receiver, constants.StoreClockField)
+}
@@ -25,3 +25,27 @@ func getTypeInfo(fieldType string) types.BasicInfo {
 
 	return typeInfo
 }
+
+// nullableType describes one of the sql.Null* types, holding the member that stores its value and the type of it.
+type nullableType struct {
+	member     string
+	underlying string
+}
+
+// nullableTypes holds the nullable types of the database/sql package supported as field types.
+var nullableTypes = map[string]nullableType{
+	"sql.NullString":  {"String", "string"},
+	"sql.NullInt64":   {"Int64", "int64"},
+	"sql.NullInt32":   {"Int32", "int32"},
+	"sql.NullInt16":   {"Int16", "int16"},
+	"sql.NullByte":    {"Byte", "uint8"},
+	"sql.NullFloat64": {"Float64", "float64"},
+	"sql.NullBool":    {"Bool", "bool"},
+	"sql.NullTime":    {"Time", "time.Time"},
+}
+
+// lookupNullableType returns the nullable type description of the field type provided, if it is one.
+func lookupNullableType(fieldType string) (nullableType, bool) {
+	nullable, ok := nullableTypes[fieldType]
+	return nullable, ok
+}
@@ -2,6 +2,7 @@ package marlow
 
 import "io"
 import "fmt"
+import "strings"
 import "net/url"
 import "go/types"
 import "github.com/dadleyy/marlow/marlow/writing"
@@ -20,6 +21,11 @@ type updaterSymbols struct {
 	valueSlice      string
 	valueCount      string
 	targetValue     string
+	stamp           string
+	now             string
+	version         string
+	guard           string
+	condition       string
 }
 
 func updater(record marlowRecord, fieldConfig url.Values, methodName, op string) io.Reader {
@@ -39,17 +45,26 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 		valueSlice:      "_values",
 		valueCount:      "_valueCount",
 		targetValue:     "_target",
+		stamp:           "_stamp",
+		now:             "_now",
+		version:         "_version",
+		guard:           "_guard",
+		condition:       "_where",
 	}
 
-	params := []writing.FuncParam{
-		{Type: fieldConfig.Get("type"), Symbol: symbols.valueParam},
-		{Type: fmt.Sprintf("*%s", record.config.Get(constants.BlueprintNameConfigOption)), Symbol: symbols.blueprint},
-	}
+	// Updates of the record stamp every update timestamp field other than the one being updated.
+	stamps := record.fieldList(func(config url.Values) bool {
+		return config.Get(constants.ColumnAutoUpdateTimeFlag) != "" && config.Get(constants.ColumnConfigOption) != column
+	})
 
-	if fieldConfig.Get("type") == "sql.NullInt64" {
-		params[0].Type = fmt.Sprintf("*%s", fieldConfig.Get("type"))
+	targetValues := symbols.valueParam
+
+	for range stamps {
+		targetValues = fmt.Sprintf("%s, %s", targetValues, symbols.now)
 	}
 
+	params, versioned := updaterParams(record, fieldConfig, symbols)
+
 	returns := []string{
 		"int64",
 		"error",
@@ -59,9 +74,22 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 		gosrc := writing.NewGoWriter(pw)
 		gosrc.Comment("[marlow] updater method for %s", column)
 
-		e := gosrc.WithMethod(methodName, record.store(), params, returns, func(scope url.Values) error {
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
 			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
 
+			writeCursorCheck(gosrc, record, symbols.blueprint, "-1")
+
+			// Values given to the updater are checked against the field's validation rules, unless they are operands.
+			if op == "" {
+				writeUpdateValidation(gosrc, record, fieldConfig, symbols.valueParam)
+			}
+
+			if e := writeMatchedHooks(gosrc, record, beforeUpdateHook, scope.Get("receiver"), symbols.blueprint); e != nil {
+				return e
+			}
+
 			// Prepare a value count to keep track of the amount of dynamic components will be sent into the query.
 			gosrc.Println("%s := 1", symbols.valueCount)
 
@@ -70,36 +98,54 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 				return gosrc.Println("%s = len(%s.Values()) + 1", symbols.valueCount, symbols.blueprint)
 			}, symbols.blueprint, symbols.blueprint)
 
-			switch record.dialect() {
-			case "postgres":
-				gosrc.Println("%s := fmt.Sprintf(\"$%%d\", %s)", symbols.targetValue, symbols.valueCount)
-				break
-			default:
-				gosrc.Println("%s := \"?\"", symbols.targetValue)
-			}
+			gosrc.Println("%s := %s", symbols.targetValue, record.placeholders("%s", symbols.valueCount))
 
-			command := fmt.Sprintf("UPDATE %s SET %s = %%s", record.table(), column)
+			command := fmt.Sprintf("UPDATE %s SET %s = %%s", record.quote(record.table()), record.quote(column))
 
 			if op != "" {
-				command = fmt.Sprintf("UPDATE %s SET %s = %s", record.table(), column, op)
+				command = fmt.Sprintf("UPDATE %s SET %s = %s", record.quote(record.table()), record.quote(column), op)
+			}
+
+			arguments := []string{symbols.targetValue}
+
+			// The update timestamps of the record are stamped in the same statement, their placeholders follow the target's.
+			for i, stamp := range stamps {
+				placeholder := fmt.Sprintf("%s%d", symbols.stamp, i)
+				position := fmt.Sprintf("%s+%d", symbols.valueCount, i+1)
+				gosrc.Println("%s := %s", placeholder, record.placeholders("%s", position))
+				command = fmt.Sprintf("%s, %s = %%s", command, record.quote(record.fields[stamp.name].Get(constants.ColumnConfigOption)))
+				arguments = append(arguments, placeholder)
+			}
+
+			if len(stamps) > 0 {
+				writeClockRead(gosrc, scope.Get("receiver"), symbols.now)
+			}
+
+			if versioned {
+				command = fmt.Sprintf("%s%s", command, versionIncrement(record))
 			}
 
 			// Start the update template string with the basic SQL-dialect `UPDATE <table> SET <column> = ?` syntax.
-			template := fmt.Sprintf("fmt.Sprintf(\"%s\", %s)", command, symbols.targetValue)
+			template := fmt.Sprintf("fmt.Sprintf(\"%s\", %s)", command, strings.Join(arguments, ", "))
 
 			gosrc.Println("%s := bytes.NewBufferString(%s)", symbols.queryString, template)
 
 			// Add our blueprint to the WHERE section of our update statement buffer if it is not nil.
-			gosrc.WithIf("%s != nil", func(url.Values) error {
-				return gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", symbols.queryString, symbols.blueprint)
-			}, symbols.blueprint)
+			if versioned {
+				writeVersionGuard(gosrc, record, symbols, fmt.Sprintf("%s+%d", symbols.valueCount, len(stamps)+1))
+			} else {
+				gosrc.WithIf("%s != nil", func(url.Values) error {
+					return gosrc.Println("fmt.Fprintf(%s, \" %%s\", %s)", symbols.queryString, symbols.blueprint)
+				}, symbols.blueprint)
+			}
 
 			// Write the query execution statement.
 			gosrc.Println(
-				"%s, %s := %s.Prepare(%s.String() + \";\")",
+				"%s, %s := %s.PrepareContext(%s, %s.String() + \";\")",
 				symbols.statementResult,
 				symbols.statementError,
 				scope.Get("receiver"),
+				contextSymbol,
 				symbols.queryString,
 			)
 
@@ -112,10 +158,10 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 			// Create an array of `interface` values that will be used during the `Exec` portion of our transaction.
 			gosrc.Println("%s := make([]interface{}, 0, %s)", symbols.valueSlice, symbols.valueCount)
 
-			// The postgres dialect uses numbered placeholder values. If the record is using anything other than that, the
-			// placeholder for the target value should appear first in the set of values sent to Exec.
-			if record.dialect() != "postgres" {
-				gosrc.Println("%s = append(%s, %s)", symbols.valueSlice, symbols.valueSlice, symbols.valueParam)
+			// Dialects with numbered placeholders receive the target value at the end of the values sent to Exec. For all
+			// others, the placeholder for the target value appears first in the query and so must its value.
+			if record.numberedPlaceholders() != true {
+				gosrc.Println("%s = append(%s, %s)", symbols.valueSlice, symbols.valueSlice, targetValues)
 			}
 
 			gosrc.WithIf("%s != nil", func(url.Values) error {
@@ -127,17 +173,23 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 				)
 			}, symbols.blueprint)
 
-			// If we're postgres, add our value to the very end of our value slice.
-			if record.dialect() == "postgres" {
-				gosrc.Println("%s = append(%s, %s)", symbols.valueSlice, symbols.valueSlice, symbols.valueParam)
+			// If the dialect uses numbered placeholders, add our value to the very end of our value slice.
+			if record.numberedPlaceholders() {
+				gosrc.Println("%s = append(%s, %s)", symbols.valueSlice, symbols.valueSlice, targetValues)
+			}
+
+			// The guarded version is the last placeholder of the statement for every dialect.
+			if versioned {
+				gosrc.Println("%s = append(%s, %s)", symbols.valueSlice, symbols.valueSlice, symbols.version)
 			}
 
 			logwriter.AddLog(symbols.queryString, symbols.valueSlice)
 
-			gosrc.Println("%s, %s := %s.Exec(%s...)",
+			gosrc.Println("%s, %s := %s.ExecContext(%s, %s...)",
 				symbols.queryResult,
 				symbols.queryError,
 				symbols.statementResult,
+				contextSymbol,
 				symbols.valueSlice,
 			)
 
@@ -151,6 +203,10 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 				return gosrc.Returns("-1", symbols.rowError)
 			}, symbols.rowError)
 
+			if versioned {
+				writeVersionConflict(gosrc, record, symbols.rowCount, symbols.version)
+			}
+
 			return gosrc.Returns(symbols.rowCount, writing.Nil)
 		})
 
@@ -160,24 +216,214 @@ func updater(record marlowRecord, fieldConfig url.Values, methodName, op string)
 		}
 
 		record.registerImports("fmt", "bytes")
-		record.registerStoreMethod(writing.FuncDecl{
-			Name:    methodName,
-			Params:  params,
-			Returns: returns,
-		})
 		pw.CloseWithError(nil)
 	}()
 
 	return pr
 }
 
+// updaterParams returns the parameters of the updater method of a field. The updaters of versioned records receive the
+// version the update was made from.
+func updaterParams(record marlowRecord, fieldConfig url.Values, symbols updaterSymbols) ([]writing.FuncParam, bool) {
+	params := []writing.FuncParam{
+		{Type: fieldConfig.Get("type"), Symbol: symbols.valueParam},
+		{Type: fmt.Sprintf("*%s", record.config.Get(constants.BlueprintNameConfigOption)), Symbol: symbols.blueprint},
+	}
+
+	// Nullable values are received by reference, the nil reference updating the column to NULL.
+	if _, nullable := lookupNullableType(fieldConfig.Get("type")); nullable {
+		params[0].Type = fmt.Sprintf("*%s", fieldConfig.Get("type"))
+	}
+
+	_, versionConfig, versioned := record.versionField()
+
+	if versioned {
+		version := writing.FuncParam{Type: versionConfig.Get("type"), Symbol: symbols.version}
+		params = []writing.FuncParam{params[0], version, params[1]}
+	}
+
+	return params, versioned
+}
+
+// saver returns a generator for the store method that updates every column of a record, by primary key, in a single
+// statement. Records without a primary key do not receive the method.
+func saver(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	methodName := fmt.Sprintf("Save%s", record.name())
+	primaryField, _, ok := record.primaryKeyField()
+
+	if ok != true {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	symbols := struct {
+		record          string
+		query           string
+		values          string
+		statementResult string
+		statementError  string
+		queryResult     string
+		queryError      string
+		rowCount        string
+		rowError        string
+		now             string
+	}{"_record", "_query", "_values", "_statement", "_se", "_queryResult", "_queryError", "_rowCount", "_re", "_now"}
+
+	params := []writing.FuncParam{
+		{Type: fmt.Sprintf("*%s", record.name()), Symbol: symbols.record},
+	}
+
+	returns := []string{"int64", "error"}
+
+	// Every column other than the primary key and those managed by the database or the store is written by the update.
+	fields := record.fieldList(func(config url.Values) bool {
+		primary := config.Get(constants.ColumnConfigOption) == record.primaryKeyColumn()
+		managed := config.Get(constants.ColumnAutoIncrementFlag) != "" || config.Get(constants.ColumnVersionFlag) != ""
+		return primary == false && managed == false
+	})
+
+	versionField, _, versioned := record.versionField()
+
+	if len(fields) == 0 {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	assignments, positions, values := make([]string, 0, len(fields)), make([]string, 0, len(fields)+1), make([]string, 0)
+
+	for i, field := range fields {
+		column := record.quote(record.fields[field.name].Get(constants.ColumnConfigOption))
+		assignments = append(assignments, fmt.Sprintf("%s = %%s", column))
+		positions = append(positions, fmt.Sprintf("%d", i+1))
+		values = append(values, fmt.Sprintf("%s.%s", symbols.record, field.name))
+	}
+
+	positions = append(positions, fmt.Sprintf("%d", len(fields)+1))
+	values = append(values, fmt.Sprintf("%s.%s", symbols.record, primaryField))
+
+	template := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s = %%s;",
+		record.quote(record.table()),
+		strings.Join(assignments, ", "),
+		record.quote(record.primaryKeyColumn()),
+	)
+
+	// Saves of versioned records are only applied to the row still holding the version of the record being saved.
+	if versioned {
+		template = fmt.Sprintf(
+			"UPDATE %s SET %s%s WHERE %s = %%s AND %s = %%s;",
+			record.quote(record.table()),
+			strings.Join(assignments, ", "),
+			versionIncrement(record),
+			record.quote(record.primaryKeyColumn()),
+			record.quote(record.fields[versionField].Get(constants.ColumnConfigOption)),
+		)
+
+		positions = append(positions, fmt.Sprintf("%d", len(fields)+2))
+		values = append(values, fmt.Sprintf("%s.%s", symbols.record, versionField))
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+		gosrc.Comment("[marlow] whole-record save method by primary key %s", record.primaryKeyColumn())
+
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+			logwriter := logWriter{output: gosrc, receiver: scope.Get("receiver")}
+			gosrc.WithIf("%s == nil", func(url.Values) error {
+				return gosrc.Returns("-1", fmt.Sprintf("fmt.Errorf(\"unable to save nil %s\")", record.name()))
+			}, symbols.record)
+
+			writeHookCall(gosrc, record, beforeUpdateHook, symbols.record, func(hookError string) error {
+				return gosrc.Returns("-1", hookError)
+			})
+
+			if record.validated() {
+				gosrc.Println("%s := %s.Validate()", validationSymbol, symbols.record)
+				writeValidationFailure(gosrc, record, validationSymbol, "-1")
+			}
+
+			// The update timestamps are stamped on the record itself, leaving it in sync with its row.
+			if stamps := record.timestampFields(constants.ColumnAutoUpdateTimeFlag); len(stamps) > 0 {
+				writeClockRead(gosrc, scope.Get("receiver"), symbols.now)
+
+				for _, stamp := range stamps {
+					gosrc.Println("%s.%s = %s", symbols.record, stamp.name, symbols.now)
+				}
+			}
+
+			gosrc.Println("%s := %s", symbols.query, record.placeholders(template, positions...))
+			gosrc.Println("%s := []interface{}{%s}", symbols.values, strings.Join(values, ", "))
+
+			logwriter.AddLog(symbols.query, symbols.values)
+
+			gosrc.Println(
+				"%s, %s := %s.PrepareContext(%s, %s)",
+				symbols.statementResult,
+				symbols.statementError,
+				scope.Get("receiver"),
+				contextSymbol,
+				symbols.query,
+			)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				return gosrc.Returns("-1", symbols.statementError)
+			}, symbols.statementError)
+
+			gosrc.Println("defer %s.Close()", symbols.statementResult)
+
+			gosrc.Println("%s, %s := %s.ExecContext(%s, %s...)",
+				symbols.queryResult,
+				symbols.queryError,
+				symbols.statementResult,
+				contextSymbol,
+				symbols.values,
+			)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				return gosrc.Returns("-1", symbols.queryError)
+			}, symbols.queryError)
+
+			gosrc.Println("%s, %s := %s.RowsAffected()", symbols.rowCount, symbols.rowError, symbols.queryResult)
+
+			gosrc.WithIf("%s != nil", func(url.Values) error {
+				return gosrc.Returns("-1", symbols.rowError)
+			}, symbols.rowError)
+
+			// The saved record holds the incremented version of its row, ready to be saved again.
+			if versioned {
+				writeVersionConflict(gosrc, record, symbols.rowCount, fmt.Sprintf("%s.%s", symbols.record, versionField))
+				gosrc.Println("%s.%s++", symbols.record, versionField)
+			}
+
+			return gosrc.Returns(symbols.rowCount, writing.Nil)
+		})
+
+		if e == nil {
+			record.registerImports("fmt")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
 // newUpdateableGenerator is responsible for generating updating store methods.
 func newUpdateableGenerator(record marlowRecord) io.Reader {
 	readers := make([]io.Reader, 0, len(record.fields))
 	prefix := record.config.Get(constants.UpdateFieldMethodPrefixConfigOption)
 
 	for name, config := range record.fields {
+		// The version field is incremented by the updates of the record, it is never updated directly.
+		if config.Get(constants.ColumnVersionFlag) != "" {
+			continue
+		}
+
 		column := config.Get(constants.ColumnConfigOption)
+		quoted := record.quote(column)
 		method := fmt.Sprintf("%s%s%s", prefix, record.name(), name)
 		up := updater(record, config, method, "")
 		fieldType := getTypeInfo(config.Get("type"))
@@ -193,8 +439,8 @@ func newUpdateableGenerator(record marlowRecord) io.Reader {
 			}
 
 			bitwise := []io.Reader{
-				updater(record, config, fmt.Sprintf("Add%s%s", record.name(), name), fmt.Sprintf("%s | %%s", column)),
-				updater(record, config, fmt.Sprintf("Drop%s%s", record.name(), name), fmt.Sprintf("%s & ~%%s", column)),
+				updater(record, config, fmt.Sprintf("Add%s%s", record.name(), name), fmt.Sprintf("%s | %%s", quoted)),
+				updater(record, config, fmt.Sprintf("Drop%s%s", record.name(), name), fmt.Sprintf("%s & ~%%s", quoted)),
 			}
 
 			readers = append(readers, bitwise...)
@@ -203,5 +449,7 @@ func newUpdateableGenerator(record marlowRecord) io.Reader {
 		readers = append(readers, up)
 	}
 
+	readers = append(readers, saver(record), newVersionConflictGenerator(record))
+
 	return io.MultiReader(readers...)
 }
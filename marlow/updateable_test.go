@@ -3,6 +3,7 @@ package marlow
 import "io"
 import "sync"
 import "bytes"
+import "strings"
 import "testing"
 import "net/url"
 import "github.com/franela/goblin"
@@ -15,6 +16,7 @@ type updateableTestScaffold struct {
 	methods  chan writing.FuncDecl
 	record   url.Values
 	fields   map[string]url.Values
+	hooks    map[string]bool
 	received map[string]bool
 	closed   bool
 	wg       *sync.WaitGroup
@@ -37,6 +39,7 @@ func (s *updateableTestScaffold) g() io.Reader {
 		fields:        s.fields,
 		importChannel: s.imports,
 		storeChannel:  s.methods,
+		hooks:         s.hooks,
 	}
 	return newUpdateableGenerator(record)
 }
@@ -55,6 +58,7 @@ func Test_Updateable(t *testing.T) {
 				methods:  make(chan writing.FuncDecl),
 				record:   make(url.Values),
 				fields:   make(map[string]url.Values),
+				hooks:    make(map[string]bool),
 				received: make(map[string]bool),
 				closed:   false,
 				wg:       &sync.WaitGroup{},
@@ -111,6 +115,52 @@ func Test_Updateable(t *testing.T) {
 				g.Assert(e).Equal(nil)
 			})
 
+			g.It("receives the values of every nullable type by reference", func() {
+				scaffold.fields["Nickname"] = url.Values{"type": []string{"sql.NullString"}}
+				scaffold.fields["Retired"] = url.Values{"type": []string{"sql.NullTime"}}
+				io.Copy(scaffold.buffer, scaffold.g())
+				output := scaffold.buffer.String()
+				g.Assert(strings.Contains(output, "UpdateAuthorNickname(_updates *sql.NullString,")).Equal(true)
+				g.Assert(strings.Contains(output, "UpdateAuthorRetired(_updates *sql.NullTime,")).Equal(true)
+				g.Assert(strings.Contains(output, "UpdateAuthorUniversityID(_updates *sql.NullInt64,")).Equal(true)
+			})
+
+			g.Describe("with a primary key defined", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "id")
+					scaffold.fields["ID"].Set(constants.ColumnConfigOption, "id")
+					scaffold.fields["ID"].Set(constants.ColumnAutoIncrementFlag, "true")
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+					scaffold.fields["UniversityID"].Set(constants.ColumnConfigOption, "university_id")
+					scaffold.fields["Flag"].Set(constants.ColumnConfigOption, "flags")
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("updates every non-primary key column in a single statement", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "_query := \"UPDATE authors SET flags = ?, name = ?, university_id = ? WHERE id = ?;\""
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("sends the primary key value after the updated column values", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "_values := []interface{}{_record.Flag, _record.Name, _record.UniversityID, _record.ID}"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("uses numbered placeholders for postgres records", func() {
+					scaffold.record.Set(constants.DialectConfigOption, "postgres")
+					io.Copy(scaffold.buffer, scaffold.g())
+					template := "\"UPDATE authors SET flags = $%d, name = $%d, university_id = $%d WHERE id = $%d;\""
+					expected := "_query := fmt.Sprintf(" + template + ", 1, 2, 3, 4)"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+			})
+
 			g.Describe("with an invalid bitmask field type", func() {
 				g.BeforeEach(func() {
 					scaffold.fields["Flag"]["type"] = []string{"string"}
@@ -122,6 +172,38 @@ func Test_Updateable(t *testing.T) {
 				})
 			})
 
+			g.Describe("with a mysql record dialect", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.DialectConfigOption, "mysql")
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+					scaffold.fields["Flag"].Set(constants.ColumnConfigOption, "flags")
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("quotes the table and column identifiers with backticks", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "fmt.Sprintf(\"UPDATE `authors` SET `name` = %s\", _target)"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("quotes the column used in bitmask updates", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					added := "fmt.Sprintf(\"UPDATE `authors` SET `flags` = `flags` | %s\", _target)"
+					dropped := "fmt.Sprintf(\"UPDATE `authors` SET `flags` = `flags` & ~%s\", _target)"
+					g.Assert(strings.Contains(scaffold.buffer.String(), added)).Equal(true)
+					g.Assert(strings.Contains(scaffold.buffer.String(), dropped)).Equal(true)
+				})
+
+				g.It("uses an unnumbered placeholder for the updated value", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(strings.Contains(scaffold.buffer.String(), "_target := \"?\"")).Equal(true)
+				})
+			})
+
 			g.Describe("with a postgres record dialect", func() {
 				g.BeforeEach(func() {
 					scaffold.record.Set(constants.DialectConfigOption, "postgres")
@@ -133,6 +215,182 @@ func Test_Updateable(t *testing.T) {
 				})
 			})
 
+			g.Describe("with an update timestamp field", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "id")
+					scaffold.fields["ID"].Set(constants.ColumnConfigOption, "id")
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+					scaffold.fields["UpdatedAt"] = url.Values{
+						"type":                             []string{"time.Time"},
+						constants.ColumnConfigOption:       []string{"updated_at"},
+						constants.ColumnAutoUpdateTimeFlag: []string{"true"},
+					}
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("stamps the update timestamp in the same statement as the updated column", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					expected := "fmt.Sprintf(\"UPDATE authors SET name = %s, updated_at = %s\", _target, _stamp0)"
+					g.Assert(strings.Contains(output, expected)).Equal(true)
+					g.Assert(strings.Contains(output, "_now := a.clock()")).Equal(true)
+					g.Assert(strings.Contains(output, "_values = append(_values, _updates, _now)")).Equal(true)
+				})
+
+				g.It("does not stamp the update timestamp twice when it is the updated column", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "fmt.Sprintf(\"UPDATE authors SET updated_at = %s\", _target)"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("numbers the timestamp placeholder after the updated value for postgres records", func() {
+					scaffold.record.Set(constants.DialectConfigOption, "postgres")
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "_stamp0 := fmt.Sprintf(\"$%d\", _valueCount+1)"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("stamps the record being saved", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(strings.Contains(scaffold.buffer.String(), "_record.UpdatedAt = _now")).Equal(true)
+				})
+			})
+
+			g.Describe("with a version field", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "id")
+					scaffold.fields["ID"].Set(constants.ColumnConfigOption, "id")
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+					scaffold.fields["UniversityID"].Set(constants.ColumnConfigOption, "university_id")
+					scaffold.fields["Flag"].Set(constants.ColumnConfigOption, "flags")
+					scaffold.fields["Version"] = url.Values{
+						"type":                       []string{"int"},
+						constants.ColumnConfigOption: []string{"version"},
+						constants.ColumnVersionFlag:  []string{"true"},
+					}
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("receives the version the update was made from", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "UpdateAuthorNameContext(_ctx context.Context,_updates string,_version int,_blueprint *"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+
+				g.It("increments the version and guards the update with the expected version", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					command := "fmt.Sprintf(\"UPDATE authors SET name = %s, version = version + 1\", _target)"
+					g.Assert(strings.Contains(output, command)).Equal(true)
+					g.Assert(strings.Contains(output, "fmt.Fprintf(_queryString, \" WHERE (%s) AND version = %s\", _where, _guard)")).Equal(true)
+					g.Assert(strings.Contains(output, "_values = append(_values, _version)")).Equal(true)
+				})
+
+				g.It("returns the version conflict error when no row was updated", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					g.Assert(strings.Contains(output, "type AuthorVersionConflictError struct")).Equal(true)
+					g.Assert(strings.Contains(output, "return -1,&AuthorVersionConflictError{Version: _version}")).Equal(true)
+				})
+
+				g.It("numbers the version placeholder after the updated value for postgres records", func() {
+					scaffold.record.Set(constants.DialectConfigOption, "postgres")
+					io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(strings.Contains(scaffold.buffer.String(), "_guard := fmt.Sprintf(\"$%d\", _valueCount+1)")).Equal(true)
+				})
+
+				g.It("guards saves with the version of the record, incrementing it", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					expected := "\"UPDATE authors SET flags = ?, name = ?, university_id = ?, version = version + 1 " +
+						"WHERE id = ? AND version = ?;\""
+					g.Assert(strings.Contains(output, expected)).Equal(true)
+					values := "_values := []interface{}{_record.Flag, _record.Name, _record.UniversityID, _record.ID, _record.Version}"
+					g.Assert(strings.Contains(output, values)).Equal(true)
+					g.Assert(strings.Contains(output, "_record.Version++")).Equal(true)
+				})
+
+				g.It("does not generate an updater for the version field itself", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					g.Assert(strings.Contains(output, "UpdateAuthorVersion")).Equal(false)
+					g.Assert(strings.Contains(output, "UPDATE authors SET version = ")).Equal(false)
+				})
+
+				g.It("documents that updates matching no row are reported as version conflicts", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					expected := "Updates are unable to tell stale versions from missing records"
+					g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+				})
+			})
+
+			g.It("calls the update hook on the records matched by the field updaters before updating them", func() {
+				scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "id")
+				scaffold.fields["ID"].Set(constants.ColumnConfigOption, "id")
+				scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+				scaffold.fields["UniversityID"].Set(constants.ColumnConfigOption, "university_id")
+				scaffold.hooks[beforeUpdateHook] = true
+				_, e := io.Copy(scaffold.buffer, scaffold.g())
+				g.Assert(e).Equal(nil)
+				output := scaffold.buffer.String()
+				g.Assert(strings.Count(output, "_record.BeforeUpdate()")).Equal(1)
+				g.Assert(strings.Contains(output, "_hooked.Limit, _hooked.Offset = 0, 0")).Equal(true)
+				g.Assert(strings.Contains(output, ".IterateAuthorsContext(_ctx, &_hooked)")).Equal(true)
+				hook, update := strings.Index(output, "_cursor.Record().BeforeUpdate()"), strings.Index(output, "UPDATE authors SET name")
+				g.Assert(hook > 0).Equal(true)
+				g.Assert(hook < update).Equal(true)
+			})
+
+			g.Describe("with validation rules", func() {
+				g.BeforeEach(func() {
+					scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "id")
+					scaffold.fields["ID"].Set(constants.ColumnConfigOption, "id")
+					scaffold.fields["Name"].Set(constants.ColumnConfigOption, "name")
+					scaffold.fields["Name"].Set(constants.ColumnValidateOption, "required,max=10")
+					scaffold.fields["UniversityID"].Set(constants.ColumnConfigOption, "university_id")
+					scaffold.fields["UniversityID"].Set(constants.ColumnValidateOption, "min=1")
+					scaffold.fields["Flag"].Set(constants.ColumnConfigOption, "flags")
+				})
+
+				g.It("generates valid golang", func() {
+					_, e := io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(e).Equal(nil)
+				})
+
+				g.It("validates the updated value against the rules of the field", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					g.Assert(strings.Contains(output, "if utf8.RuneCountInString(_updates) > 10 {")).Equal(true)
+					g.Assert(strings.Contains(output, "return -1,&AuthorValidationError{Fields: _invalid}")).Equal(true)
+				})
+
+				g.It("validates nil nullable values as NULL", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					output := scaffold.buffer.String()
+					g.Assert(strings.Contains(output, "_validated = *_updates")).Equal(true)
+					g.Assert(strings.Contains(output, "if _validated.Valid && _validated.Int64 < 1 {")).Equal(true)
+				})
+
+				g.It("validates records before saving them", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					g.Assert(strings.Contains(scaffold.buffer.String(), "_invalid := _record.Validate()")).Equal(true)
+				})
+
+				g.It("registers the utf8 import for string length rules", func() {
+					io.Copy(scaffold.buffer, scaffold.g())
+					scaffold.close()
+					g.Assert(scaffold.received["unicode/utf8"]).Equal(true)
+				})
+			})
+
 		})
 
 	})
@@ -0,0 +1,510 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "strings"
+import "net/url"
+import "github.com/gedex/inflector"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type upsertableSymbolList struct {
+	createableSymbolList
+	overwrite   string
+	assignments string
+	column      string
+	identifier  string
+	identifiers string
+	executor    string
+	values      string
+	lookup      string
+	update      string
+	insert      string
+}
+
+// upsertConflictColumns returns the columns of the unique constraint used to detect conflicting rows during upserts: the
+// record's unique key, its only unique-flagged column or, without either, the primary key when it is written by inserts.
+// Records with several unique-flagged columns pick the one to conflict on with their unique key.
+func upsertConflictColumns(record marlowRecord) []string {
+	if key := record.uniqueKey(); len(key) > 0 {
+		return key
+	}
+
+	if unique := record.uniqueColumns(); len(unique) > 0 {
+		// Rows may conflict on any of several unique columns, a single conflict target is unable to cover all of them.
+		if len(unique) > 1 {
+			return nil
+		}
+
+		return unique
+	}
+
+	primaryKey := record.primaryKeyColumn()
+
+	for _, config := range record.fields {
+		if config.Get(constants.ColumnConfigOption) != primaryKey {
+			continue
+		}
+
+		if config.Get(constants.ColumnAutoIncrementFlag) != "" {
+			return nil
+		}
+	}
+
+	if primaryKey == "" {
+		return nil
+	}
+
+	return []string{primaryKey}
+}
+
+// upsertClause returns the clause of the record's dialect overwriting the rows conflicting on the (quoted) columns
+// provided, or an error if the dialect does not support upserts. An empty clause is returned for emulated upserts, which
+// rely on the last insert id of the dialect.
+func upsertClause(record marlowRecord, conflicts []string, primaryKey string) (string, error) {
+	dialect, ok := record.dialect().(UpsertDialect)
+
+	if !ok {
+		return "", fmt.Errorf("dialect does not implement UpsertDialect")
+	}
+
+	clause, e := dialect.Upsert(conflicts, record.quote(primaryKey))
+
+	if e == nil && clause == "" && record.dialect().InsertID() == ReturningInsertID {
+		return "", fmt.Errorf("emulated upserts require the last insert id of the dialect")
+	}
+
+	return clause, e
+}
+
+// newUpsertableGenerator returns a reader that will generate a record store's upsert api. Records without a primary key
+// or a conflict target, or whose dialect does not support upserts, receive a comment in place of the api.
+func newUpsertableGenerator(record marlowRecord) io.Reader {
+	methodName := fmt.Sprintf("Upsert%s", inflector.Pluralize(record.name()))
+	conflicts, primaryKey := upsertConflictColumns(record), record.primaryKeyColumn()
+
+	quotedConflicts := make([]string, 0, len(conflicts))
+
+	for _, column := range conflicts {
+		quotedConflicts = append(quotedConflicts, record.quote(column))
+	}
+
+	clause, unsupported := upsertClause(record, quotedConflicts, primaryKey)
+
+	if len(conflicts) == 0 || primaryKey == "" || unsupported != nil {
+		reason := fmt.Sprintf("/* [marlow] %s upserts require a primaryKey and conflict column */\n\n", record.name())
+
+		if unsupported != nil {
+			reason = fmt.Sprintf("/* [marlow] %s upserts unsupported: %v */\n\n", record.name(), unsupported)
+		}
+
+		return strings.NewReader(reason)
+	}
+
+	symbols := upsertableSymbolList{
+		createableSymbolList: createableSymbolList{
+			recordParam:              "_records",
+			queryBuffer:              "_query",
+			rowValueString:           "_placeholders",
+			statementPlaceholderList: "_placeholderList",
+			statementValueList:       "_valueList",
+			statement:                "_statement",
+			statementError:           "_e",
+			singleRecord:             "_record",
+			execResult:               "_rows",
+			execError:                "_queryError",
+			affectedError:            "_scanError",
+			now:                      "_now",
+		},
+		overwrite:   "_overwrite",
+		assignments: "_assignments",
+		column:      "_column",
+		identifier:  "_id",
+		identifiers: "_ids",
+		executor:    "_executor",
+		values:      "_values",
+		lookup:      "_lookup",
+		update:      "_update",
+		insert:      "_insert",
+	}
+
+	pr, pw := io.Pipe()
+
+	params := []writing.FuncParam{
+		{Symbol: symbols.overwrite, Type: "[]string"},
+		{Symbol: symbols.recordParam, Type: fmt.Sprintf("...%s", record.name())},
+	}
+
+	returns := []string{"[]int64", "error"}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+
+		gosrc.Comment("[marlow] upsertable")
+
+		method := writing.FuncDecl{Name: methodName, Params: params, Returns: returns}
+
+		e := writeContextMethods(gosrc, record, method, func(scope url.Values) error {
+			gosrc.WithIf("len(%s) == 0", func(url.Values) error {
+				return gosrc.Returns(writing.Nil, writing.Nil)
+			}, symbols.recordParam)
+
+			// Upserted records may be inserted, they are given to the same hook as the records of the creation api.
+			writeHookLoop(gosrc, record, beforeCreateHook, symbols.recordParam, writing.Nil)
+			writeRecordValidations(gosrc, record, symbols.recordParam, writing.Nil)
+
+			if clause == "" {
+				writeUpsertOverwrite(gosrc, record, conflicts, symbols, record.quote)
+				return writeEmulatedUpsert(gosrc, record, scope.Get("receiver"), conflicts, symbols)
+			}
+
+			columns := writeInsertRows(gosrc, record, scope.Get("receiver"), symbols.createableSymbolList)
+			writeUpsertOverwrite(gosrc, record, conflicts, symbols, record.dialect().(UpsertDialect).UpsertAssignment)
+			return writeUpsertStatement(gosrc, record, scope.Get("receiver"), columns, clause, symbols)
+		})
+
+		if e == nil && clause == "" {
+			record.registerImports("fmt", "strings", "database/sql")
+		}
+
+		if e == nil && clause != "" {
+			record.registerImports("fmt", "bytes", "strings")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// writeUpsertOverwrite writes the defaults and validation of the columns overwritten by an upsert, filling the list of
+// assignments with the result of the assignment function for each of the requested (quoted) columns.
+func writeUpsertOverwrite(
+	gosrc writing.GoWriter,
+	record marlowRecord,
+	conflicts []string,
+	symbols upsertableSymbolList,
+	assignment func(string) string,
+) error {
+	conflicting := make(map[string]bool, len(conflicts))
+
+	for _, column := range conflicts {
+		conflicting[column] = true
+	}
+
+	// By default, every inserted column that is not part of the conflict target is overwritten.
+	defaults := make([]string, 0, len(record.fields))
+	overwritable := make(map[string]string, len(record.fields))
+
+	for _, field := range record.fieldList(nil) {
+		config := record.fields[field.name]
+		column := config.Get(constants.ColumnConfigOption)
+
+		if config.Get(constants.ColumnAutoIncrementFlag) != "" {
+			continue
+		}
+
+		overwritable[column] = assignment(record.quote(column))
+
+		// Creation timestamps are kept when the conflicting row is overwritten unless explicitly requested.
+		if conflicting[column] || config.Get(constants.ColumnAutoCreateTimeFlag) != "" {
+			continue
+		}
+
+		defaults = append(defaults, fmt.Sprintf("%q", column))
+	}
+
+	if len(defaults) == 0 {
+		for _, column := range conflicts {
+			defaults = append(defaults, fmt.Sprintf("%q", column))
+		}
+	}
+
+	gosrc.WithIf("len(%s) == 0", func(url.Values) error {
+		return gosrc.Println("%s = []string{%s}", symbols.overwrite, strings.Join(defaults, ", "))
+	}, symbols.overwrite)
+
+	gosrc.Println("%s := make([]string, 0, len(%s))", symbols.assignments, symbols.overwrite)
+
+	return gosrc.WithIter("_, %s := range %s", func(url.Values) error {
+		cases := make([]string, 0, len(overwritable))
+
+		for column := range overwritable {
+			cases = append(cases, column)
+		}
+
+		sort.Strings(cases)
+
+		gosrc.Println("switch %s {", symbols.column)
+
+		for _, column := range cases {
+			gosrc.Println("case %q:", column)
+			gosrc.Println("%s = append(%s, %q)", symbols.assignments, symbols.assignments, overwritable[column])
+		}
+
+		gosrc.Println("default:")
+		gosrc.Returns(writing.Nil, fmt.Sprintf("fmt.Errorf(\"invalid upsert column %%q\", %s)", symbols.column))
+		return gosrc.Println("}")
+	}, symbols.column, symbols.overwrite)
+}
+
+// writeUpsertStatement writes the single statement inserting every record with the conflict clause of the dialect,
+// scanning the primary keys of the affected rows returned by it.
+func writeUpsertStatement(
+	gosrc writing.GoWriter,
+	record marlowRecord,
+	receiver string,
+	columns []string,
+	clause string,
+	symbols upsertableSymbolList,
+) error {
+	logwriter := logWriter{output: gosrc, receiver: receiver}
+
+	gosrc.Println("%s := new(bytes.Buffer)", symbols.queryBuffer)
+
+	table := record.quote(record.table())
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES %%s %s;", table, strings.Join(columns, ","), clause)
+
+	gosrc.Println(
+		"fmt.Fprintf(%s, %q, strings.Join(%s, \", \"), strings.Join(%s, \", \"))\n",
+		symbols.queryBuffer,
+		statement,
+		symbols.statementPlaceholderList,
+		symbols.assignments,
+	)
+
+	logwriter.AddLog(symbols.queryBuffer, symbols.statementValueList)
+
+	gosrc.Println(
+		"%s, %s := %s.PrepareContext(%s, %s.String())",
+		symbols.statement,
+		symbols.statementError,
+		receiver,
+		contextSymbol,
+		symbols.queryBuffer,
+	)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, symbols.statementError)
+	}, symbols.statementError)
+
+	gosrc.Println("defer %s.Close()\n", symbols.statement)
+
+	gosrc.Println(
+		"%s, %s := %s.QueryContext(%s, %s...)",
+		symbols.execResult,
+		symbols.execError,
+		symbols.statement,
+		contextSymbol,
+		symbols.statementValueList,
+	)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, symbols.execError)
+	}, symbols.execError)
+
+	gosrc.Println("defer %s.Close()\n", symbols.execResult)
+
+	gosrc.Println("%s := make([]int64, 0, len(%s))", symbols.identifiers, symbols.recordParam)
+
+	gosrc.WithIter("%s.Next()", func(url.Values) error {
+		gosrc.Println("var %s int64", symbols.identifier)
+
+		gosrc.WithIf("%s := %s.Scan(&%s); %s != nil", func(url.Values) error {
+			return gosrc.Returns(writing.Nil, symbols.affectedError)
+		}, symbols.affectedError, symbols.execResult, symbols.identifier, symbols.affectedError)
+
+		return gosrc.Println("%s = append(%s, %s)", symbols.identifiers, symbols.identifiers, symbols.identifier)
+	}, symbols.execResult)
+
+	gosrc.WithIf("%s := %s.Err(); %s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, symbols.execError)
+	}, symbols.execError, symbols.execResult, symbols.execError)
+
+	return gosrc.Returns(symbols.identifiers, writing.Nil)
+}
+
+// writeEmulatedUpsert writes the upsert of dialects without a conflict clause; within a transaction, every record is
+// looked up by its conflicting columns and the row found is overwritten, or the record inserted when there is none.
+func writeEmulatedUpsert(
+	gosrc writing.GoWriter,
+	record marlowRecord,
+	receiver string,
+	conflicts []string,
+	symbols upsertableSymbolList,
+) error {
+	logwriter := logWriter{output: gosrc, receiver: receiver}
+	table, primaryKey := record.quote(record.table()), record.quote(record.primaryKeyColumn())
+
+	stamps := make(map[string]bool)
+
+	for _, field := range record.timestampFields(constants.ColumnAutoCreateTimeFlag, constants.ColumnAutoUpdateTimeFlag) {
+		stamps[field.name] = true
+	}
+
+	if len(stamps) > 0 {
+		writeClockRead(gosrc, receiver, symbols.now)
+	}
+
+	columns, positions := make([]string, 0, len(record.fields)), make([]string, 0, len(record.fields))
+	values, inserts := make([]string, 0, len(record.fields)), make([]string, 0, len(record.fields))
+
+	for _, field := range record.fieldList(nil) {
+		config := record.fields[field.name]
+
+		if config.Get(constants.ColumnAutoIncrementFlag) != "" {
+			continue
+		}
+
+		value := fmt.Sprintf("%s.%s", symbols.singleRecord, field.name)
+
+		if stamps[field.name] {
+			value = symbols.now
+		}
+
+		values = append(values, fmt.Sprintf("%q: %s", config.Get(constants.ColumnConfigOption), value))
+		inserts = append(inserts, fmt.Sprintf("%s[%q]", symbols.values, config.Get(constants.ColumnConfigOption)))
+		columns = append(columns, record.quote(field.bare))
+		positions = append(positions, fmt.Sprintf("%d", len(positions)+1))
+	}
+
+	gosrc.Println("_sets := make([]string, 0, len(%s))", symbols.assignments)
+
+	gosrc.WithIter("_, _assignment := range %s", func(url.Values) error {
+		placeholder := record.placeholders("%s", "len(_sets)+1")
+		return gosrc.Println("_sets = append(_sets, fmt.Sprintf(\"%%s = %%s\", _assignment, %s))", placeholder)
+	}, symbols.assignments)
+
+	matches := make([]string, 0, len(conflicts))
+
+	for _, column := range conflicts {
+		matches = append(matches, fmt.Sprintf("%s = %%s", record.quote(column)))
+	}
+
+	lookup := fmt.Sprintf("SELECT %s FROM %s WHERE %s;", primaryKey, table, strings.Join(matches, " AND "))
+	gosrc.Println("%s := %s", symbols.lookup, record.placeholders(lookup, positions[:len(conflicts)]...))
+
+	gosrc.Println(
+		"%s := fmt.Sprintf(%q, strings.Join(_sets, \", \"), %s)",
+		symbols.update,
+		fmt.Sprintf("UPDATE %s SET %%s WHERE %s = %%s;", table, primaryKey),
+		record.placeholders("%s", "len(_sets)+1"),
+	)
+
+	insert := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s);",
+		table,
+		strings.Join(columns, ","),
+		strings.Repeat(",%s", len(columns))[1:],
+	)
+
+	gosrc.Println("%s := %s", symbols.insert, record.placeholders(insert, positions...))
+
+	gosrc.Println("%s := make([]int64, 0, len(%s))\n", symbols.identifiers, symbols.recordParam)
+
+	callback := fmt.Sprintf("func(_ %s, %s %s) error {", record.external(), symbols.executor, record.executor())
+	gosrc.Println("%s := %s.TransactionContext(%s, %s", symbols.statementError, receiver, contextSymbol, callback)
+
+	gosrc.Println(
+		"%s, %s := %s.PrepareContext(%s, %s)",
+		symbols.statement,
+		symbols.statementError,
+		symbols.executor,
+		contextSymbol,
+		symbols.lookup,
+	)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(symbols.statementError)
+	}, symbols.statementError)
+
+	gosrc.Println("defer %s.Close()\n", symbols.statement)
+
+	gosrc.WithIter("_, %s := range %s", func(url.Values) error {
+		gosrc.Println("%s := map[string]interface{}{%s}", symbols.values, strings.Join(values, ", "))
+
+		keys := make([]string, 0, len(conflicts))
+
+		for _, column := range conflicts {
+			keys = append(keys, fmt.Sprintf("%s[%q]", symbols.values, column))
+		}
+
+		gosrc.Println("_conflicts := []interface{}{%s}", strings.Join(keys, ", "))
+		gosrc.Println("var %s int64", symbols.identifier)
+		logwriter.AddLog(symbols.lookup, "_conflicts")
+
+		gosrc.Println(
+			"%s = %s.QueryRowContext(%s, _conflicts...).Scan(&%s)\n",
+			symbols.statementError,
+			symbols.statement,
+			contextSymbol,
+			symbols.identifier,
+		)
+
+		writeEmulatedUpsertWrites(gosrc, logwriter, inserts, symbols)
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Returns(symbols.statementError)
+		}, symbols.statementError)
+
+		return gosrc.Println("%s = append(%s, %s)", symbols.identifiers, symbols.identifiers, symbols.identifier)
+	}, symbols.singleRecord, symbols.recordParam)
+
+	gosrc.Returns(writing.Nil)
+	gosrc.Println("})\n")
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Returns(writing.Nil, symbols.statementError)
+	}, symbols.statementError)
+
+	return gosrc.Returns(symbols.identifiers, writing.Nil)
+}
+
+// writeEmulatedUpsertWrites writes the overwrite of the row found by the lookup of an emulated upsert, or the insert of
+// the record when the lookup did not find one.
+func writeEmulatedUpsertWrites(
+	gosrc writing.GoWriter,
+	logwriter logWriter,
+	inserts []string,
+	symbols upsertableSymbolList,
+) {
+	gosrc.Println("switch %s {", symbols.statementError)
+	gosrc.Println("case nil:")
+	gosrc.Println("_updates := make([]interface{}, 0, len(%s)+1)", symbols.overwrite)
+
+	gosrc.WithIter("_, %s := range %s", func(url.Values) error {
+		return gosrc.Println("_updates = append(_updates, %s[%s])", symbols.values, symbols.column)
+	}, symbols.column, symbols.overwrite)
+
+	gosrc.Println("_updates = append(_updates, %s)", symbols.identifier)
+	logwriter.AddLog(symbols.update, "_updates")
+
+	gosrc.Println(
+		"_, %s = %s.ExecContext(%s, %s, _updates...)",
+		symbols.statementError,
+		symbols.executor,
+		contextSymbol,
+		symbols.update,
+	)
+
+	gosrc.Println("case sql.ErrNoRows:")
+
+	gosrc.Println("_inserts := []interface{}{%s}", strings.Join(inserts, ", "))
+	logwriter.AddLog(symbols.insert, "_inserts")
+	gosrc.Println("var _result sql.Result")
+
+	gosrc.WithIf(
+		"_result, %s = %s.ExecContext(%s, %s, _inserts...); %s == nil",
+		func(url.Values) error {
+			return gosrc.Println("%s, %s = _result.LastInsertId()", symbols.identifier, symbols.statementError)
+		},
+		symbols.statementError,
+		symbols.executor,
+		contextSymbol,
+		symbols.insert,
+		symbols.statementError,
+	)
+
+	gosrc.Println("}\n")
+}
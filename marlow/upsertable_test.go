@@ -0,0 +1,223 @@
+package marlow
+
+import "io"
+import "sync"
+import "bytes"
+import "strings"
+import "testing"
+import "net/url"
+import "github.com/franela/goblin"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+type upsertableTestScaffold struct {
+	buffer *bytes.Buffer
+
+	imports chan string
+	methods chan writing.FuncDecl
+
+	record url.Values
+	fields map[string]url.Values
+
+	registered map[string]bool
+	closed     bool
+	wg         *sync.WaitGroup
+}
+
+func (s *upsertableTestScaffold) close() {
+	if s == nil || s.closed {
+		return
+	}
+
+	s.closed = true
+	close(s.imports)
+	close(s.methods)
+	s.wg.Wait()
+}
+
+func (s *upsertableTestScaffold) g() io.Reader {
+	record := marlowRecord{
+		fields:        s.fields,
+		config:        s.record,
+		importChannel: s.imports,
+		storeChannel:  s.methods,
+	}
+
+	return newUpsertableGenerator(record)
+}
+
+func Test_Upsertable(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	var scaffold *upsertableTestScaffold
+
+	g.Describe("upsertable feature generator test suite", func() {
+		g.BeforeEach(func() {
+			scaffold = &upsertableTestScaffold{
+				buffer:     new(bytes.Buffer),
+				wg:         &sync.WaitGroup{},
+				imports:    make(chan string),
+				methods:    make(chan writing.FuncDecl),
+				record:     make(url.Values),
+				fields:     make(map[string]url.Values),
+				registered: make(map[string]bool),
+			}
+
+			scaffold.wg.Add(2)
+
+			go func() {
+				for method := range scaffold.methods {
+					scaffold.registered[method.Name] = true
+				}
+				scaffold.wg.Done()
+			}()
+
+			go func() {
+				for range scaffold.imports {
+				}
+				scaffold.wg.Done()
+			}()
+
+			scaffold.record.Set(constants.RecordNameConfigOption, "Author")
+			scaffold.record.Set(constants.TableNameConfigOption, "authors")
+			scaffold.record.Set(constants.StoreNameConfigOption, "AuthorStore")
+			scaffold.record.Set(constants.PrimaryKeyColumnConfigOption, "id")
+
+			scaffold.fields["ID"] = url.Values{
+				"type":                            []string{"int"},
+				constants.ColumnConfigOption:      []string{"id"},
+				constants.ColumnAutoIncrementFlag: []string{"true"},
+			}
+
+			scaffold.fields["Name"] = url.Values{
+				"type":                       []string{"string"},
+				constants.ColumnConfigOption: []string{"name"},
+			}
+
+			scaffold.fields["Email"] = url.Values{
+				"type":                       []string{"string"},
+				constants.ColumnConfigOption: []string{"email"},
+				constants.ColumnUniqueFlag:   []string{""},
+			}
+		})
+
+		g.AfterEach(func() {
+			scaffold.close()
+		})
+
+		g.It("generates valid golang", func() {
+			_, e := io.Copy(scaffold.buffer, scaffold.g())
+			g.Assert(e).Equal(nil)
+		})
+
+		g.It("registers the upsert method and its context-aware variant with the store", func() {
+			io.Copy(scaffold.buffer, scaffold.g())
+			scaffold.close()
+			g.Assert(scaffold.registered["UpsertAuthors"]).Equal(true)
+			g.Assert(scaffold.registered["UpsertAuthorsContext"]).Equal(true)
+		})
+
+		g.It("looks up the rows conflicting on the unique columns for sqlite records", func() {
+			io.Copy(scaffold.buffer, scaffold.g())
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "_lookup := \"SELECT id FROM authors WHERE email = ?;\"")).Equal(true)
+			g.Assert(strings.Contains(output, "_conflicts := []interface{}{_values[\"email\"]}")).Equal(true)
+			g.Assert(strings.Contains(output, "ON CONFLICT")).Equal(false)
+		})
+
+		g.It("updates the conflicting rows by primary key or inserts the records within a transaction", func() {
+			io.Copy(scaffold.buffer, scaffold.g())
+			output := scaffold.buffer.String()
+			update := "_update := fmt.Sprintf(\"UPDATE authors SET %s WHERE id = %s;\", strings.Join(_sets, \", \"), \"?\")"
+			g.Assert(strings.Contains(output, update)).Equal(true)
+			g.Assert(strings.Contains(output, "_insert := \"INSERT INTO authors (email,name) VALUES (?,?);\"")).Equal(true)
+			g.Assert(strings.Contains(output, "_id, _e = _result.LastInsertId()")).Equal(true)
+			transaction := "TransactionContext(_ctx, func(_ AuthorStore, _executor AuthorStoreExecutor) error {"
+			g.Assert(strings.Contains(output, transaction)).Equal(true)
+		})
+
+		g.It("conflicts on the unique columns, returning the primary key of affected rows for postgres records", func() {
+			scaffold.record.Set(constants.DialectConfigOption, "postgres")
+			io.Copy(scaffold.buffer, scaffold.g())
+			expected := "INSERT INTO authors (email,name) VALUES %s ON CONFLICT (email) DO UPDATE SET %s RETURNING id;"
+			g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+		})
+
+		g.It("overwrites every column outside of the conflict target by default", func() {
+			io.Copy(scaffold.buffer, scaffold.g())
+			g.Assert(strings.Contains(scaffold.buffer.String(), "_overwrite = []string{\"name\"}")).Equal(true)
+		})
+
+		g.It("validates the requested columns against the inserted columns", func() {
+			io.Copy(scaffold.buffer, scaffold.g())
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "_assignments = append(_assignments, \"name\")")).Equal(true)
+			g.Assert(strings.Contains(output, "_assignments = append(_assignments, \"email\")")).Equal(true)
+			g.Assert(strings.Contains(output, "_assignments = append(_assignments, \"id\")")).Equal(false)
+			g.Assert(strings.Contains(output, "fmt.Errorf(\"invalid upsert column %q\", _column)")).Equal(true)
+		})
+
+		g.It("assigns the values being inserted to the requested columns for postgres records", func() {
+			scaffold.record.Set(constants.DialectConfigOption, "postgres")
+			io.Copy(scaffold.buffer, scaffold.g())
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "_assignments = append(_assignments, \"name = excluded.name\")")).Equal(true)
+			g.Assert(strings.Contains(output, "_assignments = append(_assignments, \"email = excluded.email\")")).Equal(true)
+			g.Assert(strings.Contains(output, "_assignments = append(_assignments, \"id = excluded.id\")")).Equal(false)
+			g.Assert(strings.Contains(output, "fmt.Errorf(\"invalid upsert column %q\", _column)")).Equal(true)
+		})
+
+		g.It("conflicts on the columns of the unique key when one is configured", func() {
+			scaffold.record.Set(constants.UniqueKeyConfigOption, "name,email")
+			io.Copy(scaffold.buffer, scaffold.g())
+			output := scaffold.buffer.String()
+			g.Assert(strings.Contains(output, "\"SELECT id FROM authors WHERE name = ? AND email = ?;\"")).Equal(true)
+		})
+
+		g.It("skips the api when several columns are flagged unique without a unique key", func() {
+			scaffold.fields["Name"].Set(constants.ColumnUniqueFlag, "true")
+			io.Copy(scaffold.buffer, scaffold.g())
+			scaffold.close()
+			g.Assert(strings.Contains(scaffold.buffer.String(), "upserts require a primaryKey")).Equal(true)
+			g.Assert(scaffold.registered["UpsertAuthors"]).Equal(false)
+		})
+
+		g.It("conflicts on the primary key when no unique columns are flagged and it is inserted", func() {
+			delete(scaffold.fields["Email"], constants.ColumnUniqueFlag)
+			scaffold.fields["ID"].Del(constants.ColumnAutoIncrementFlag)
+			io.Copy(scaffold.buffer, scaffold.g())
+			g.Assert(strings.Contains(scaffold.buffer.String(), "\"SELECT id FROM authors WHERE id = ?;\"")).Equal(true)
+		})
+
+		g.It("skips the api when there is no column to conflict on", func() {
+			delete(scaffold.fields["Email"], constants.ColumnUniqueFlag)
+			io.Copy(scaffold.buffer, scaffold.g())
+			scaffold.close()
+			g.Assert(strings.Contains(scaffold.buffer.String(), "upserts require a primaryKey")).Equal(true)
+			g.Assert(scaffold.registered["UpsertAuthors"]).Equal(false)
+		})
+
+		g.It("skips the api when there is no primary key to return", func() {
+			scaffold.record.Del(constants.PrimaryKeyColumnConfigOption)
+			io.Copy(scaffold.buffer, scaffold.g())
+			scaffold.close()
+			g.Assert(scaffold.registered["UpsertAuthors"]).Equal(false)
+		})
+
+		g.It("uses numbered placeholders for postgres records", func() {
+			scaffold.record.Set(constants.DialectConfigOption, "postgres")
+			io.Copy(scaffold.buffer, scaffold.g())
+			expected := "_placeholders := []string{fmt.Sprintf(\"$%d\", (_recordIndex*2)+1), fmt.Sprintf(\"$%d\", (_recordIndex*2)+2)}"
+			g.Assert(strings.Contains(scaffold.buffer.String(), expected)).Equal(true)
+		})
+
+		g.It("skips the api for mysql records", func() {
+			scaffold.record.Set(constants.DialectConfigOption, "mysql")
+			_, e := io.Copy(scaffold.buffer, scaffold.g())
+			scaffold.close()
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(scaffold.buffer.String(), "upserts unsupported")).Equal(true)
+			g.Assert(scaffold.registered["UpsertAuthors"]).Equal(false)
+		})
+	})
+}
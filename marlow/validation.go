@@ -0,0 +1,363 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "strings"
+import "strconv"
+import "net/url"
+import "go/types"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+const (
+	// validationRequired fails fields holding the zero value of their type (or NULL, for nullable types).
+	validationRequired = "required"
+
+	// validationMin fails fields whose length (for strings) or value (for numbers) is below the bound of the rule.
+	validationMin = "min"
+
+	// validationMax fails fields whose length (for strings) or value (for numbers) is above the bound of the rule.
+	validationMax = "max"
+
+	// validationSymbol is the symbol holding the field errors collected by generated validations.
+	validationSymbol = "_invalid"
+)
+
+// validationRule is a single rule of a field's validate option along with the bound it is checked against, if any.
+type validationRule struct {
+	name  string
+	bound string
+}
+
+func (r validationRule) String() string {
+	if r.bound == "" {
+		return r.name
+	}
+
+	return fmt.Sprintf("%s=%s", r.name, r.bound)
+}
+
+// validationSubject describes how the values of a field type are checked by validation rules. Each template receives
+// the expression of the value being validated.
+type validationSubject struct {
+	missing string
+	measure string
+	guard   string
+	integer bool
+}
+
+// validationSubjects holds the subjects of the types that are not checked by value.
+var validationSubjects = map[string]validationSubject{
+	"string":    {missing: "%s == \"\"", measure: "utf8.RuneCountInString(%s)", integer: true},
+	"bool":      {missing: "%s == false"},
+	"time.Time": {missing: "%s.IsZero()"},
+}
+
+// lookupValidationSubject returns the subject used to validate values of the field type provided.
+func lookupValidationSubject(fieldType string) (validationSubject, bool) {
+	if subject, ok := validationSubjects[fieldType]; ok {
+		return subject, true
+	}
+
+	// Nullable values are missing when invalid, their measure being the one of the value they hold when valid.
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		subject, ok := lookupValidationSubject(nullable.underlying)
+		member := fmt.Sprintf("%%s.%s", nullable.member)
+
+		if subject.measure != "" {
+			subject.measure, subject.guard = fmt.Sprintf(subject.measure, member), "%s.Valid && "
+		}
+
+		subject.missing = "%s.Valid == false"
+		return subject, ok
+	}
+
+	info := getTypeInfo(fieldType)
+
+	if info&types.IsInteger != 0 {
+		return validationSubject{missing: "%s == 0", measure: "%s", integer: true}, true
+	}
+
+	if info&types.IsFloat != 0 {
+		return validationSubject{missing: "%s == 0", measure: "%s"}, true
+	}
+
+	return validationSubject{}, false
+}
+
+// parseValidationRules returns the rules held by the validate option of a field, returning an error if any of them are
+// unknown or unable to be checked against the type of the field.
+func parseValidationRules(typeName, name string, config url.Values) ([]validationRule, error) {
+	option := config.Get(constants.ColumnValidateOption)
+
+	if option == "" {
+		return nil, nil
+	}
+
+	rules := make([]validationRule, 0, strings.Count(option, ",")+1)
+
+	subject, supported := lookupValidationSubject(config.Get("type"))
+
+	for _, part := range strings.Split(option, ",") {
+		rule := validationRule{}
+		rule.name, rule.bound, _ = strings.Cut(strings.TrimSpace(part), "=")
+
+		checked := subject.missing != ""
+
+		switch rule.name {
+		case validationRequired:
+		case validationMin, validationMax:
+			checked = subject.measure != ""
+		default:
+			return nil, fmt.Errorf("unknown validation rule \"%s\" for %s.%s", rule.name, typeName, name)
+		}
+
+		if supported != true || checked != true {
+			e := "validation rule %s is not supported by %s.%s of type %s"
+			return nil, fmt.Errorf(e, rule.name, typeName, name, config.Get("type"))
+		}
+
+		if e := validateRuleBound(rule, subject); e != nil {
+			return nil, fmt.Errorf("invalid %s bound for %s.%s: %s", rule.name, typeName, name, rule.bound)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// validateRuleBound returns an error if the bound of the rule is missing where required or is not a valid number.
+func validateRuleBound(rule validationRule, subject validationSubject) error {
+	if rule.name == validationRequired {
+		if rule.bound != "" {
+			return fmt.Errorf("unexpected-bound")
+		}
+
+		return nil
+	}
+
+	if subject.integer {
+		_, e := strconv.ParseInt(rule.bound, 10, 64)
+		return e
+	}
+
+	_, e := strconv.ParseFloat(rule.bound, 64)
+	return e
+}
+
+// validateValidations returns an error if the validate option of any field holds an invalid rule.
+func validateValidations(typeName string, fields map[string]url.Values) error {
+	names := make([]string, 0, len(fields))
+
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, e := parseValidationRules(typeName, name, fields[name]); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// validated returns true if any of the record's fields have validation rules.
+func (r *marlowRecord) validated() bool {
+	for _, config := range r.fields {
+		if config.Get(constants.ColumnValidateOption) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *marlowRecord) validationError() string {
+	return fmt.Sprintf("%s%s", r.name(), constants.ValidationErrorSuffix)
+}
+
+func (r *marlowRecord) fieldError() string {
+	return fmt.Sprintf("%s%s", r.name(), constants.FieldErrorSuffix)
+}
+
+// newValidationGenerator returns a reader that writes the Validate method of the record along with the types describing
+// the fields that fail their validation rules.
+func newValidationGenerator(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+
+	if record.validated() != true {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+		e := writeValidationTypes(gosrc, record)
+
+		if e == nil {
+			e = writeValidateMethod(gosrc, record)
+		}
+
+		if e == nil {
+			record.registerImports("fmt", "strings")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// writeValidationTypes writes the field error type and the error type returned by the store when records fail their
+// validation rules.
+func writeValidationTypes(gosrc writing.GoWriter, record marlowRecord) error {
+	gosrc.Comment("%s describes a field of a %s that failed one of its validation rules.", record.fieldError(), record.name())
+
+	e := gosrc.WithStruct(record.fieldError(), func(url.Values) error {
+		gosrc.Println("Field string")
+		return gosrc.Println("Rule string")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	comment := "%s is returned by the creates and updates of %s records with fields failing their validation rules."
+	gosrc.Comment(comment, record.validationError(), record.name())
+
+	e = gosrc.WithStruct(record.validationError(), func(url.Values) error {
+		return gosrc.Println("Fields []%s", record.fieldError())
+	})
+
+	if e != nil {
+		return e
+	}
+
+	return gosrc.WithMethod("Error", record.validationError(), nil, []string{"string"}, func(scope url.Values) error {
+		receiver := scope.Get("receiver")
+		gosrc.Println("_messages := make([]string, 0, len(%s.Fields))", receiver)
+
+		gosrc.WithIter("_, _field := range %s.Fields", func(url.Values) error {
+			return gosrc.Println("_messages = append(_messages, fmt.Sprintf(\"%%s failed %%s\", _field.Field, _field.Rule))")
+		}, receiver)
+
+		message := fmt.Sprintf("invalid %s: %%s", strings.ToLower(record.name()))
+		return gosrc.Returns(fmt.Sprintf("fmt.Sprintf(%q, strings.Join(_messages, \", \"))", message))
+	})
+}
+
+// writeValidateMethod writes the Validate method of the record, returning the field errors of every failed rule.
+func writeValidateMethod(gosrc writing.GoWriter, record marlowRecord) error {
+	comment := "Validate returns the fields of the %s that fail their validation rules, or nil if every rule is satisfied."
+	gosrc.Comment(comment, record.name())
+
+	returns := []string{fmt.Sprintf("[]%s", record.fieldError())}
+
+	return gosrc.WithMethod("Validate", record.name(), nil, returns, func(scope url.Values) error {
+		gosrc.Println("var %s []%s", validationSymbol, record.fieldError())
+
+		for _, field := range record.fieldList(nil) {
+			value := fmt.Sprintf("%s.%s", scope.Get("receiver"), field.name)
+			writeFieldValidation(gosrc, record, field.name, value)
+		}
+
+		return gosrc.Returns(validationSymbol)
+	})
+}
+
+// writeFieldValidation writes the checks of a field's validation rules against the value provided, appending the field
+// errors of the rules that fail to the validation symbol.
+func writeFieldValidation(gosrc writing.GoWriter, record marlowRecord, field, value string) error {
+	config := record.fields[field]
+	rules, e := parseValidationRules(record.name(), field, config)
+
+	if e != nil || len(rules) == 0 {
+		return e
+	}
+
+	subject, _ := lookupValidationSubject(config.Get("type"))
+
+	for _, rule := range rules {
+		condition := fmt.Sprintf(subject.missing, value)
+
+		if rule.name != validationRequired {
+			operator := map[string]string{validationMin: "<", validationMax: ">"}[rule.name]
+			condition = fmt.Sprintf("%s %s %s", fmt.Sprintf(subject.measure, value), operator, rule.bound)
+		}
+
+		if rule.name != validationRequired && subject.guard != "" {
+			condition = fmt.Sprintf("%s%s", fmt.Sprintf(subject.guard, value), condition)
+		}
+
+		if strings.Contains(subject.measure, "utf8") && rule.name != validationRequired {
+			record.registerImports("unicode/utf8")
+		}
+
+		gosrc.WithIf(condition, func(url.Values) error {
+			failure := fmt.Sprintf("%s{Field: %q, Rule: %q}", record.fieldError(), field, rule.String())
+			return gosrc.Println("%s = append(%s, %s)", validationSymbol, validationSymbol, failure)
+		})
+	}
+
+	return nil
+}
+
+// writeValidationFailure writes the return of the validation error when the field errors held by the symbol provided
+// are not empty, preceded by the zero values provided.
+func writeValidationFailure(gosrc writing.GoWriter, record marlowRecord, invalid string, zeros ...string) error {
+	return gosrc.WithIf("len(%s) > 0", func(url.Values) error {
+		failure := fmt.Sprintf("&%s{Fields: %s}", record.validationError(), invalid)
+		return gosrc.Returns(append(zeros, failure)...)
+	}, invalid)
+}
+
+// writeRecordValidations writes the validation of each of the records held by the slice provided, returning the zero
+// values along with the validation error of the first record that fails its rules.
+func writeRecordValidations(gosrc writing.GoWriter, record marlowRecord, slice string, zeros ...string) error {
+	if record.validated() != true {
+		return nil
+	}
+
+	return gosrc.WithIter("_i := range %s", func(url.Values) error {
+		gosrc.Println("%s := %s[_i].Validate()", validationSymbol, slice)
+		return writeValidationFailure(gosrc, record, validationSymbol, zeros...)
+	}, slice)
+}
+
+// writeUpdateValidation writes the validation of the value given to the updater of a field, returning the validation
+// error if it fails any of the field's rules. Nothing is written for fields without validation rules.
+func writeUpdateValidation(gosrc writing.GoWriter, record marlowRecord, fieldConfig url.Values, value string) error {
+	fields := record.fieldList(func(config url.Values) bool {
+		return config.Get(constants.ColumnConfigOption) == fieldConfig.Get(constants.ColumnConfigOption)
+	})
+
+	if len(fields) != 1 || fieldConfig.Get(constants.ColumnValidateOption) == "" {
+		return nil
+	}
+
+	field := fields[0].name
+
+	gosrc.Println("var %s []%s", validationSymbol, record.fieldError())
+
+	// Nullable values are received by reference, the nil reference being validated as NULL.
+	if _, nullable := lookupNullableType(fieldConfig.Get("type")); nullable {
+		gosrc.Println("var _validated %s", fieldConfig.Get("type"))
+
+		gosrc.WithIf("%s != nil", func(url.Values) error {
+			return gosrc.Println("_validated = *%s", value)
+		}, value)
+
+		value = "_validated"
+	}
+
+	if e := writeFieldValidation(gosrc, record, field, value); e != nil {
+		return e
+	}
+
+	return writeValidationFailure(gosrc, record, validationSymbol, "-1")
+}
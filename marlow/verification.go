@@ -0,0 +1,344 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "strings"
+import "net/url"
+import "go/types"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+const (
+	// verificationErrorSuffix is appended to the name of the record to name the error describing schema mismatches.
+	verificationErrorSuffix = "SchemaError"
+
+	// verificationAggregate is the name of the function verifying the schema of every record in the package.
+	verificationAggregate = "VerifySchemas"
+
+	// verificationAggregateError is the name of the error returned by the aggregate, holding the error of each record.
+	verificationAggregateError = "SchemaVerificationError"
+)
+
+// columnAffinity describes the column types able to hold the values of a field type. Column types are matched when the
+// lowercased name of the type contains any of the keywords, similar to the type affinity rules of sqlite.
+type columnAffinity struct {
+	description string
+	keywords    []string
+}
+
+var (
+	integerAffinity = columnAffinity{"an integer", []string{"int"}}
+	floatAffinity   = columnAffinity{"a floating point", []string{"real", "floa", "doub", "numeric", "decimal"}}
+	textAffinity    = columnAffinity{"a text", []string{"char", "text", "clob"}}
+	booleanAffinity = columnAffinity{"a boolean", []string{"bool", "int"}}
+	timeAffinity    = columnAffinity{"a date or time", []string{"date", "time"}}
+)
+
+// columnAffinities holds the affinities of the types that are not numeric.
+var columnAffinities = map[string]columnAffinity{
+	"string":    textAffinity,
+	"bool":      booleanAffinity,
+	"time.Time": timeAffinity,
+}
+
+// lookupColumnAffinity returns the affinity of the columns able to hold the field type provided, or false for types
+// whose columns are not verified.
+func lookupColumnAffinity(fieldType string) (columnAffinity, bool) {
+	if affinity, ok := columnAffinities[fieldType]; ok {
+		return affinity, true
+	}
+
+	if nullable, ok := lookupNullableType(fieldType); ok {
+		return lookupColumnAffinity(nullable.underlying)
+	}
+
+	info := getTypeInfo(fieldType)
+
+	if info&types.IsInteger != 0 {
+		return integerAffinity, true
+	}
+
+	if info&types.IsFloat != 0 {
+		return floatAffinity, true
+	}
+
+	return columnAffinity{}, false
+}
+
+// verifiable returns true if the schema of the record is verified, which is when it has fields, a store and a dialect
+// able to list the columns of its table.
+func (r *marlowRecord) verifiable() bool {
+	_, listed := r.dialect().(VerificationDialect)
+	return len(r.fields) > 0 && r.featured() && listed
+}
+
+func (r *marlowRecord) verifier() string {
+	return fmt.Sprintf("Verify%sSchema", r.name())
+}
+
+func (r *marlowRecord) verificationError() string {
+	return fmt.Sprintf("%s%s", r.name(), verificationErrorSuffix)
+}
+
+// newVerificationGenerator returns a reader that writes the function verifying the schema of the record's table in a
+// live database. The generator of the record declared first (by name) in the package also writes the aggregate of the
+// verification functions of every record in the package.
+func newVerificationGenerator(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+
+	if record.verifiable() != true {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+		e := writeVerificationError(gosrc, record)
+
+		if e == nil {
+			e = writeVerifier(gosrc, record)
+		}
+
+		if records := verifiedRecords(record); e == nil && records[0] == record.name() {
+			e = writeVerificationAggregate(gosrc, records)
+		}
+
+		if e == nil {
+			record.registerImports("fmt", "strings", "database/sql")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// columnFilter returns the field list filter of the fields holding the column provided.
+func columnFilter(column string) func(url.Values) bool {
+	return func(config url.Values) bool {
+		return config.Get(constants.ColumnConfigOption) == column
+	}
+}
+
+// verifiedRecords returns the sorted names of the records in the package of the record (including itself) whose schema
+// is verified.
+func verifiedRecords(record marlowRecord) []string {
+	names := []string{record.name()}
+
+	for name, other := range record.registry {
+		if name != record.name() && other.verifiable() {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// writeVerificationError writes the error returned by the verification of the record's schema.
+func writeVerificationError(gosrc writing.GoWriter, record marlowRecord) error {
+	comment := "%s is returned by %s, describing how the %s table differs from the fields of %s."
+	gosrc.Comment(comment, record.verificationError(), record.verifier(), record.table(), record.name())
+
+	e := gosrc.WithStruct(record.verificationError(), func(url.Values) error {
+		return gosrc.Println("Problems []string")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	return gosrc.WithMethod("Error", record.verificationError(), nil, []string{"string"}, func(scope url.Values) error {
+		message := fmt.Sprintf("invalid %s table: %%s", record.table())
+		problems := fmt.Sprintf("strings.Join(%s.Problems, \", \")", scope.Get("receiver"))
+		return gosrc.Returns(fmt.Sprintf("fmt.Sprintf(%q, %s)", message, problems))
+	})
+}
+
+// writeVerifier writes the function introspecting the record's table through the query of its dialect, returning the
+// missing columns along with those whose type or nullability does not match the field holding them.
+func writeVerifier(gosrc writing.GoWriter, record marlowRecord) error {
+	comment := "%s returns a %s if the %s table of the database is missing columns of %s or holds them with types or"
+	gosrc.Comment(comment, record.verifier(), record.verificationError(), record.table(), record.name())
+	gosrc.Comment("nullability that do not match its fields.")
+
+	params := []writing.FuncParam{{Type: "*sql.DB", Symbol: "_db"}}
+
+	// Records whose dialect is unable to list the columns of their table are not verifiable.
+	dialect := record.dialect().(VerificationDialect)
+
+	return gosrc.WithFunc(record.verifier(), params, []string{"error"}, func(url.Values) error {
+		gosrc.Println("_rows, _e := _db.Query(%q)", dialect.TableColumns(record.table()))
+
+		gosrc.WithIf("_e != nil", func(url.Values) error {
+			return gosrc.Returns("_e")
+		})
+
+		gosrc.Println("defer _rows.Close()")
+		gosrc.Println("_columns, _e := _rows.Columns()")
+
+		gosrc.WithIf("_e != nil", func(url.Values) error {
+			return gosrc.Returns("_e")
+		})
+
+		gosrc.Println("_types, _nullable := make(map[string]string), make(map[string]bool)")
+
+		gosrc.WithIter("_rows.Next()", func(url.Values) error {
+			return writeColumnScan(gosrc)
+		})
+
+		gosrc.WithIf("_e := _rows.Err(); _e != nil", func(url.Values) error {
+			return gosrc.Returns("_e")
+		})
+
+		gosrc.WithIf("len(_types) == 0", func(url.Values) error {
+			problem := fmt.Sprintf("[]string{\"table %s does not exist\"}", record.table())
+			return gosrc.Returns(fmt.Sprintf("&%s{Problems: %s}", record.verificationError(), problem))
+		})
+
+		gosrc.Println("_problems := make([]string, 0)")
+		gosrc.Println("var _type string")
+		gosrc.Println("var _ok bool")
+
+		for _, field := range record.fieldList(nil) {
+			writeColumnVerification(gosrc, record, record.fields[field.name], field.name)
+		}
+
+		// Soft deleted records are marked by a nullable timestamp column that is not held by any of their fields.
+		if column := record.softDeleteColumn(); column != "" && len(record.fieldList(columnFilter(column))) == 0 {
+			config := url.Values{constants.ColumnConfigOption: {column}, "type": {timestampType}}
+			writeColumnVerification(gosrc, record, config, "")
+		}
+
+		gosrc.WithIf("len(_problems) > 0", func(url.Values) error {
+			return gosrc.Returns(fmt.Sprintf("&%s{Problems: _problems}", record.verificationError()))
+		})
+
+		return gosrc.Returns(writing.Nil)
+	})
+}
+
+// writeColumnScan writes the scan of a row listing a column of the table into the types and nullability of the
+// columns, discarding the values of the row that are not used by the verification.
+func writeColumnScan(gosrc writing.GoWriter) error {
+	gosrc.Println("var _name, _type string")
+	gosrc.Println("var _notNull bool")
+	gosrc.Println("_values := make([]interface{}, len(_columns))")
+
+	gosrc.WithIter("_i, _column := range _columns", func(url.Values) error {
+		gosrc.Println("switch strings.ToLower(_column) {")
+		gosrc.Println("case \"name\":")
+		gosrc.Println("_values[_i] = &_name")
+		gosrc.Println("case \"type\":")
+		gosrc.Println("_values[_i] = &_type")
+		gosrc.Println("case \"notnull\":")
+		gosrc.Println("_values[_i] = &_notNull")
+		gosrc.Println("default:")
+		gosrc.Println("_values[_i] = new(interface{})")
+		return gosrc.Println("}")
+	})
+
+	gosrc.WithIf("_e := _rows.Scan(_values...); _e != nil", func(url.Values) error {
+		return gosrc.Returns("_e")
+	})
+
+	gosrc.Println("_name = strings.ToLower(_name)")
+	return gosrc.Println("_types[_name], _nullable[_name] = strings.ToLower(_type), _notNull == false")
+}
+
+// writeColumnVerification writes the checks of the column held by a field (or by nothing, if the field name is empty),
+// appending the problems found to the problems of the verification.
+func writeColumnVerification(gosrc writing.GoWriter, record marlowRecord, config url.Values, field string) error {
+	column, fieldType := strings.ToLower(config.Get(constants.ColumnConfigOption)), config.Get("type")
+	nullable, holder := strings.HasPrefix(fieldType, "sql.Null"), fmt.Sprintf("%s.%s", record.name(), field)
+
+	if field == "" {
+		nullable, holder = true, fmt.Sprintf("the soft deletes of %s", record.name())
+	}
+
+	gosrc.Println("_type, _ok = _types[%q]", column)
+
+	gosrc.WithIf("_ok == false", func(url.Values) error {
+		return gosrc.Println("_problems = append(_problems, %q)", fmt.Sprintf("missing column %s", column))
+	})
+
+	if affinity, ok := lookupColumnAffinity(fieldType); ok {
+		matches := make([]string, 0, len(affinity.keywords))
+
+		for _, keyword := range affinity.keywords {
+			matches = append(matches, fmt.Sprintf("strings.Contains(_type, %q)", keyword))
+		}
+
+		gosrc.WithIf("_ok && !(%s)", func(url.Values) error {
+			problem := fmt.Sprintf("column %s has type %%s, expected %s type for %s", column, affinity.description, holder)
+			return gosrc.Println("_problems = append(_problems, fmt.Sprintf(%q, _type))", problem)
+		}, strings.Join(matches, " || "))
+	}
+
+	// Primary keys are never null, regardless of what is reported for them.
+	if column == strings.ToLower(record.primaryKeyColumn()) {
+		return nil
+	}
+
+	problem := fmt.Sprintf("column %s is nullable but %s is not", column, holder)
+
+	if nullable {
+		problem = fmt.Sprintf("column %s is not nullable but %s is", column, holder)
+	}
+
+	return gosrc.WithIf("_ok && _nullable[%q] != %v", func(url.Values) error {
+		return gosrc.Println("_problems = append(_problems, %q)", problem)
+	}, column, nullable)
+}
+
+// writeVerificationAggregate writes the function verifying the schema of every record in the package, along with the
+// error it returns.
+func writeVerificationAggregate(gosrc writing.GoWriter, records []string) error {
+	comment := "%s is returned by %s, holding the error of each record whose schema failed verification."
+	gosrc.Comment(comment, verificationAggregateError, verificationAggregate)
+
+	e := gosrc.WithStruct(verificationAggregateError, func(url.Values) error {
+		return gosrc.Println("Errors []error")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	e = gosrc.WithMethod("Error", verificationAggregateError, nil, []string{"string"}, func(scope url.Values) error {
+		gosrc.Println("_messages := make([]string, 0, len(%s.Errors))", scope.Get("receiver"))
+
+		gosrc.WithIter("_, _e := range %s.Errors", func(url.Values) error {
+			return gosrc.Println("_messages = append(_messages, _e.Error())")
+		}, scope.Get("receiver"))
+
+		return gosrc.Returns("strings.Join(_messages, \"; \")")
+	})
+
+	if e != nil {
+		return e
+	}
+
+	comment = "%s verifies the schema of every record in the package against the database, returning a %s"
+	gosrc.Comment(comment, verificationAggregate, verificationAggregateError)
+	gosrc.Comment("holding the error of each record that failed verification.")
+
+	params := []writing.FuncParam{{Type: "*sql.DB", Symbol: "_db"}}
+
+	return gosrc.WithFunc(verificationAggregate, params, []string{"error"}, func(url.Values) error {
+		gosrc.Println("_errors := make([]error, 0)")
+
+		for _, name := range records {
+			gosrc.WithIf("_e := Verify%sSchema(_db); _e != nil", func(url.Values) error {
+				return gosrc.Println("_errors = append(_errors, _e)")
+			}, name)
+		}
+
+		gosrc.WithIf("len(_errors) > 0", func(url.Values) error {
+			return gosrc.Returns(fmt.Sprintf("&%s{Errors: _errors}", verificationAggregateError))
+		})
+
+		return gosrc.Returns(writing.Nil)
+	})
+}
@@ -0,0 +1,110 @@
+package marlow
+
+import "bytes"
+import "strings"
+import "testing"
+import "go/token"
+import "go/parser"
+import "github.com/franela/goblin"
+
+func Test_Verification(t *testing.T) {
+	g := goblin.Goblin(t)
+
+	g.Describe("schema verification generator test suite", func() {
+		var output *bytes.Buffer
+
+		book := `
+		package marlowt
+
+		type Book struct {
+			table    bool          ` + "`marlow:\"tableName=books&primaryKey=id&softDelete=deleted_at\"`" + `
+			ID       int           ` + "`marlow:\"column=id\"`" + `
+			Title    string        ` + "`marlow:\"column=title\"`" + `
+			SeriesID sql.NullInt64 ` + "`marlow:\"column=series_id\"`" + `
+		}
+		`
+
+		author := `
+		package marlowt
+
+		type Author struct {
+			table bool   ` + "`marlow:\"tableName=authors&dialect=postgres&primaryKey=id\"`" + `
+			ID    int    ` + "`marlow:\"column=id\"`" + `
+			Name  string ` + "`marlow:\"column=name\"`" + `
+		}
+		`
+
+		g.BeforeEach(func() {
+			output = new(bytes.Buffer)
+		})
+
+		g.It("generates valid golang", func() {
+			e := Compile(output, strings.NewReader(book))
+			g.Assert(e).Equal(nil)
+			_, e = parser.ParseFile(token.NewFileSet(), "", output, parser.AllErrors)
+			g.Assert(e).Equal(nil)
+		})
+
+		g.It("introspects the table of the record through its dialect", func() {
+			e := Compile(output, strings.NewReader(book))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "func VerifyBookSchema(_db *sql.DB) error {")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "_db.Query(\"PRAGMA table_info(books)\")")).Equal(true)
+
+			output.Reset()
+			e = Compile(output, strings.NewReader(author))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "FROM information_schema.columns")).Equal(true)
+		})
+
+		g.It("checks the type and nullability of the column held by each field", func() {
+			e := Compile(output, strings.NewReader(book))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "\"missing column title\"")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "expected a text type for Book.Title")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "\"column title is nullable but Book.Title is not\"")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "\"column series_id is not nullable but Book.SeriesID is\"")).Equal(true)
+		})
+
+		g.It("does not check the nullability of the primary key", func() {
+			e := Compile(output, strings.NewReader(book))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "column id is nullable")).Equal(false)
+		})
+
+		g.It("checks the soft delete column of records that do not hold it in a field", func() {
+			e := Compile(output, strings.NewReader(book))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "\"missing column deleted_at\"")).Equal(true)
+			expected := "\"column deleted_at is not nullable but the soft deletes of Book is\""
+			g.Assert(strings.Contains(output.String(), expected)).Equal(true)
+		})
+
+		g.It("writes the aggregate of the package with the record declared first", func() {
+			e := Compile(output, strings.NewReader(book), strings.NewReader(author))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "func VerifySchemas(")).Equal(false)
+
+			output.Reset()
+			e = Compile(output, strings.NewReader(author), strings.NewReader(book))
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "func VerifySchemas(_db *sql.DB) error {")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "VerifyAuthorSchema(_db)")).Equal(true)
+			g.Assert(strings.Contains(output.String(), "VerifyBookSchema(_db)")).Equal(true)
+		})
+
+		g.It("skips records without fields or features", func() {
+			source := strings.NewReader(`
+			package marlowt
+
+			type Construct struct {
+				table bool ` + "`marlow:\"queryable=false&updateable=false&createable=false&deletable=false\"`" + `
+				Name string ` + "`marlow:\"column=name\"`" + `
+			}
+			`)
+			e := Compile(output, source)
+			g.Assert(e).Equal(nil)
+			g.Assert(strings.Contains(output.String(), "VerifyConstructSchema")).Equal(false)
+		})
+	})
+}
@@ -0,0 +1,135 @@
+package marlow
+
+import "io"
+import "fmt"
+import "sort"
+import "strings"
+import "net/url"
+import "go/types"
+import "github.com/dadleyy/marlow/marlow/writing"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+// versionField returns the name and config of the field holding the record's optimistic locking version.
+func (r *marlowRecord) versionField() (string, url.Values, bool) {
+	for name, config := range r.fields {
+		if config.Get(constants.ColumnVersionFlag) != "" {
+			return name, config, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (r *marlowRecord) versionConflictError() string {
+	return fmt.Sprintf("%s%s", r.name(), constants.VersionConflictErrorSuffix)
+}
+
+// validateVersion returns an error if the record flags more than a single version field or if the version field is
+// unable to be incremented by updates.
+func validateVersion(typeName string, fields map[string]url.Values) error {
+	versions := make([]string, 0, 1)
+
+	for name, config := range fields {
+		if config.Get(constants.ColumnVersionFlag) == "" {
+			continue
+		}
+
+		if fieldType := config.Get("type"); getTypeInfo(fieldType)&types.IsInteger == 0 {
+			return fmt.Errorf("version field %s.%s must be an integer, found %s", typeName, name, fieldType)
+		}
+
+		versions = append(versions, name)
+	}
+
+	if len(versions) > 1 {
+		sort.Strings(versions)
+		return fmt.Errorf("record %s has multiple version fields: %s", typeName, strings.Join(versions, " & "))
+	}
+
+	return nil
+}
+
+// newVersionConflictGenerator returns a reader that writes the error type returned by the updates of versioned records
+// when no row holds the version the update was made from. Updates matching no row at all are reported the same way.
+func newVersionConflictGenerator(record marlowRecord) io.Reader {
+	pr, pw := io.Pipe()
+	_, config, versioned := record.versionField()
+
+	if versioned != true {
+		pw.CloseWithError(nil)
+		return pr
+	}
+
+	go func() {
+		gosrc := writing.NewGoWriter(pw)
+		comment := "%s is returned by the updates of %s records when the version they were made from is out of date."
+		gosrc.Comment(comment, record.versionConflictError(), record.name())
+		gosrc.Comment("Updates are unable to tell stale versions from missing records: both affect no row and return the error.")
+
+		e := gosrc.WithStruct(record.versionConflictError(), func(url.Values) error {
+			return gosrc.Println("Version %s", config.Get("type"))
+		})
+
+		if e != nil {
+			pw.CloseWithError(e)
+			return
+		}
+
+		e = gosrc.WithMethod("Error", record.versionConflictError(), nil, []string{"string"}, func(scope url.Values) error {
+			message := fmt.Sprintf("%s version %%v is out of date", strings.ToLower(record.name()))
+			return gosrc.Returns(fmt.Sprintf("fmt.Sprintf(%q, %s.Version)", message, scope.Get("receiver")))
+		})
+
+		if e == nil {
+			record.registerImports("fmt")
+		}
+
+		pw.CloseWithError(e)
+	}()
+
+	return pr
+}
+
+// versionIncrement returns the assignment incrementing the version column of the record, empty for records without one.
+func versionIncrement(record marlowRecord) string {
+	_, config, versioned := record.versionField()
+
+	if versioned != true {
+		return ""
+	}
+
+	column := record.quote(config.Get(constants.ColumnConfigOption))
+	return fmt.Sprintf(", %s = %s + 1", column, column)
+}
+
+// writeVersionGuard writes the WHERE clause of versioned updates, limiting the rows matched by the blueprint to those
+// holding the expected version. The blueprint condition is grouped so that inclusive blueprints are limited as well.
+func writeVersionGuard(gosrc writing.GoWriter, record marlowRecord, symbols updaterSymbols, position string) {
+	_, config, _ := record.versionField()
+	column := record.quote(config.Get(constants.ColumnConfigOption))
+
+	gosrc.Println("%s := %s", symbols.guard, record.placeholders("%s", position))
+	gosrc.Println("%s := \"\"", symbols.condition)
+
+	gosrc.WithIf("%s != nil", func(url.Values) error {
+		return gosrc.Println("%s, _ = %s.%s(1)", symbols.condition, symbols.blueprint, blueprintClauseMethod)
+	}, symbols.blueprint)
+
+	gosrc.WithIf("%s != \"\"", func(url.Values) error {
+		template := fmt.Sprintf("\" WHERE (%%s) AND %s = %%s\"", column)
+		return gosrc.Println("fmt.Fprintf(%s, %s, %s, %s)", symbols.queryString, template, symbols.condition, symbols.guard)
+	}, symbols.condition)
+
+	gosrc.WithIf("%s == \"\"", func(url.Values) error {
+		template := fmt.Sprintf("\" WHERE %s = %%s\"", column)
+		return gosrc.Println("fmt.Fprintf(%s, %s, %s)", symbols.queryString, template, symbols.guard)
+	}, symbols.condition)
+}
+
+// writeVersionConflict writes the check returning the version conflict error of the record when no row was affected,
+// whether the row's version was out of date or no row was matched at all.
+func writeVersionConflict(gosrc writing.GoWriter, record marlowRecord, rowCount, version string) error {
+	return gosrc.WithIf("%s == 0", func(url.Values) error {
+		return gosrc.Returns("-1", fmt.Sprintf("&%s{Version: %s}", record.versionConflictError(), version))
+	}, rowCount)
+}
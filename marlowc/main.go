@@ -23,11 +23,18 @@ func main() {
 		exit("unable to get current directory", e)
 	}
 
+	// The schema subcommand writes the CREATE TABLE statements of the records instead of compiling them.
+	if len(os.Args) > 1 && os.Args[1] == schemaCommand {
+		schema(cwd, os.Args[2:])
+		return
+	}
+
 	options := cliOptions{ext: constants.DefaultMarlowFileExtension}
 	flag.StringVar(&options.input, "input", cwd, "the input to compile")
 	flag.BoolVar(&options.stdout, "stdout", false, "print generated code to stdout")
 	flag.BoolVar(&options.silent, "silent", false, "print nothing unless error")
 	flag.StringVar(&options.ext, "extension", options.ext, "the file extension used for generated code")
+	flag.BoolVar(&options.fakes, "fakes", false, "also generate in-memory fake implementations of the stores")
 
 	flag.Usage = usage
 	flag.Parse()
@@ -71,39 +78,7 @@ func main() {
 			continue
 		}
 
-		// Attempt to build the writer that we will copy the generated source into.
-		writer, e := options.writerFor(name)
-
-		if e != nil {
-			exit("unable to create writer for file", e)
-		}
-
-		// Create our marlow compiler for the given file.
-		reader, e := marlow.NewReaderFromFile(name)
-
-		if e != nil {
-			exit("unable to open output for file", e)
-		}
-
-		size, e := io.Copy(writer, reader)
-
-		if e != nil {
-			exit(fmt.Sprintf("unable to compile file %s", name), e)
-		}
-
-		// If no data was copied we had an no-op gen source, remove the file and continue.
-		if size == 0 {
-			os.Remove(options.generatedName(name))
-			bar.IncrBy(1)
-			continue
-		}
-
-		fmt.Fprintf(os.Stdout, "completed compilation of %s\n", name)
-
-		results[options.generatedName(name)] = size
-
-		// Close the destination file/buffer.
-		writer.Close()
+		options.compileAll(name, results)
 
 		// Let our progress bar know we're done.
 		bar.IncrBy(1)
@@ -129,6 +104,69 @@ type cliOptions struct {
 	stdout bool
 	silent bool
 	ext    string
+	fakes  bool
+}
+
+// compileAll compiles the input file into each of its destinations, adding the size of the source written into each
+// of them to the results.
+func (o *cliOptions) compileAll(input string, results map[string]int64) {
+	targets := []bool{false}
+
+	// When generating fakes, each source file is compiled a second time into its fake destination.
+	if o.fakes == true {
+		targets = append(targets, true)
+	}
+
+	for _, fake := range targets {
+		destination, size, e := o.compile(input, fake)
+
+		if e != nil {
+			exit(fmt.Sprintf("unable to compile file %s", input), e)
+		}
+
+		// If no data was copied we had an no-op gen source, nothing to report.
+		if size == 0 {
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "completed compilation of %s\n", destination)
+
+		results[destination] = size
+	}
+}
+
+// compile writes the generated source of the input file (or of its fakes) into the destination for it, returning the
+// name of the destination and the amount of source written. Destinations left empty are removed.
+func (o *cliOptions) compile(input string, fake bool) (string, int64, error) {
+	destination, open := o.generatedName(input), marlow.NewReaderFromFile
+
+	if fake == true {
+		destination, open = o.fakeName(input), marlow.NewFakeReaderFromFile
+	}
+
+	// Attempt to build the writer that we will copy the generated source into.
+	writer, e := o.writerFor(destination)
+
+	if e != nil {
+		return destination, 0, e
+	}
+
+	// Create our marlow compiler for the given file.
+	reader, e := open(input)
+
+	if e != nil {
+		return destination, 0, e
+	}
+
+	size, e := io.Copy(writer, reader)
+
+	if e != nil || size == 0 {
+		os.Remove(destination)
+		return destination, 0, e
+	}
+
+	// Close the destination file/buffer.
+	return destination, size, writer.Close()
 }
 
 func (o *cliOptions) generatedName(input string) string {
@@ -137,15 +175,20 @@ func (o *cliOptions) generatedName(input string) string {
 	return path.Join(dir, name)
 }
 
-func (o *cliOptions) writerFor(input string) (io.WriteCloser, error) {
+func (o *cliOptions) fakeName(input string) string {
+	dir := path.Dir(input)
+	name := strings.TrimSuffix(path.Base(input), path.Ext(input)) + constants.DefaultFakeFileSuffix + o.ext
+	return path.Join(dir, name)
+}
+
+func (o *cliOptions) writerFor(destination string) (io.WriteCloser, error) {
 	// If we're printing to stdout, just return a bytes.Buffer wrapped w/ a Close.
 	if o.stdout == true {
 		buffer := new(bytes.Buffer)
 		return &closableBuffer{Buffer: buffer}, nil
 	}
 
-	full := o.generatedName(input)
-	return os.Create(full)
+	return os.Create(destination)
 }
 
 // closableBuffer records are used in place of actual files when the -std flag is used.
@@ -161,6 +204,7 @@ func (b *closableBuffer) Close() error {
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n\n", os.Args[0])
 	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nUse \"%s %s -help\" for the options of the schema subcommand.\n", os.Args[0], schemaCommand)
 }
 
 func exit(msg string, e error) {
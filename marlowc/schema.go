@@ -0,0 +1,80 @@
+package main
+
+import "os"
+import "io"
+import "fmt"
+import "flag"
+import "path"
+import "bytes"
+import "strings"
+
+import "github.com/dadleyy/marlow/marlow"
+import "github.com/dadleyy/marlow/marlow/constants"
+
+// schemaCommand is the name of the subcommand writing the CREATE TABLE statements of the records found in the input.
+const schemaCommand = "schema"
+
+// schema runs the schema subcommand, writing the statements of every source file into a single output.
+func schema(cwd string, args []string) {
+	options := struct {
+		input   string
+		dialect string
+		output  string
+		ext     string
+	}{ext: constants.DefaultMarlowFileExtension}
+
+	commands := flag.NewFlagSet(schemaCommand, flag.ExitOnError)
+	commands.StringVar(&options.input, "input", cwd, "the input to read records from")
+	commands.StringVar(&options.dialect, "dialect", "", "only write the tables of records using this dialect")
+	commands.StringVar(&options.output, "output", "", "the file to write the statements into (default stdout)")
+	commands.StringVar(&options.ext, "extension", options.ext, "the file extension used for generated code")
+	commands.Parse(args)
+
+	sourceFiles, e := loadFileNames(options.input)
+
+	if e != nil {
+		exit("unable to load package from input", e)
+	}
+
+	statements := new(bytes.Buffer)
+
+	for _, name := range sourceFiles {
+		// Skip files that have been generated by marlow.
+		if strings.HasSuffix(path.Base(name), options.ext) {
+			continue
+		}
+
+		reader, e := marlow.NewSchemaReaderFromFile(name, options.dialect)
+
+		if e != nil {
+			exit("unable to open file", e)
+		}
+
+		if _, e := io.Copy(statements, reader); e != nil {
+			exit(fmt.Sprintf("unable to compile schema of file %s", name), e)
+		}
+	}
+
+	if statements.Len() == 0 {
+		exit("no tables found in input", nil)
+	}
+
+	var output io.Writer = os.Stdout
+
+	if options.output != "" {
+		file, e := os.Create(options.output)
+
+		if e != nil {
+			exit("unable to create output", e)
+		}
+
+		defer file.Close()
+		output = file
+	}
+
+	fmt.Fprintf(output, "-- %s\n\n", constants.CompilerHeader)
+
+	if _, e := io.Copy(output, statements); e != nil {
+		exit("unable to write schema", e)
+	}
+}